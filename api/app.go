@@ -3,16 +3,38 @@ package api
 import (
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/IbnuFarhanS/Golang_MNC/internal/controller"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/funding"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/fx"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/ledger"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
 	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/risk"
 	"github.com/IbnuFarhanS/Golang_MNC/internal/router"
 	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
 )
 
+// scheduleInterval adalah jeda pemeriksaan pembayaran terjadwal yang jatuh tempo
+const scheduleInterval = 30 * time.Second
+
+// webhookInterval adalah jeda pemeriksaan pengiriman webhook yang perlu dicoba ulang
+const webhookInterval = 10 * time.Second
+
 // App mewakili aplikasi API
 type App struct {
-	router *router.Router
+	router             *router.Router
+	transactionService *service.TransactionService
+	idempotencyRepo    repository.IdempotencyRepository
+	settlementService  *service.SettlementService
+	scheduleService    *service.ScheduleService
+	billService        *service.BillService
+	invoiceService     *service.InvoiceService
+	webhookService     *service.WebhookService
+	loyaltyService     *service.LoyaltyService
 }
 
 // NewApp membuat instance baru dari App
@@ -43,10 +65,181 @@ func (a *App) Initialize() {
 		// Log fatal jika gagal membuat repository merchant dalam memori
 		log.Fatal(err)
 	}
+	err = service.ValidateMerchantFees(merchantRepo)
+	if err != nil {
+		// Log fatal jika skema biaya merchant tidak valid
+		log.Fatal(err)
+	}
+	walletRepo, err := repository.NewInMemoryWalletRepository("json/wallets.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository wallet dalam memori
+		log.Fatal(err)
+	}
+	// Membuat buku besar yang mencatat seluruh pergerakan uang
+	book, err := ledger.New(ledger.NewFileStore("json/ledger.json"))
+	if err != nil {
+		// Log fatal jika gagal memuat buku besar
+		log.Fatal(err)
+	}
+	limitRepo, err := repository.NewInMemoryLimitRepository("json/limits.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository batas pengeluaran dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan batas pengeluaran pelanggan
+	limitService := service.NewLimitService(limitRepo, customerRepo, transactionRepo)
+	// Membuat penyedia kurs pertukaran untuk pembayaran valas
+	rates := fx.NewFileRateProvider("json/exchange_rates.json")
 	// Membuat layanan transaksi baru dengan repository yang sudah dibuat
-	transactionService := service.NewTransactionService(transactionRepo, customerRepo, merchantRepo)
-	// Membuat kontroler transaksi baru dengan layanan transaksi
-	transactionController := controller.NewTransactionController(customerRepo, transactionService)
+	transactionService := service.NewTransactionService(transactionRepo, customerRepo, merchantRepo, walletRepo, book, rates, limitService)
+	// Lama penahanan saldo otorisasi dapat diatur melalui environment HOLD_EXPIRY, contoh "15m"
+	if holdExpiry := os.Getenv("HOLD_EXPIRY"); holdExpiry != "" {
+		expiry, err := time.ParseDuration(holdExpiry)
+		if err != nil {
+			log.Fatal("HOLD_EXPIRY tidak valid: ", err)
+		}
+		transactionService.SetHoldExpiry(expiry)
+	}
+	a.transactionService = transactionService
+	promotionRepo, err := repository.NewInMemoryPromotionRepository("json/promotions.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository promosi dalam memori
+		log.Fatal(err)
+	}
+	redemptionRepo, err := repository.NewInMemoryRedemptionRepository("json/promotion_redemptions.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository pemakaian promosi dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan promosi yang menghitung diskon dan cashback pembayaran
+	promotionService := service.NewPromotionService(promotionRepo, redemptionRepo, customerRepo, merchantRepo)
+	transactionService.SetPromotions(promotionService)
+	loyaltyRepo, err := repository.NewInMemoryLoyaltyRepository("json/loyalty.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository pengaturan poin loyalitas dalam memori
+		log.Fatal(err)
+	}
+	pointsRepo, err := repository.NewInMemoryPointsRepository("json/points.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository buku poin dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan dan kontroler poin loyalitas yang dicatat dari pembayaran
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, pointsRepo, merchantRepo)
+	transactionService.SetLoyalty(loyaltyService)
+	a.loyaltyService = loyaltyService
+	loyaltyController := controller.NewLoyaltyController(customerRepo, loyaltyService)
+	riskConfig, err := risk.LoadConfig("json/risk_rules.json")
+	if err != nil {
+		// Log fatal jika gagal membaca pengaturan aturan risiko
+		log.Fatal(err)
+	}
+	riskEventRepo, err := repository.NewInMemoryRiskEventRepository("json/risk_events.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository catatan risiko dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan pemeriksaan risiko yang dijalankan sebelum pembayaran disimpan
+	riskService := service.NewRiskService(risk.NewEngine(riskConfig.Rules()...), riskEventRepo, transactionRepo)
+	transactionService.SetRisk(riskService)
+	qrCodeRepo, err := repository.NewInMemoryQRCodeRepository("json/qr_codes.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository QR dinamis dalam memori
+		log.Fatal(err)
+	}
+	transactionService.SetQRCodes(qrCodeRepo)
+	webhookRepo, err := repository.NewInMemoryWebhookRepository("json/webhooks.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository webhook dalam memori
+		log.Fatal(err)
+	}
+	webhookDeliveryRepo, err := repository.NewInMemoryWebhookDeliveryRepository("json/webhook_deliveries.json")
+	if err != nil {
+		// Log fatal jika gagal membuat outbox webhook dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan webhook yang menerima event dari layanan transaksi
+	webhookService := service.NewWebhookService(webhookRepo, webhookDeliveryRepo, merchantRepo, nil)
+	transactionService.SetEventPublisher(webhookService)
+	a.webhookService = webhookService
+	webhookController := controller.NewWebhookController(merchantRepo, webhookService)
+	idempotencyRepo, err := repository.NewInMemoryIdempotencyRepository("json/idempotency_keys.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository idempotency key dalam memori
+		log.Fatal(err)
+	}
+	a.idempotencyRepo = idempotencyRepo
+	// Secret kode verifikasi struk wajib diatur melalui environment RECEIPT_SECRET agar kode tidak dapat dipalsukan
+	receiptSecret := os.Getenv("RECEIPT_SECRET")
+	if receiptSecret == "" {
+		log.Fatal("RECEIPT_SECRET wajib diatur")
+	}
+	// Membuat layanan struk transaksi
+	receiptService := service.NewReceiptService(transactionRepo, customerRepo, merchantRepo, receiptSecret)
+	// Membuat kontroler transaksi baru dengan layanan transaksi dan layanan struk
+	transactionController := controller.NewTransactionController(customerRepo, merchantRepo, idempotencyRepo, transactionService, receiptService)
+	// Membuat kontroler QR pembayaran merchant
+	qrController := controller.NewQRController(merchantRepo, transactionService)
+
+	scheduleRepo, err := repository.NewInMemoryScheduleRepository("json/schedules.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository pembayaran terjadwal dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan dan kontroler pembayaran terjadwal
+	scheduleService := service.NewScheduleService(scheduleRepo, transactionService)
+	a.scheduleService = scheduleService
+	scheduleController := controller.NewScheduleController(customerRepo, scheduleService)
+
+	billRepo, err := repository.NewInMemoryBillRepository("json/bills.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository split bill dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan dan kontroler split bill
+	billService := service.NewBillService(billRepo, customerRepo, merchantRepo, transactionService)
+	a.billService = billService
+	billController := controller.NewBillController(customerRepo, billService)
+
+	invoiceRepo, err := repository.NewInMemoryInvoiceRepository("json/invoices.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository invoice dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan dan kontroler invoice merchant
+	invoiceService := service.NewInvoiceService(invoiceRepo, customerRepo, merchantRepo, transactionService)
+	a.invoiceService = invoiceService
+	invoiceController := controller.NewInvoiceController(customerRepo, merchantRepo, invoiceService)
+
+	// Membuat registry sumber dana dengan simulator transfer bank / virtual account
+	fundingSources := funding.NewRegistry(funding.NewBankTransferSimulator("8808", money.MustParse("50000000", money.DefaultCurrency)))
+	// Membuat layanan wallet baru
+	walletService := service.NewWalletService(walletRepo, customerRepo, transactionRepo, fundingSources, book, limitService)
+	// Membuat kontroler wallet baru dengan layanan wallet
+	walletController := controller.NewWalletController(customerRepo, walletService)
+	// Membuat layanan dan kontroler laporan rekening bulanan pelanggan
+	statementService := service.NewStatementService(transactionRepo, customerRepo, merchantRepo, walletRepo)
+	statementController := controller.NewStatementController(customerRepo, statementService)
+	settlementRepo, err := repository.NewInMemorySettlementRepository("json/settlements.json")
+	if err != nil {
+		// Log fatal jika gagal membuat repository settlement dalam memori
+		log.Fatal(err)
+	}
+	// Membuat layanan settlement merchant
+	settlementService := service.NewSettlementService(settlementRepo, transactionRepo, merchantRepo, book)
+	// Jam cut-off settlement harian dapat diatur melalui environment SETTLEMENT_CUTOFF_HOUR, contoh "17"
+	if cutoffHour := os.Getenv("SETTLEMENT_CUTOFF_HOUR"); cutoffHour != "" {
+		hour, err := strconv.Atoi(cutoffHour)
+		if err == nil {
+			err = settlementService.SetSchedule(service.DefaultSettlementPeriod, hour)
+		}
+		if err != nil {
+			log.Fatal("SETTLEMENT_CUTOFF_HOUR tidak valid: ", err)
+		}
+	}
+	a.settlementService = settlementService
+	// Membuat kontroler admin dengan layanan batas pengeluaran, settlement, promosi, poin loyalitas, dan risiko
+	adminController := controller.NewAdminController(customerRepo, limitService, settlementService, promotionService, loyaltyService, riskService)
 
 	// Mendaftarkan rute pelanggan
 	log.Println("Mendaftarkan rute pelanggan...")
@@ -58,11 +251,104 @@ func (a *App) Initialize() {
 	a.router.RegisterTransactionRoutes(transactionController)
 	log.Println("Rute transaksi terdaftar.")
 
+	// Mendaftarkan rute wallet
+	log.Println("Mendaftarkan rute wallet...")
+	a.router.RegisterWalletRoutes(walletController)
+	log.Println("Rute wallet terdaftar.")
+
+	// Mendaftarkan rute pembayaran terjadwal
+	log.Println("Mendaftarkan rute pembayaran terjadwal...")
+	a.router.RegisterScheduleRoutes(scheduleController)
+	log.Println("Rute pembayaran terjadwal terdaftar.")
+
+	// Mendaftarkan rute split bill
+	log.Println("Mendaftarkan rute split bill...")
+	a.router.RegisterBillRoutes(billController)
+	log.Println("Rute split bill terdaftar.")
+
+	// Mendaftarkan rute invoice
+	log.Println("Mendaftarkan rute invoice...")
+	a.router.RegisterInvoiceRoutes(invoiceController)
+	log.Println("Rute invoice terdaftar.")
+
+	// Mendaftarkan rute QR merchant
+	log.Println("Mendaftarkan rute QR merchant...")
+	a.router.RegisterQRRoutes(qrController)
+	log.Println("Rute QR merchant terdaftar.")
+
+	// Mendaftarkan rute webhook merchant
+	log.Println("Mendaftarkan rute webhook merchant...")
+	a.router.RegisterWebhookRoutes(webhookController)
+	log.Println("Rute webhook merchant terdaftar.")
+
+	// Mendaftarkan rute poin loyalitas
+	log.Println("Mendaftarkan rute poin loyalitas...")
+	a.router.RegisterLoyaltyRoutes(loyaltyController)
+	log.Println("Rute poin loyalitas terdaftar.")
+
+	// Mendaftarkan rute laporan rekening
+	log.Println("Mendaftarkan rute laporan rekening...")
+	a.router.RegisterStatementRoutes(statementController)
+	log.Println("Rute laporan rekening terdaftar.")
+
+	// Mendaftarkan rute admin
+	log.Println("Mendaftarkan rute admin...")
+	a.router.RegisterAdminRoutes(adminController)
+	log.Println("Rute admin terdaftar.")
+
 	log.Println("Aplikasi diinisialisasi.")
 }
 
 // Run menjalankan aplikasi
 func (a *App) Run(port string) {
+	// Menyelesaikan kuota promosi yang masih dipesan oleh pembayaran yang terputus saat server terakhir berhenti
+	confirmed, released, err := a.transactionService.RecoverPromotions()
+	if err != nil {
+		log.Println("Gagal memulihkan kuota promosi:", err)
+	} else if confirmed+released > 0 {
+		log.Println("Kuota promosi dipulihkan, redeemed:", confirmed, "dikembalikan:", released)
+	}
+
+	// Menjalankan pelepasan otorisasi kedaluwarsa di background
+	log.Println("Menjalankan sweeper otorisasi kedaluwarsa...")
+	a.transactionService.StartHoldSweeper(time.Minute)
+
+	// Membatalkan split bill kedaluwarsa dan menyelesaikan capture split bill yang sudah lunas
+	log.Println("Menjalankan sweeper split bill...")
+	a.billService.StartSweeper(time.Minute)
+
+	// Mengirim event transaksi ke webhook merchant dari outbox
+	log.Println("Menjalankan dispatcher webhook...")
+	a.webhookService.StartDispatcher(webhookInterval)
+
+	// Menandai invoice yang tidak dibayar sampai waktu kedaluwarsanya
+	log.Println("Menjalankan sweeper invoice...")
+	a.invoiceService.StartSweeper(time.Minute)
+
+	// Menjalankan pembayaran terjadwal yang jatuh tempo di background
+	log.Println("Menjalankan scheduler pembayaran terjadwal...")
+	a.scheduleService.StartScheduler(scheduleInterval)
+
+	// Menghanguskan poin loyalitas yang melewati masa berlakunya
+	log.Println("Menjalankan sweeper poin loyalitas...")
+	a.loyaltyService.StartSweeper(time.Hour)
+
+	// Membuat batch settlement merchant setiap kali cut-off terlewati
+	log.Println("Menjalankan scheduler settlement...")
+	a.settlementService.StartScheduler()
+
+	// Membersihkan idempotency key yang kedaluwarsa secara berkala
+	go func() {
+		for now := range time.Tick(time.Hour) {
+			purged, err := a.idempotencyRepo.PurgeExpired(now)
+			if err != nil {
+				log.Println("Gagal membersihkan idempotency key:", err)
+			} else if purged > 0 {
+				log.Println("Idempotency key kedaluwarsa dihapus:", purged)
+			}
+		}
+	}()
+
 	log.Printf("Server berjalan pada port %s\n", port)
 	log.Fatal(http.ListenAndServe(":"+port, a.router.GetHandler()))
 }
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// AdminController menangani permintaan HTTP untuk pengelolaan oleh admin
+type AdminController struct {
+	CustomerRepo      repository.CustomerRepository
+	limitService      *service.LimitService
+	settlementService *service.SettlementService
+	promotionService  *service.PromotionService
+	loyaltyService    *service.LoyaltyService
+	riskService       *service.RiskService
+}
+
+// NewAdminController membuat instance baru dari AdminController
+func NewAdminController(customerRepo repository.CustomerRepository, limitService *service.LimitService, settlementService *service.SettlementService, promotionService *service.PromotionService, loyaltyService *service.LoyaltyService, riskService *service.RiskService) *AdminController {
+	return &AdminController{
+		CustomerRepo:      customerRepo,
+		limitService:      limitService,
+		settlementService: settlementService,
+		promotionService:  promotionService,
+		loyaltyService:    loyaltyService,
+		riskService:       riskService,
+	}
+}
+
+type TierRequest struct {
+	Tier string `json:"tier"`
+}
+
+type TierResponse struct {
+	Success    bool   `json:"success"`
+	CustomerID string `json:"customer_id"`
+	Tier       string `json:"tier"`
+	Message    string `json:"message"`
+}
+
+type LimitConfigResponse struct {
+	Success bool                `json:"success"`
+	Limits  *models.LimitConfig `json:"limits"`
+}
+
+type LimitUsageResponse struct {
+	Success bool                `json:"success"`
+	Usage   *service.LimitUsage `json:"usage"`
+}
+
+// GetLimits menangani permintaan HTTP untuk melihat seluruh batas pengeluaran
+func (h *AdminController) GetLimits(w http.ResponseWriter, r *http.Request) {
+	config, err := h.limitService.GetConfig()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &LimitConfigResponse{Success: true, Limits: config})
+}
+
+// SetTierLimit menangani permintaan HTTP untuk mengatur batas pengeluaran sebuah tier
+func (h *AdminController) SetTierLimit(w http.ResponseWriter, r *http.Request) {
+	var limit models.SpendingLimit
+	err := json.NewDecoder(r.Body).Decode(&limit)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	err = h.limitService.SetTierLimit(mux.Vars(r)["tier"], limit)
+	if err != nil {
+		log.Println("Gagal mengatur batas tier:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.GetLimits(w, r)
+}
+
+// SetCustomerLimit menangani permintaan HTTP untuk mengatur batas pengeluaran khusus pelanggan
+func (h *AdminController) SetCustomerLimit(w http.ResponseWriter, r *http.Request) {
+	var limit models.SpendingLimit
+	err := json.NewDecoder(r.Body).Decode(&limit)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	customerID := mux.Vars(r)["id"]
+	err = h.limitService.SetCustomerLimit(customerID, limit)
+	if err != nil {
+		log.Println("Gagal mengatur batas pelanggan:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.GetCustomerLimit(w, r)
+}
+
+// DeleteCustomerLimit menangani permintaan HTTP untuk menghapus batas pengeluaran khusus pelanggan
+func (h *AdminController) DeleteCustomerLimit(w http.ResponseWriter, r *http.Request) {
+	err := h.limitService.DeleteCustomerLimit(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.GetCustomerLimit(w, r)
+}
+
+// GetCustomerLimit menangani permintaan HTTP untuk melihat batas pengeluaran yang berlaku
+// untuk pelanggan beserta pemakaian hari dan bulan berjalan
+func (h *AdminController) GetCustomerLimit(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.limitService.Usage(mux.Vars(r)["id"], time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &LimitUsageResponse{Success: true, Usage: usage})
+}
+
+// SetCustomerTier menangani permintaan HTTP untuk memindahkan pelanggan ke tier lain
+func (h *AdminController) SetCustomerTier(w http.ResponseWriter, r *http.Request) {
+	var req TierRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	customer, err := h.limitService.SetCustomerTier(mux.Vars(r)["id"], req.Tier)
+	if err != nil {
+		log.Println("Gagal mengatur tier pelanggan:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &TierResponse{
+		Success:    true,
+		CustomerID: customer.ID,
+		Tier:       customer.TierName(),
+		Message:    "Tier pelanggan diperbarui",
+	})
+}
+
+// Fungsi bantu untuk menulis respons JSON
+func writeJSON(w http.ResponseWriter, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, "Gagal mengodekan respons JSON", http.StatusInternalServerError)
+		return
+	}
+}
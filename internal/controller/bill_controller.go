@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// BillController menangani permintaan HTTP terkait split bill
+type BillController struct {
+	CustomerRepo repository.CustomerRepository
+	billService  *service.BillService
+}
+
+// NewBillController membuat instance baru dari BillController
+func NewBillController(customerRepo repository.CustomerRepository, billService *service.BillService) *BillController {
+	return &BillController{
+		CustomerRepo: customerRepo,
+		billService:  billService,
+	}
+}
+
+type BillRequest struct {
+	MerchantID  string                     `json:"merchant_id"`
+	Description string                     `json:"description"`
+	Shares      []service.BillShareRequest `json:"shares"`
+	ExpiresAt   *time.Time                 `json:"expires_at"`
+}
+
+type BillResponse struct {
+	Success bool         `json:"success"`
+	Bill    *models.Bill `json:"bill"`
+	Message string       `json:"message,omitempty"`
+}
+
+type BillsResponse struct {
+	Success bool          `json:"success"`
+	Bills   []models.Bill `json:"bills"`
+}
+
+// CreateBill menangani permintaan HTTP untuk membuat split bill
+func (h *BillController) CreateBill(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	var req BillRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt time.Time
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	bill, err := h.billService.CreateBill(customer.ID, req.MerchantID, req.Description, req.Shares, expiresAt)
+	if err != nil {
+		log.Println("Gagal membuat split bill:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, &BillResponse{Success: true, Bill: bill, Message: "Split bill dibuat"})
+}
+
+// ListBills menangani permintaan HTTP untuk melihat split bill yang dibuat atau diikuti pelanggan
+func (h *BillController) ListBills(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	bills, err := h.billService.GetBills(customer.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &BillsResponse{Success: true, Bills: bills})
+}
+
+// GetBill menangani permintaan HTTP untuk melihat split bill beserta status setiap bagian
+func (h *BillController) GetBill(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	bill, err := h.billService.GetBill(customer.ID, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &BillResponse{Success: true, Bill: bill})
+}
+
+// PayShare menangani permintaan HTTP untuk menyetujui dan membayar bagian pelanggan pada split bill
+func (h *BillController) PayShare(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	bill, err := h.billService.PayShare(customer.ID, mux.Vars(r)["id"], r.Header.Get(DeviceIDHeader))
+	if err != nil {
+		log.Println("Gagal membayar bagian split bill:", err)
+		if writeLimitError(w, err) || writeRiskError(w, err) {
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &BillResponse{Success: true, Bill: bill, Message: "Bagian split bill dibayar"})
+}
+
+// CancelBill menangani permintaan HTTP untuk membatalkan split bill dan mengembalikan bagian yang sudah dibayar
+func (h *BillController) CancelBill(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	bill, err := h.billService.CancelBill(customer.ID, mux.Vars(r)["id"])
+	if err != nil {
+		log.Println("Gagal membatalkan split bill:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &BillResponse{Success: true, Bill: bill, Message: "Split bill dibatalkan"})
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/middleware"
+)
+
+// Fungsi bantu untuk mengambil pelanggan pemilik token dari konteks permintaan
+func authenticatedCustomer(r *http.Request, repo repository.CustomerRepository) (*models.Customer, error) {
+	// User ID pada token berisi username pelanggan
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok {
+		return nil, errors.New("failed to extract user ID from context")
+	}
+
+	return repo.GetByUsername(userID)
+}
+
+// Fungsi bantu untuk mengambil ID merchant pemilik API key dari konteks permintaan
+func authenticatedMerchant(r *http.Request) (string, error) {
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey).(string)
+	if !ok {
+		return "", errors.New("failed to extract merchant ID from context")
+	}
+
+	return merchantID, nil
+}
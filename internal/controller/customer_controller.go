@@ -84,7 +84,13 @@ func (h *CustomerController) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if success {
-		token, err := utils.GenerateToken(req.Username, "user")
+		customer, err := h.CustomerRepo.GetByUsername(req.Username)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		token, err := utils.GenerateToken(req.Username, customer.RoleName())
 		if err != nil {
 			http.Error(w, "Gagal menghasilkan token", http.StatusInternalServerError)
 			return
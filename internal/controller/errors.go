@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+)
+
+// ErrorResponse adalah respons JSON untuk error yang memiliki kode error
+type ErrorResponse struct {
+	Success bool                `json:"success"`
+	Code    string              `json:"code"`
+	Message string              `json:"message"`
+	Limit   *money.Money        `json:"limit,omitempty"`
+	Used    *money.Money        `json:"used,omitempty"`
+	Reasons []models.RiskReason `json:"reasons,omitempty"`
+}
+
+// Fungsi bantu untuk menulis respons error batas pengeluaran beserta kodenya.
+// Mengembalikan false jika err bukan error batas pengeluaran.
+func writeLimitError(w http.ResponseWriter, err error) bool {
+	var limitErr *service.LimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	resp := ErrorResponse{
+		Success: false,
+		Code:    limitErr.Code,
+		Message: err.Error(),
+		Limit:   &limitErr.Limit,
+		Used:    &limitErr.Used,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(&resp)
+	return true
+}
+
+// Fungsi bantu untuk menulis respons error pembayaran yang ditolak pemeriksaan risiko beserta alasannya.
+// Mengembalikan false jika err bukan error pemeriksaan risiko.
+func writeRiskError(w http.ResponseWriter, err error) bool {
+	var riskErr *service.RiskError
+	if !errors.As(err, &riskErr) {
+		return false
+	}
+
+	resp := ErrorResponse{
+		Success: false,
+		Code:    "risk_denied",
+		Message: err.Error(),
+		Reasons: riskErr.Reasons,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(&resp)
+	return true
+}
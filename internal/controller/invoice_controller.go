@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// InvoiceController menangani permintaan HTTP terkait invoice merchant
+type InvoiceController struct {
+	CustomerRepo   repository.CustomerRepository
+	MerchantRepo   repository.MerchantRepository
+	invoiceService *service.InvoiceService
+}
+
+// NewInvoiceController membuat instance baru dari InvoiceController
+func NewInvoiceController(customerRepo repository.CustomerRepository, merchantRepo repository.MerchantRepository, invoiceService *service.InvoiceService) *InvoiceController {
+	return &InvoiceController{
+		CustomerRepo:   customerRepo,
+		MerchantRepo:   merchantRepo,
+		invoiceService: invoiceService,
+	}
+}
+
+type InvoiceResponse struct {
+	Success bool            `json:"success"`
+	Invoice *models.Invoice `json:"invoice"`
+	Message string          `json:"message,omitempty"`
+}
+
+type InvoicesResponse struct {
+	Success  bool             `json:"success"`
+	Invoices []models.Invoice `json:"invoices"`
+}
+
+// CreateInvoice menangani permintaan HTTP merchant untuk membuat invoice
+func (h *InvoiceController) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	var req service.InvoiceRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := h.invoiceService.CreateInvoice(merchantID, req)
+	if err != nil {
+		log.Println("Gagal membuat invoice:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, &InvoiceResponse{Success: true, Invoice: invoice, Message: "Invoice dibuat"})
+}
+
+// ListMerchantInvoices menangani permintaan HTTP merchant untuk melihat invoice miliknya
+func (h *InvoiceController) ListMerchantInvoices(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	invoices, err := h.invoiceService.GetMerchantInvoices(merchantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &InvoicesResponse{Success: true, Invoices: invoices})
+}
+
+// GetMerchantInvoice menangani permintaan HTTP merchant untuk melihat status invoice
+func (h *InvoiceController) GetMerchantInvoice(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	invoice, err := h.invoiceService.GetMerchantInvoice(merchantID, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &InvoiceResponse{Success: true, Invoice: invoice})
+}
+
+// CancelInvoice menangani permintaan HTTP merchant untuk membatalkan invoice yang belum dibayar
+func (h *InvoiceController) CancelInvoice(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	invoice, err := h.invoiceService.CancelInvoice(merchantID, mux.Vars(r)["id"])
+	if err != nil {
+		log.Println("Gagal membatalkan invoice:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &InvoiceResponse{Success: true, Invoice: invoice, Message: "Invoice dibatalkan"})
+}
+
+// ListCustomerInvoices menangani permintaan HTTP untuk melihat invoice yang ditujukan untuk atau dibayar oleh pelanggan
+func (h *InvoiceController) ListCustomerInvoices(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	invoices, err := h.invoiceService.GetCustomerInvoices(customer.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &InvoicesResponse{Success: true, Invoices: invoices})
+}
+
+// GetCustomerInvoice menangani permintaan HTTP pelanggan untuk melihat invoice berdasarkan ID yang dibagikan merchant
+func (h *InvoiceController) GetCustomerInvoice(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	invoice, err := h.invoiceService.GetCustomerInvoice(customer.ID, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &InvoiceResponse{Success: true, Invoice: invoice})
+}
+
+// PayInvoice menangani permintaan HTTP pelanggan untuk membayar invoice dari saldo wallet
+func (h *InvoiceController) PayInvoice(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	invoice, err := h.invoiceService.PayInvoice(customer.ID, mux.Vars(r)["id"])
+	if err != nil {
+		log.Println("Gagal membayar invoice:", err)
+		if writeLimitError(w, err) || writeRiskError(w, err) {
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &InvoiceResponse{Success: true, Invoice: invoice, Message: "Invoice dibayar"})
+}
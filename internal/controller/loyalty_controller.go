@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// LoyaltyController menangani permintaan HTTP terkait poin loyalitas pelanggan
+type LoyaltyController struct {
+	CustomerRepo   repository.CustomerRepository
+	loyaltyService *service.LoyaltyService
+}
+
+// NewLoyaltyController membuat instance baru dari LoyaltyController
+func NewLoyaltyController(customerRepo repository.CustomerRepository, loyaltyService *service.LoyaltyService) *LoyaltyController {
+	return &LoyaltyController{
+		CustomerRepo:   customerRepo,
+		loyaltyService: loyaltyService,
+	}
+}
+
+type PointsBalanceResponse struct {
+	Success bool                   `json:"success"`
+	Balance *service.PointsBalance `json:"balance"`
+}
+
+type PointsHistoryResponse struct {
+	Success bool                 `json:"success"`
+	Entries []models.PointsEntry `json:"entries"`
+}
+
+type LoyaltyConfigResponse struct {
+	Success bool                  `json:"success"`
+	Loyalty *models.LoyaltyConfig `json:"loyalty"`
+}
+
+// GetBalance menangani permintaan HTTP untuk melihat saldo poin pelanggan
+func (h *LoyaltyController) GetBalance(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	balance, err := h.loyaltyService.Balance(customer.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &PointsBalanceResponse{Success: true, Balance: balance})
+}
+
+// GetHistory menangani permintaan HTTP untuk melihat riwayat poin pelanggan
+func (h *LoyaltyController) GetHistory(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	entries, err := h.loyaltyService.History(customer.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &PointsHistoryResponse{Success: true, Entries: entries})
+}
+
+// GetLoyalty menangani permintaan HTTP untuk melihat pengaturan program poin loyalitas
+func (h *AdminController) GetLoyalty(w http.ResponseWriter, r *http.Request) {
+	config, err := h.loyaltyService.GetConfig()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &LoyaltyConfigResponse{Success: true, Loyalty: config})
+}
+
+// SetMerchantEarnRate menangani permintaan HTTP untuk mengatur perolehan poin khusus merchant
+func (h *AdminController) SetMerchantEarnRate(w http.ResponseWriter, r *http.Request) {
+	var rate models.EarnRate
+	err := json.NewDecoder(r.Body).Decode(&rate)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	err = h.loyaltyService.SetMerchantRate(mux.Vars(r)["id"], rate)
+	if err != nil {
+		log.Println("Gagal mengatur perolehan poin merchant:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.GetLoyalty(w, r)
+}
+
+// DeleteMerchantEarnRate menangani permintaan HTTP untuk menghapus perolehan poin khusus merchant
+func (h *AdminController) DeleteMerchantEarnRate(w http.ResponseWriter, r *http.Request) {
+	err := h.loyaltyService.DeleteMerchantRate(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.GetLoyalty(w, r)
+}
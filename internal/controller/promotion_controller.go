@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type PromotionsResponse struct {
+	Success    bool                       `json:"success"`
+	Promotions []service.PromotionSummary `json:"promotions"`
+}
+
+type PromotionResponse struct {
+	Success   bool                      `json:"success"`
+	Promotion *service.PromotionSummary `json:"promotion"`
+	Message   string                    `json:"message,omitempty"`
+}
+
+type PromotionDeactivateResponse struct {
+	Success   bool              `json:"success"`
+	Promotion *models.Promotion `json:"promotion"`
+	Message   string            `json:"message"`
+}
+
+// CreatePromotion menangani permintaan HTTP untuk membuat promosi diskon atau cashback
+func (h *AdminController) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var req service.PromotionRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(req)
+	if err != nil {
+		log.Println("Gagal membuat promosi:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, &PromotionResponse{
+		Success:   true,
+		Promotion: &service.PromotionSummary{Promotion: *promotion},
+		Message:   "Promosi berhasil dibuat",
+	})
+}
+
+// ListPromotions menangani permintaan HTTP untuk melihat seluruh promosi beserta pemakaian kuotanya
+func (h *AdminController) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.promotionService.GetPromotions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &PromotionsResponse{Success: true, Promotions: promotions})
+}
+
+// GetPromotion menangani permintaan HTTP untuk melihat promosi beserta pemakaian kuotanya
+func (h *AdminController) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	promotion, err := h.promotionService.GetPromotion(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &PromotionResponse{Success: true, Promotion: promotion})
+}
+
+// DeactivatePromotion menangani permintaan HTTP untuk menghentikan promosi
+func (h *AdminController) DeactivatePromotion(w http.ResponseWriter, r *http.Request) {
+	promotion, err := h.promotionService.DeactivatePromotion(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &PromotionDeactivateResponse{Success: true, Promotion: promotion, Message: "Promosi dihentikan"})
+}
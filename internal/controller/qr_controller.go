@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+)
+
+// QRController menangani permintaan HTTP merchant untuk membuat QR pembayaran
+type QRController struct {
+	MerchantRepo       repository.MerchantRepository
+	transactionService *service.TransactionService
+}
+
+// NewQRController membuat instance baru dari QRController
+func NewQRController(merchantRepo repository.MerchantRepository, transactionService *service.TransactionService) *QRController {
+	return &QRController{
+		MerchantRepo:       merchantRepo,
+		transactionService: transactionService,
+	}
+}
+
+type QRRequest struct {
+	Amount    *money.Money `json:"amount"`
+	Reference string       `json:"reference"`
+}
+
+type QRResponse struct {
+	Success   bool         `json:"success"`
+	Payload   string       `json:"payload"`
+	Dynamic   bool         `json:"dynamic"`
+	Amount    *money.Money `json:"amount,omitempty"`
+	Reference string       `json:"reference,omitempty"`
+}
+
+// GenerateQR menangani permintaan HTTP merchant untuk membuat payload QR statis atau dinamis
+func (h *QRController) GenerateQR(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	// Body kosong menghasilkan QR statis
+	var req QRRequest
+	if r.ContentLength != 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+			return
+		}
+	}
+
+	payload, data, err := h.transactionService.GenerateQR(merchantID, req.Amount, req.Reference)
+	if err != nil {
+		log.Println("Gagal membuat QR:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &QRResponse{
+		Success:   true,
+		Payload:   data,
+		Dynamic:   payload.Dynamic(),
+		Amount:    payload.Amount,
+		Reference: payload.Reference,
+	})
+}
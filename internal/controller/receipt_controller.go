@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Format struk yang dapat diminta melalui header Accept, format pertama adalah format default
+var receiptFormats = []string{"text/plain", "text/html", "application/pdf", "application/json"}
+
+// Nama format struk pada query format sebagai alternatif header Accept
+var receiptFormatNames = map[string]string{
+	"text": "text/plain",
+	"html": "text/html",
+	"pdf":  "application/pdf",
+	"json": "application/json",
+}
+
+type ReceiptVerifyResponse struct {
+	Success       bool   `json:"success"`
+	TransactionID string `json:"transaction_id"`
+	Valid         bool   `json:"valid"`
+}
+
+// GetReceipt menangani permintaan HTTP untuk mengunduh struk transaksi milik pengguna. Format struk dipilih dari
+// header Accept (text/plain, text/html, application/pdf, atau application/json) atau query format.
+func (h *TransactionController) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Failed to get authenticated customer:", err)
+		http.Error(w, "Customer not found", http.StatusUnauthorized)
+		return
+	}
+
+	contentType := ""
+	if format := r.URL.Query().Get("format"); format != "" {
+		contentType = receiptFormatNames[strings.ToLower(format)]
+	} else {
+		contentType = negotiate(r.Header.Get("Accept"), receiptFormats)
+	}
+	if contentType == "" {
+		http.Error(w, "Format struk tidak didukung, gunakan text/plain, text/html, application/pdf, atau application/json", http.StatusNotAcceptable)
+		return
+	}
+
+	receipt, err := h.receiptService.GetReceipt(customer.ID, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var data []byte
+	extension := ""
+	switch contentType {
+	case "text/plain":
+		data, extension = h.receiptService.RenderText(receipt), "txt"
+	case "text/html":
+		data, err = h.receiptService.RenderHTML(receipt)
+		extension = "html"
+	case "application/pdf":
+		data, extension = h.receiptService.RenderPDF(receipt), "pdf"
+	case "application/json":
+		w.Header().Set("Vary", "Accept")
+		writeJSON(w, receipt)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if strings.HasPrefix(contentType, "text/") {
+		contentType += "; charset=utf-8"
+	}
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "inline; filename=\"receipt-"+receipt.TransactionID+"."+extension+"\"")
+	w.Write(data)
+}
+
+// VerifyReceipt menangani permintaan HTTP untuk memeriksa kode verifikasi struk tanpa autentikasi
+func (h *TransactionController) VerifyReceipt(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.URL.Query().Get("transaction_id")
+	code := r.URL.Query().Get("code")
+	if transactionID == "" || code == "" {
+		http.Error(w, "transaction_id dan code wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &ReceiptVerifyResponse{
+		Success:       true,
+		TransactionID: transactionID,
+		Valid:         h.receiptService.VerifyReceipt(transactionID, code),
+	})
+}
+
+// Fungsi bantu untuk memilih format dari offers yang paling diinginkan menurut header Accept.
+// Header kosong berarti format default. Mengembalikan string kosong jika tidak ada format yang dapat diterima.
+func negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	type mediaRange struct {
+		value string
+		q     float64
+	}
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, raw, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.EqualFold(key, "q") {
+				parsed, err := strconv.ParseFloat(raw, 64)
+				if err == nil {
+					q = parsed
+				}
+			}
+		}
+		if value != "" && q > 0 {
+			ranges = append(ranges, mediaRange{value: value, q: q})
+		}
+	}
+	// Media range dengan q lebih tinggi didahulukan, lalu yang lebih spesifik, lalu sesuai urutan pada header
+	specificity := func(value string) int {
+		switch {
+		case value == "*/*":
+			return 0
+		case strings.HasSuffix(value, "/*"):
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return specificity(ranges[i].value) > specificity(ranges[j].value)
+	})
+
+	for _, mediaRange := range ranges {
+		for _, offer := range offers {
+			switch {
+			case mediaRange.value == "*/*", mediaRange.value == offer:
+				return offer
+			case strings.HasSuffix(mediaRange.value, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(mediaRange.value, "*")):
+				return offer
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+type RiskEventsResponse struct {
+	Success bool               `json:"success"`
+	Events  []models.RiskEvent `json:"events"`
+}
+
+// ListRiskEvents menangani permintaan HTTP untuk melihat pembayaran yang ditolak atau ditandai oleh pemeriksaan risiko.
+// Query decision bersifat opsional untuk menyaring berdasarkan keputusan (deny atau review).
+func (h *AdminController) ListRiskEvents(w http.ResponseWriter, r *http.Request) {
+	events, err := h.riskService.GetEvents(r.URL.Query().Get("decision"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &RiskEventsResponse{Success: true, Events: events})
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// ScheduleController menangani permintaan HTTP terkait pembayaran terjadwal pelanggan
+type ScheduleController struct {
+	CustomerRepo    repository.CustomerRepository
+	scheduleService *service.ScheduleService
+}
+
+// NewScheduleController membuat instance baru dari ScheduleController
+func NewScheduleController(customerRepo repository.CustomerRepository, scheduleService *service.ScheduleService) *ScheduleController {
+	return &ScheduleController{
+		CustomerRepo:    customerRepo,
+		scheduleService: scheduleService,
+	}
+}
+
+type ScheduleRequest struct {
+	MerchantID string      `json:"merchant_id"`
+	Amount     money.Money `json:"amount"`
+	Frequency  string      `json:"frequency"`
+	StartAt    *time.Time  `json:"start_at"`
+	EndAt      *time.Time  `json:"end_at"`
+}
+
+type ScheduleResponse struct {
+	Success  bool             `json:"success"`
+	Schedule *models.Schedule `json:"schedule"`
+	Message  string           `json:"message,omitempty"`
+}
+
+type SchedulesResponse struct {
+	Success   bool              `json:"success"`
+	Schedules []models.Schedule `json:"schedules"`
+}
+
+// CreateSchedule menangani permintaan HTTP untuk membuat pembayaran terjadwal
+func (h *ScheduleController) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	var req ScheduleRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var startAt time.Time
+	if req.StartAt != nil {
+		startAt = *req.StartAt
+	}
+	schedule, err := h.scheduleService.CreateSchedule(customer.ID, req.MerchantID, req.Amount, req.Frequency, startAt, req.EndAt)
+	if err != nil {
+		log.Println("Gagal membuat pembayaran terjadwal:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, &ScheduleResponse{Success: true, Schedule: schedule, Message: "Pembayaran terjadwal dibuat"})
+}
+
+// ListSchedules menangani permintaan HTTP untuk melihat pembayaran terjadwal milik pelanggan
+func (h *ScheduleController) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	schedules, err := h.scheduleService.GetSchedules(customer.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &SchedulesResponse{Success: true, Schedules: schedules})
+}
+
+// GetSchedule menangani permintaan HTTP untuk melihat pembayaran terjadwal beserta riwayat eksekusinya
+func (h *ScheduleController) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	schedule, err := h.scheduleService.GetSchedule(customer.ID, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &ScheduleResponse{Success: true, Schedule: schedule})
+}
+
+// CancelSchedule menangani permintaan HTTP untuk membatalkan pembayaran terjadwal
+func (h *ScheduleController) CancelSchedule(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	schedule, err := h.scheduleService.CancelSchedule(customer.ID, mux.Vars(r)["id"])
+	if err != nil {
+		log.Println("Gagal membatalkan pembayaran terjadwal:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &ScheduleResponse{Success: true, Schedule: schedule, Message: "Pembayaran terjadwal dibatalkan"})
+}
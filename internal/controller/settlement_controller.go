@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type SettlementRequest struct {
+	// Cutoff dalam format RFC3339, kosong berarti cut-off terakhir
+	Cutoff string `json:"cutoff"`
+}
+
+type SettlementBatchesResponse struct {
+	Success bool                     `json:"success"`
+	Batches []models.SettlementBatch `json:"batches"`
+}
+
+type SettlementBatchResponse struct {
+	Success bool                      `json:"success"`
+	Report  *service.SettlementReport `json:"settlement"`
+}
+
+// CreateSettlements menangani permintaan HTTP untuk membuat batch settlement sampai waktu cut-off
+func (h *AdminController) CreateSettlements(w http.ResponseWriter, r *http.Request) {
+	var req SettlementRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && err != io.EOF {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	cutoff := h.settlementService.LastCutoff(time.Now())
+	if req.Cutoff != "" {
+		cutoff, err = time.Parse(time.RFC3339, req.Cutoff)
+		if err != nil {
+			http.Error(w, "Format cutoff tidak valid, gunakan RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+
+	batches, err := h.settlementService.CreateBatches(cutoff)
+	if err != nil {
+		log.Println("Gagal membuat batch settlement:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, &SettlementBatchesResponse{Success: true, Batches: batches})
+}
+
+// ListSettlements menangani permintaan HTTP untuk melihat batch settlement, dapat difilter dengan merchant_id
+func (h *AdminController) ListSettlements(w http.ResponseWriter, r *http.Request) {
+	batches, err := h.settlementService.ListBatches(r.URL.Query().Get("merchant_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &SettlementBatchesResponse{Success: true, Batches: batches})
+}
+
+// GetSettlement menangani permintaan HTTP untuk melihat batch settlement beserta rincian transaksinya
+func (h *AdminController) GetSettlement(w http.ResponseWriter, r *http.Request) {
+	report, err := h.settlementService.GetReport(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &SettlementBatchResponse{Success: true, Report: report})
+}
+
+// ExportSettlement menangani permintaan HTTP untuk mengunduh laporan settlement dalam format csv atau json
+func (h *AdminController) ExportSettlement(w http.ResponseWriter, r *http.Request) {
+	batchID := mux.Vars(r)["id"]
+
+	var data []byte
+	var err error
+	contentType := ""
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", "csv":
+		format = "csv"
+		contentType = "text/csv"
+		data, err = h.settlementService.ExportCSV(batchID)
+	case "json":
+		contentType = "application/json"
+		data, err = h.settlementService.ExportJSON(batchID)
+	default:
+		http.Error(w, "Format ekspor tidak valid, gunakan csv atau json", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\"settlement-"+batchID+"."+format+"\"")
+	w.Write(data)
+}
+
+// MarkSettlementPaid menangani permintaan HTTP untuk menandai batch settlement sudah dibayarkan
+func (h *AdminController) MarkSettlementPaid(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.settlementService.MarkPaid(mux.Vars(r)["id"])
+	if err != nil {
+		log.Println("Gagal membayar settlement:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &SettlementBatchesResponse{Success: true, Batches: []models.SettlementBatch{*batch}})
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// Format laporan rekening yang dapat diminta melalui header Accept, format pertama adalah format default
+var statementFormats = []string{"application/json", "text/csv", "application/pdf"}
+
+// Nama format laporan rekening pada query format sebagai alternatif header Accept
+var statementFormatNames = map[string]string{
+	"json": "application/json",
+	"csv":  "text/csv",
+	"pdf":  "application/pdf",
+}
+
+// StatementController menangani permintaan HTTP terkait laporan rekening bulanan pelanggan
+type StatementController struct {
+	CustomerRepo     repository.CustomerRepository
+	statementService *service.StatementService
+}
+
+// NewStatementController membuat instance baru dari StatementController
+func NewStatementController(customerRepo repository.CustomerRepository, statementService *service.StatementService) *StatementController {
+	return &StatementController{
+		CustomerRepo:     customerRepo,
+		statementService: statementService,
+	}
+}
+
+type StatementResponse struct {
+	Success   bool               `json:"success"`
+	Statement *service.Statement `json:"statement"`
+}
+
+// GetStatement menangani permintaan HTTP untuk mengunduh laporan rekening bulanan pengguna. Periode ditulis pada
+// path dengan format YYYY-MM, format laporan dipilih dari header Accept (application/json, text/csv, atau
+// application/pdf) atau query format.
+func (h *StatementController) GetStatement(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	contentType := ""
+	if format := r.URL.Query().Get("format"); format != "" {
+		contentType = statementFormatNames[strings.ToLower(format)]
+	} else {
+		contentType = negotiate(r.Header.Get("Accept"), statementFormats)
+	}
+	if contentType == "" {
+		http.Error(w, "Format laporan tidak didukung, gunakan application/json, text/csv, atau application/pdf", http.StatusNotAcceptable)
+		return
+	}
+
+	start, err := service.ParseStatementPeriod(mux.Vars(r)["period"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statement, err := h.statementService.Build(customer.ID, start)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var data []byte
+	extension := ""
+	switch contentType {
+	case "text/csv":
+		data, err = h.statementService.RenderCSV(statement)
+		extension = "csv"
+	case "application/pdf":
+		data, extension = h.statementService.RenderPDF(statement), "pdf"
+	case "application/json":
+		w.Header().Set("Vary", "Accept")
+		writeJSON(w, &StatementResponse{Success: true, Statement: statement})
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if strings.HasPrefix(contentType, "text/") {
+		contentType += "; charset=utf-8"
+	}
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+service.StatementFileName(statement, extension)+"\"")
+	w.Write(data)
+}
@@ -2,40 +2,113 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
 	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
 	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
 	"github.com/IbnuFarhanS/Golang_MNC/middleware"
+	"github.com/gorilla/mux"
 )
 
 type TransactionController struct {
 	CustomerRepo       repository.CustomerRepository
+	MerchantRepo       repository.MerchantRepository
+	IdempotencyRepo    repository.IdempotencyRepository
 	transactionService *service.TransactionService
+	receiptService     *service.ReceiptService
 }
 
-func NewTransactionController(customerRepo repository.CustomerRepository, transactionService *service.TransactionService) *TransactionController {
+func NewTransactionController(customerRepo repository.CustomerRepository, merchantRepo repository.MerchantRepository, idempotencyRepo repository.IdempotencyRepository, transactionService *service.TransactionService, receiptService *service.ReceiptService) *TransactionController {
 	return &TransactionController{
 		CustomerRepo:       customerRepo,
+		MerchantRepo:       merchantRepo,
+		IdempotencyRepo:    idempotencyRepo,
 		transactionService: transactionService,
+		receiptService:     receiptService,
 	}
 }
 
+// DeviceIDHeader adalah header berisi ID perangkat pelanggan yang digunakan oleh pemeriksaan risiko pembayaran
+const DeviceIDHeader = "X-Device-ID"
+
+// TransactionRequest adalah permintaan pembayaran atau otorisasi. VoucherCode dan RedeemPoints hanya berlaku
+// untuk pembayaran langsung.
 type TransactionRequest struct {
-	CustomerID string  `json:"customer_id"`
-	MerchantID string  `json:"merchant_id"`
-	Amount     float64 `json:"amount"`
+	CustomerID   string      `json:"customer_id"`
+	MerchantID   string      `json:"merchant_id"`
+	Amount       money.Money `json:"amount"`
+	VoucherCode  string      `json:"voucher_code,omitempty"`
+	RedeemPoints int64       `json:"redeem_points,omitempty"`
+}
+
+type QRPaymentRequest struct {
+	Payload      string       `json:"payload"`
+	Amount       *money.Money `json:"amount"`
+	VoucherCode  string       `json:"voucher_code,omitempty"`
+	RedeemPoints int64        `json:"redeem_points,omitempty"`
 }
 
 type TransactionResponse struct {
-	Success      bool    `json:"success"`
-	CustomerID   string  `json:"customer_id"`
-	MerchantName string  `json:"merchant_name"`
-	Amount       float64 `json:"amount"`
-	Description  string  `json:"description"`
-	Message      string  `json:"message"`
+	Success        bool         `json:"success"`
+	TransactionID  string       `json:"transaction_id"`
+	Status         string       `json:"status"`
+	CustomerID     string       `json:"customer_id"`
+	MerchantName   string       `json:"merchant_name"`
+	Amount         money.Money  `json:"amount"`
+	OriginalAmount *money.Money `json:"original_amount,omitempty"`
+	ExchangeRate   string       `json:"exchange_rate,omitempty"`
+	RateLockedAt   *time.Time   `json:"rate_locked_at,omitempty"`
+	HoldAmount     *money.Money `json:"hold_amount,omitempty"`
+	HoldExpiresAt  *time.Time   `json:"hold_expires_at,omitempty"`
+	Reference      string       `json:"reference,omitempty"`
+	PromotionID    string       `json:"promotion_id,omitempty"`
+	VoucherCode    string       `json:"voucher_code,omitempty"`
+	Discount       *money.Money `json:"discount,omitempty"`
+	Cashback       *money.Money `json:"cashback,omitempty"`
+	PointsRedeemed int64        `json:"points_redeemed,omitempty"`
+	PointsDiscount *money.Money `json:"points_discount,omitempty"`
+	PointsEarned   int64        `json:"points_earned,omitempty"`
+	RiskDecision   string       `json:"risk_decision,omitempty"`
+	Description    string       `json:"description"`
+	Message        string       `json:"message"`
+}
+
+type CaptureRequest struct {
+	Amount money.Money `json:"amount"`
+}
+
+type VoidRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RefundRequest struct {
+	Amount money.Money `json:"amount"`
+	Reason string      `json:"reason"`
+}
+
+type RefundResponse struct {
+	Success        bool         `json:"success"`
+	TransactionID  string       `json:"transaction_id"`
+	OriginalID     string       `json:"original_id"`
+	Amount         money.Money  `json:"amount"`
+	OriginalAmount *money.Money `json:"original_amount,omitempty"`
+	RefundedTotal  money.Money  `json:"refunded_total"`
+	Description    string       `json:"description"`
+	Message        string       `json:"message"`
+}
+
+type TransactionHistoryResponse struct {
+	Success    bool                      `json:"success"`
+	CustomerID string                    `json:"customer_id"`
+	Items      []service.TransactionItem `json:"items"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
 }
 
 func (h *TransactionController) ProcessTransaction(w http.ResponseWriter, r *http.Request) {
@@ -65,31 +138,247 @@ func (h *TransactionController) ProcessTransaction(w http.ResponseWriter, r *htt
 	// Logging request transaksi yang diterima
 	log.Println("Received transaction request:", req)
 
+	// Transaksi hanya boleh mendebit wallet milik pengguna token
+	customer, err := h.CustomerRepo.GetByUsername(userID)
+	if err != nil {
+		log.Println("Failed to get authenticated customer:", err)
+		http.Error(w, "Customer not found", http.StatusUnauthorized)
+		return
+	}
+	if req.CustomerID == "" {
+		req.CustomerID = customer.ID
+	} else if req.CustomerID != customer.ID {
+		log.Println("Customer ID does not match authenticated user:", req.CustomerID)
+		http.Error(w, "Customer ID does not match authenticated user", http.StatusForbidden)
+		return
+	}
+
 	// Memproses transaksi menggunakan service transaksi
-	err = h.transactionService.ProcessTransaction(req.CustomerID, req.MerchantID, req.Amount)
+	transaction, err := h.transactionService.ProcessTransaction(req.CustomerID, req.MerchantID, req.Amount, service.PaymentOptions{
+		VoucherCode:  req.VoucherCode,
+		RedeemPoints: req.RedeemPoints,
+		DeviceID:     r.Header.Get(DeviceIDHeader),
+	})
+	if transaction != nil {
+		// Transaksi sudah tersimpan sehingga respons harus disimpan untuk Idempotency-Key
+		middleware.MarkCommitted(r)
+	}
 	if err != nil {
 		// Logging jika gagal memproses transaksi
 		log.Println("Failed to process transaction:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if writeLimitError(w, err) || writeRiskError(w, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			status = http.StatusPaymentRequired
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if !h.writeTransactionResponse(w, transaction) {
+		return
+	}
+
+	// Logging jika transaksi berhasil diproses
+	log.Println("Transaction processed successfully.")
+}
+
+// PayQR menangani permintaan pembayaran dari payload QR merchant yang dipindai pelanggan
+func (h *TransactionController) PayQR(w http.ResponseWriter, r *http.Request) {
+	log.Println("Processing QR payment...")
+
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Failed to get authenticated customer:", err)
+		http.Error(w, "Customer not found", http.StatusUnauthorized)
+		return
+	}
+
+	var req QRPaymentRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil || req.Payload == "" {
+		log.Println("Invalid request payload:", err)
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.transactionService.ProcessQRPayment(customer.ID, req.Payload, req.Amount, service.PaymentOptions{
+		VoucherCode:  req.VoucherCode,
+		RedeemPoints: req.RedeemPoints,
+		DeviceID:     r.Header.Get(DeviceIDHeader),
+	})
+	if transaction != nil {
+		// Transaksi sudah tersimpan sehingga respons harus disimpan untuk Idempotency-Key
+		middleware.MarkCommitted(r)
+	}
+	if err != nil {
+		log.Println("Failed to process QR payment:", err)
+		if writeLimitError(w, err) || writeRiskError(w, err) {
+			return
+		}
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			status = http.StatusPaymentRequired
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if !h.writeTransactionResponse(w, transaction) {
+		return
+	}
+
+	log.Println("QR payment processed successfully.")
+}
+
+// AuthorizeTransaction menangani permintaan otorisasi yang menahan saldo untuk di-capture kemudian
+func (h *TransactionController) AuthorizeTransaction(w http.ResponseWriter, r *http.Request) {
+	log.Println("Authorizing transaction...")
+
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Failed to get authenticated customer:", err)
+		http.Error(w, "Customer not found", http.StatusUnauthorized)
+		return
+	}
+
+	var req TransactionRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Invalid request payload:", err)
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	// Otorisasi hanya boleh menahan saldo milik pengguna token
+	if req.CustomerID != "" && req.CustomerID != customer.ID {
+		log.Println("Customer ID does not match authenticated user:", req.CustomerID)
+		http.Error(w, "Customer ID does not match authenticated user", http.StatusForbidden)
+		return
+	}
+
+	transaction, err := h.transactionService.AuthorizeTransaction(customer.ID, req.MerchantID, req.Amount, r.Header.Get(DeviceIDHeader))
+	if transaction != nil {
+		// Transaksi sudah tersimpan sehingga respons harus disimpan untuk Idempotency-Key
+		middleware.MarkCommitted(r)
+	}
+	if err != nil {
+		log.Println("Failed to authorize transaction:", err)
+		if writeLimitError(w, err) || writeRiskError(w, err) {
+			return
+		}
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			status = http.StatusPaymentRequired
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	h.writeTransactionResponse(w, transaction)
+}
+
+// CaptureTransaction menangani permintaan capture penuh atau sebagian dari merchant untuk otorisasi yang ditujukan kepadanya
+func (h *TransactionController) CaptureTransaction(w http.ResponseWriter, r *http.Request) {
+	log.Println("Capturing transaction...")
+
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Failed to get authenticated merchant:", err)
+		http.Error(w, "Merchant not found", http.StatusUnauthorized)
+		return
+	}
+
+	var req CaptureRequest
+	// Body boleh kosong untuk capture penuh
+	if r.ContentLength != 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			log.Println("Invalid request payload:", err)
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+	}
+
+	transaction, err := h.transactionService.CaptureTransaction(merchantID, mux.Vars(r)["id"], req.Amount)
+	if err != nil {
+		log.Println("Failed to capture transaction:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.writeTransactionResponse(w, transaction)
+}
+
+// VoidTransaction menangani permintaan pembatalan otorisasi dari merchant yang dituju otorisasi tersebut
+func (h *TransactionController) VoidTransaction(w http.ResponseWriter, r *http.Request) {
+	log.Println("Voiding transaction...")
+
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Failed to get authenticated merchant:", err)
+		http.Error(w, "Merchant not found", http.StatusUnauthorized)
+		return
+	}
+
+	var req VoidRequest
+	if r.ContentLength != 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			log.Println("Invalid request payload:", err)
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+	}
+
+	transaction, err := h.transactionService.VoidTransaction(merchantID, mux.Vars(r)["id"], req.Reason)
+	if err != nil {
+		log.Println("Failed to void transaction:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	h.writeTransactionResponse(w, transaction)
+}
+
+// Fungsi bantu untuk menulis TransactionResponse berisi status terakhir transaksi.
+// Mengembalikan false jika respons gagal ditulis.
+func (h *TransactionController) writeTransactionResponse(w http.ResponseWriter, transaction *models.Transaction) bool {
 	// Mendapatkan nama merchant berdasarkan ID
-	merchantName, err := h.transactionService.GetMerchantNameByID(req.MerchantID)
+	merchantName, err := h.transactionService.GetMerchantNameByID(transaction.MerchantID)
 	if err != nil {
 		// Logging jika gagal mendapatkan nama merchant
 		log.Println("Failed to get merchant name:", err)
 		http.Error(w, "Failed to get merchant name", http.StatusInternalServerError)
-		return
+		return false
 	}
 
+	status := service.TransactionStatus(transaction)
 	resp := TransactionResponse{
-		Success:      true,
-		CustomerID:   req.CustomerID,
-		MerchantName: merchantName,
-		Amount:       req.Amount,
-		Description:  fmt.Sprintf("payment for %s with amount %.2f success", merchantName, req.Amount),
-		Message:      "Transaction success",
+		Success:        true,
+		TransactionID:  transaction.ID,
+		Status:         status,
+		CustomerID:     transaction.CustomerID,
+		MerchantName:   merchantName,
+		Amount:         transaction.Amount,
+		OriginalAmount: transaction.OriginalAmount,
+		ExchangeRate:   transaction.ExchangeRate,
+		RateLockedAt:   transaction.RateLockedAt,
+		HoldAmount:     transaction.HoldAmount,
+		HoldExpiresAt:  transaction.HoldExpiresAt,
+		Reference:      transaction.Reference,
+		PromotionID:    transaction.PromotionID,
+		VoucherCode:    transaction.VoucherCode,
+		Discount:       transaction.Discount,
+		Cashback:       transaction.Cashback,
+		PointsRedeemed: transaction.PointsRedeemed,
+		PointsDiscount: transaction.PointsDiscount,
+		PointsEarned:   transaction.PointsEarned,
+		RiskDecision:   transaction.RiskDecision,
+		Description:    fmt.Sprintf("payment for %s with amount %s %s", merchantName, transaction.Amount, status),
+		Message:        "Transaction " + status,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -99,9 +388,175 @@ func (h *TransactionController) ProcessTransaction(w http.ResponseWriter, r *htt
 		// Logging jika gagal mengodekan JSON respons
 		log.Println("Failed to encode JSON response:", err)
 		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return false
+	}
+
+	return true
+}
+
+// RefundTransaction menangani permintaan refund penuh atau sebagian dari merchant untuk pembayaran yang diterimanya
+func (h *TransactionController) RefundTransaction(w http.ResponseWriter, r *http.Request) {
+	log.Println("Processing refund...")
+
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Failed to get authenticated merchant:", err)
+		http.Error(w, "Merchant not found", http.StatusUnauthorized)
 		return
 	}
 
-	// Logging jika transaksi berhasil diproses
-	log.Println("Transaction processed successfully.")
+	var req RefundRequest
+	// Body boleh kosong untuk refund penuh
+	if r.ContentLength != 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			log.Println("Invalid request payload:", err)
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+	}
+
+	transactionID := mux.Vars(r)["id"]
+	refund, err := h.transactionService.RefundTransaction(merchantID, transactionID, req.Amount, req.Reason)
+	if err != nil {
+		log.Println("Failed to process refund:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	refundedTotal, err := h.transactionService.RefundedAmount(transactionID)
+	if err != nil {
+		log.Println("Failed to get refunded total:", err)
+		http.Error(w, "Failed to get refunded total", http.StatusInternalServerError)
+		return
+	}
+
+	resp := RefundResponse{
+		Success:        true,
+		TransactionID:  refund.ID,
+		OriginalID:     refund.OriginalID,
+		Amount:         refund.Amount,
+		OriginalAmount: refund.OriginalAmount,
+		RefundedTotal:  refundedTotal,
+		Description:    refund.Description,
+		Message:        "Refund success",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		log.Println("Failed to encode JSON response:", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Refund processed successfully.")
+}
+
+// ListTransactions menangani permintaan riwayat transaksi milik pengguna token
+func (h *TransactionController) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Failed to get authenticated customer:", err)
+		http.Error(w, "Customer not found", http.StatusUnauthorized)
+		return
+	}
+
+	query, err := parseTransactionQuery(r)
+	if err != nil {
+		log.Println("Invalid transaction query:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.transactionService.ListTransactions(customer.ID, query)
+	if err != nil {
+		log.Println("Failed to list transactions:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := TransactionHistoryResponse{
+		Success:    true,
+		CustomerID: customer.ID,
+		Items:      page.Items,
+		NextCursor: page.NextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		log.Println("Failed to encode JSON response:", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Fungsi bantu untuk membaca filter riwayat transaksi dari query string
+func parseTransactionQuery(r *http.Request) (service.TransactionQuery, error) {
+	values := r.URL.Query()
+	query := service.TransactionQuery{
+		MerchantID: values.Get("merchant_id"),
+		Currency:   values.Get("currency"),
+		Type:       values.Get("type"),
+		Status:     values.Get("status"),
+		Sort:       values.Get("sort"),
+		Cursor:     values.Get("cursor"),
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %s", v)
+		}
+		query.Limit = limit
+	}
+	if v := values.Get("min_amount"); v != "" {
+		amount, err := money.Parse(v, money.DefaultCurrency)
+		if err != nil {
+			return query, fmt.Errorf("invalid min_amount: %s", v)
+		}
+		query.MinAmount = &amount
+	}
+	if v := values.Get("max_amount"); v != "" {
+		amount, err := money.Parse(v, money.DefaultCurrency)
+		if err != nil {
+			return query, fmt.Errorf("invalid max_amount: %s", v)
+		}
+		query.MaxAmount = &amount
+	}
+	if v := values.Get("from"); v != "" {
+		from, err := parseQueryTime(v, false)
+		if err != nil {
+			return query, fmt.Errorf("invalid from: %s", v)
+		}
+		query.From = &from
+	}
+	if v := values.Get("to"); v != "" {
+		to, err := parseQueryTime(v, true)
+		if err != nil {
+			return query, fmt.Errorf("invalid to: %s", v)
+		}
+		query.To = &to
+	}
+
+	return query, nil
+}
+
+// Fungsi bantu untuk membaca waktu dalam format RFC3339 atau tanggal YYYY-MM-DD.
+// Tanggal akhir tanpa jam mencakup seluruh hari tersebut.
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
 }
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+)
+
+// WalletController menangani permintaan HTTP terkait wallet pelanggan
+type WalletController struct {
+	CustomerRepo  repository.CustomerRepository
+	walletService *service.WalletService
+}
+
+// NewWalletController membuat instance baru dari WalletController
+func NewWalletController(customerRepo repository.CustomerRepository, walletService *service.WalletService) *WalletController {
+	return &WalletController{
+		CustomerRepo:  customerRepo,
+		walletService: walletService,
+	}
+}
+
+type TopUpRequest struct {
+	Source string      `json:"source"`
+	Amount money.Money `json:"amount"`
+}
+
+type TopUpResponse struct {
+	Success       bool        `json:"success"`
+	TransactionID string      `json:"transaction_id"`
+	CustomerID    string      `json:"customer_id"`
+	Amount        money.Money `json:"amount"`
+	Balance       money.Money `json:"balance"`
+	Description   string      `json:"description"`
+	Message       string      `json:"message"`
+}
+
+type TransferRequest struct {
+	Recipient string      `json:"recipient"`
+	Amount    money.Money `json:"amount"`
+	Note      string      `json:"note"`
+}
+
+type TransferResponse struct {
+	Success       bool        `json:"success"`
+	TransactionID string      `json:"transaction_id"`
+	RecipientID   string      `json:"recipient_id"`
+	Amount        money.Money `json:"amount"`
+	Balance       money.Money `json:"balance"`
+	Description   string      `json:"description"`
+	Message       string      `json:"message"`
+}
+
+type WalletResponse struct {
+	Success    bool        `json:"success"`
+	CustomerID string      `json:"customer_id"`
+	Balance    money.Money `json:"balance"`
+	Held       money.Money `json:"held"`
+	Available  money.Money `json:"available"`
+}
+
+type LedgerResponse struct {
+	Success    bool                     `json:"success"`
+	CustomerID string                   `json:"customer_id"`
+	At         *time.Time               `json:"at,omitempty"`
+	Accounts   []service.AccountBalance `json:"accounts"`
+}
+
+// TopUp menangani permintaan HTTP top-up saldo wallet
+func (h *WalletController) TopUp(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	var req TopUpRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	// Sumber dana default adalah simulator transfer bank
+	if req.Source == "" {
+		req.Source = "bank_transfer"
+	}
+
+	transaction, wallet, err := h.walletService.TopUp(customer.ID, req.Source, req.Amount)
+	if err != nil {
+		log.Println("Gagal memproses top-up:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := TopUpResponse{
+		Success:       true,
+		TransactionID: transaction.ID,
+		CustomerID:    customer.ID,
+		Amount:        transaction.Amount,
+		Balance:       wallet.Balance,
+		Description:   transaction.Description,
+		Message:       "Top-up berhasil",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		http.Error(w, "Gagal mengodekan respons JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetWallet menangani permintaan HTTP untuk melihat saldo wallet
+func (h *WalletController) GetWallet(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	wallet, err := h.walletService.GetWallet(customer.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := WalletResponse{
+		Success:    true,
+		CustomerID: customer.ID,
+		Balance:    wallet.Balance,
+		Held:       wallet.Held,
+		Available:  wallet.Available(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		http.Error(w, "Gagal mengodekan respons JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetLedger menangani permintaan HTTP untuk melihat saldo akun buku besar pelanggan,
+// parameter at (opsional) digunakan untuk melihat saldo pada waktu tertentu
+func (h *WalletController) GetLedger(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	var at *time.Time
+	if value := r.URL.Query().Get("at"); value != "" {
+		t, err := parseQueryTime(value, true)
+		if err != nil {
+			http.Error(w, "Parameter at tidak valid", http.StatusBadRequest)
+			return
+		}
+		at = &t
+	}
+
+	var balanceAt time.Time
+	if at != nil {
+		balanceAt = *at
+	}
+	balances, err := h.walletService.LedgerBalances(customer.ID, balanceAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := LedgerResponse{
+		Success:    true,
+		CustomerID: customer.ID,
+		At:         at,
+		Accounts:   balances,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		http.Error(w, "Gagal mengodekan respons JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Transfer menangani permintaan HTTP transfer saldo ke pelanggan lain
+func (h *WalletController) Transfer(w http.ResponseWriter, r *http.Request) {
+	customer, err := authenticatedCustomer(r, h.CustomerRepo)
+	if err != nil {
+		log.Println("Gagal mendapatkan pelanggan terautentikasi:", err)
+		http.Error(w, "Pelanggan tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	var req TransferRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	transaction, wallet, err := h.walletService.Transfer(customer.ID, req.Recipient, req.Amount, req.Note)
+	if err != nil {
+		log.Println("Gagal memproses transfer:", err)
+		if writeLimitError(w, err) {
+			return
+		}
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			status = http.StatusPaymentRequired
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	resp := TransferResponse{
+		Success:       true,
+		TransactionID: transaction.ID,
+		RecipientID:   transaction.CounterpartyID,
+		Amount:        transaction.Amount,
+		Balance:       wallet.Balance,
+		Description:   transaction.Description,
+		Message:       "Transfer berhasil",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		http.Error(w, "Gagal mengodekan respons JSON", http.StatusInternalServerError)
+		return
+	}
+}
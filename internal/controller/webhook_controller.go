@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// WebhookController menangani permintaan HTTP merchant terkait webhook
+type WebhookController struct {
+	MerchantRepo   repository.MerchantRepository
+	webhookService *service.WebhookService
+}
+
+// NewWebhookController membuat instance baru dari WebhookController
+func NewWebhookController(merchantRepo repository.MerchantRepository, webhookService *service.WebhookService) *WebhookController {
+	return &WebhookController{
+		MerchantRepo:   merchantRepo,
+		webhookService: webhookService,
+	}
+}
+
+type WebhookEndpointRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+type WebhookConfigResponse struct {
+	Success bool                  `json:"success"`
+	Config  *models.WebhookConfig `json:"config"`
+	Message string                `json:"message,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	Success  bool                    `json:"success"`
+	Delivery *models.WebhookDelivery `json:"delivery"`
+	Message  string                  `json:"message,omitempty"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Success    bool                     `json:"success"`
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+// RegisterEndpoint menangani permintaan HTTP merchant untuk mendaftarkan endpoint webhook
+func (h *WebhookController) RegisterEndpoint(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	var req WebhookEndpointRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Payload permintaan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	config, err := h.webhookService.RegisterEndpoint(merchantID, req.URL, req.Events)
+	if err != nil {
+		log.Println("Gagal mendaftarkan endpoint webhook:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, &WebhookConfigResponse{Success: true, Config: config, Message: "Endpoint webhook didaftarkan"})
+}
+
+// GetConfig menangani permintaan HTTP merchant untuk melihat endpoint webhook dan secret penandatanganan
+func (h *WebhookController) GetConfig(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	config, err := h.webhookService.GetConfig(merchantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &WebhookConfigResponse{Success: true, Config: config})
+}
+
+// DeleteEndpoint menangani permintaan HTTP merchant untuk menghapus endpoint webhook
+func (h *WebhookController) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	config, err := h.webhookService.DeleteEndpoint(merchantID, mux.Vars(r)["id"])
+	if err != nil {
+		log.Println("Gagal menghapus endpoint webhook:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &WebhookConfigResponse{Success: true, Config: config, Message: "Endpoint webhook dihapus"})
+}
+
+// RotateSecret menangani permintaan HTTP merchant untuk mengganti secret penandatanganan webhook
+func (h *WebhookController) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	config, err := h.webhookService.RotateSecret(merchantID)
+	if err != nil {
+		log.Println("Gagal mengganti secret webhook:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &WebhookConfigResponse{Success: true, Config: config, Message: "Secret webhook diganti"})
+}
+
+// ListDeliveries menangani permintaan HTTP merchant untuk melihat log pengiriman webhook
+func (h *WebhookController) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(merchantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &WebhookDeliveriesResponse{Success: true, Deliveries: deliveries})
+}
+
+// GetDelivery menangani permintaan HTTP merchant untuk melihat pengiriman webhook beserta riwayat percobaannya
+func (h *WebhookController) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(merchantID, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, &WebhookDeliveryResponse{Success: true, Delivery: delivery})
+}
+
+// Redeliver menangani permintaan HTTP merchant untuk mengirim ulang event dari pengiriman webhook
+func (h *WebhookController) Redeliver(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := authenticatedMerchant(r)
+	if err != nil {
+		log.Println("Gagal mendapatkan merchant terautentikasi:", err)
+		http.Error(w, "Merchant tidak ditemukan", http.StatusUnauthorized)
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(merchantID, mux.Vars(r)["id"])
+	if err != nil {
+		log.Println("Gagal mengirim ulang webhook:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, &WebhookDeliveryResponse{Success: true, Delivery: delivery, Message: "Webhook dijadwalkan untuk dikirim ulang"})
+}
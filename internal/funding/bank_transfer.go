@@ -0,0 +1,67 @@
+package funding
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// BankTransferSimulator mensimulasikan top-up melalui transfer bank ke virtual account.
+// Setiap pelanggan mendapatkan nomor virtual account tetap dan transfer dianggap
+// langsung diterima, sehingga seluruh alur top-up dapat diuji secara offline.
+type BankTransferSimulator struct {
+	bankCode  string
+	maxAmount money.Money
+}
+
+// NewBankTransferSimulator membuat simulator transfer bank dengan kode bank dan batas maksimum per transfer
+func NewBankTransferSimulator(bankCode string, maxAmount money.Money) *BankTransferSimulator {
+	return &BankTransferSimulator{
+		bankCode:  bankCode,
+		maxAmount: maxAmount,
+	}
+}
+
+// Name mengembalikan nama sumber dana
+func (s *BankTransferSimulator) Name() string {
+	return "bank_transfer"
+}
+
+// VirtualAccountNumber mengembalikan nomor virtual account milik pelanggan
+func (s *BankTransferSimulator) VirtualAccountNumber(customerID string) string {
+	// Nomor virtual account = kode bank + ID pelanggan yang dipadatkan menjadi 10 digit
+	id := strings.Repeat("0", 10) + customerID
+	return s.bankCode + id[len(id)-10:]
+}
+
+// Fund mensimulasikan transfer dari rekening pelanggan ke virtual account
+func (s *BankTransferSimulator) Fund(req Request) (*Result, error) {
+	if req.CustomerID == "" {
+		return nil, errors.New("customer ID is required")
+	}
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("transfer amount must be greater than zero")
+	}
+	if s.maxAmount.IsPositive() {
+		cmp, err := req.Amount.Cmp(s.maxAmount)
+		if err != nil {
+			return nil, err
+		}
+		if cmp > 0 {
+			return nil, fmt.Errorf("transfer amount exceeds bank limit of %s", s.maxAmount)
+		}
+	}
+
+	now := time.Now()
+	return &Result{
+		Source:        s.Name(),
+		Reference:     "BT" + strconv.FormatInt(now.UnixNano(), 10),
+		AccountNumber: s.VirtualAccountNumber(req.CustomerID),
+		Amount:        req.Amount,
+		ProcessedAt:   now,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package funding
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Request mewakili permintaan penarikan dana dari sumber dana eksternal
+type Request struct {
+	CustomerID string
+	Amount     money.Money
+}
+
+// Result mewakili hasil penarikan dana yang berhasil
+type Result struct {
+	Source        string      `json:"source"`
+	Reference     string      `json:"reference"`
+	AccountNumber string      `json:"account_number"`
+	Amount        money.Money `json:"amount"`
+	ProcessedAt   time.Time   `json:"processed_at"`
+}
+
+// Source adalah sumber dana yang dapat digunakan untuk melakukan top-up wallet
+type Source interface {
+	// Name mengembalikan nama unik sumber dana, misalnya "bank_transfer"
+	Name() string
+	// Fund menarik dana dari sumber dana dan mengembalikan bukti penarikannya
+	Fund(req Request) (*Result, error)
+}
+
+// Registry menyimpan sumber dana yang tersedia berdasarkan namanya
+type Registry struct {
+	sources map[string]Source
+}
+
+// NewRegistry membuat instance baru dari Registry dengan sumber dana yang diberikan
+func NewRegistry(sources ...Source) *Registry {
+	registry := &Registry{
+		sources: make(map[string]Source),
+	}
+	for _, source := range sources {
+		registry.Register(source)
+	}
+	return registry
+}
+
+// Register menambahkan sumber dana ke dalam registry
+func (r *Registry) Register(source Source) {
+	r.sources[source.Name()] = source
+}
+
+// Get mengambil sumber dana berdasarkan nama
+func (r *Registry) Get(name string) (Source, error) {
+	source, ok := r.sources[name]
+	if !ok {
+		return nil, fmt.Errorf("funding source %q not found", name)
+	}
+	return source, nil
+}
@@ -0,0 +1,68 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// FileRateProvider membaca kurs pertukaran dari file JSON.
+// File dibaca ulang pada setiap permintaan sehingga kurs dapat diperbarui tanpa menjalankan ulang aplikasi.
+type FileRateProvider struct {
+	filePath string
+}
+
+// NewFileRateProvider membuat instance baru dari FileRateProvider
+func NewFileRateProvider(filePath string) *FileRateProvider {
+	return &FileRateProvider{
+		filePath: filePath,
+	}
+}
+
+// Rate mengambil kurs dari base ke quote. Jika hanya kurs sebaliknya yang tersedia
+// maka kurs kebalikannya yang digunakan.
+func (p *FileRateProvider) Rate(base, quote string) (Rate, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if base == quote {
+		return Identity(base), nil
+	}
+
+	rates, err := p.getRatesFromFile()
+	if err != nil {
+		return Rate{}, err
+	}
+
+	for _, rate := range rates {
+		if strings.ToUpper(rate.Base) == base && strings.ToUpper(rate.Quote) == quote {
+			_, err = rate.Rat()
+			if err != nil {
+				return Rate{}, err
+			}
+			return rate, nil
+		}
+	}
+	for _, rate := range rates {
+		if strings.ToUpper(rate.Base) == quote && strings.ToUpper(rate.Quote) == base {
+			return rate.Inverse()
+		}
+	}
+
+	return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+}
+
+// Fungsi bantu untuk membaca kurs dari file
+func (p *FileRateProvider) getRatesFromFile() ([]Rate, error) {
+	data, err := ioutil.ReadFile(p.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rate data: %v", err)
+	}
+
+	var rates []Rate
+	err = json.Unmarshal(data, &rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal exchange rate data: %v", err)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,86 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// inverseRatePrecision adalah jumlah digit desimal kurs kebalikan yang dihitung dari kurs tersimpan
+const inverseRatePrecision = 10
+
+// ErrRateNotFound dikembalikan ketika kurs untuk pasangan mata uang tidak tersedia
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Rate adalah kurs pertukaran dari mata uang Base ke Quote.
+// Value adalah jumlah Quote untuk setiap satu Base dalam bentuk desimal, contoh "15650.25".
+type Rate struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Value     string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RateProvider menyediakan kurs pertukaran antar mata uang
+type RateProvider interface {
+	Rate(base, quote string) (Rate, error)
+}
+
+// Rat mengembalikan nilai kurs sebagai pecahan eksak
+func (r Rate) Rat() (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(r.Value))
+	if !ok || value.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q for %s/%s", r.Value, r.Base, r.Quote)
+	}
+	return value, nil
+}
+
+// Inverse mengembalikan kurs kebalikan dari Quote ke Base
+func (r Rate) Inverse() (Rate, error) {
+	value, err := r.Rat()
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{
+		Base:      r.Quote,
+		Quote:     r.Base,
+		Value:     trimZeros(new(big.Rat).Inv(value).FloatString(inverseRatePrecision)),
+		UpdatedAt: r.UpdatedAt,
+	}, nil
+}
+
+// Convert mengonversi nilai dalam mata uang Base ke mata uang Quote menggunakan kurs ini
+func (r Rate) Convert(amount money.Money) (money.Money, error) {
+	if amount.Currency() != strings.ToUpper(r.Base) {
+		return money.Money{}, fmt.Errorf("%w: rate is for %s, amount is %s", money.ErrCurrencyMismatch, r.Base, amount.Currency())
+	}
+
+	value, err := r.Rat()
+	if err != nil {
+		return money.Money{}, err
+	}
+	return amount.Convert(r.Quote, value)
+}
+
+// Identity mengembalikan kurs 1 untuk mata uang yang sama
+func Identity(currency string) Rate {
+	return Rate{
+		Base:      currency,
+		Quote:     currency,
+		Value:     "1",
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Fungsi bantu untuk menghapus angka nol di belakang koma
+func trimZeros(value string) string {
+	if !strings.Contains(value, ".") {
+		return value
+	}
+	return strings.TrimSuffix(strings.TrimRight(value, "0"), ".")
+}
@@ -0,0 +1,116 @@
+package ledger
+
+import "github.com/IbnuFarhanS/Golang_MNC/internal/money"
+
+// CustomerWallet adalah akun saldo wallet pelanggan yang dapat digunakan
+func CustomerWallet(customerID string, currency string) Account {
+	return Account{
+		ID:       "customer:" + customerID + ":wallet",
+		Name:     "Wallet pelanggan " + customerID,
+		Type:     AccountTypeLiability,
+		Currency: currencyOrDefault(currency),
+	}
+}
+
+// CustomerHold adalah akun saldo pelanggan yang sedang ditahan oleh otorisasi
+func CustomerHold(customerID string, currency string) Account {
+	return Account{
+		ID:       "customer:" + customerID + ":hold",
+		Name:     "Saldo ditahan pelanggan " + customerID,
+		Type:     AccountTypeLiability,
+		Currency: currencyOrDefault(currency),
+	}
+}
+
+// MerchantPayable adalah akun utang kepada merchant atas pembayaran yang diterima
+func MerchantPayable(merchantID string, currency string) Account {
+	return Account{
+		ID:       "merchant:" + merchantID + ":payable",
+		Name:     "Utang merchant " + merchantID,
+		Type:     AccountTypeLiability,
+		Currency: currencyOrDefault(currency),
+	}
+}
+
+// MerchantSettlement adalah akun utang kepada merchant yang sudah masuk batch settlement dan menunggu dibayarkan
+func MerchantSettlement(merchantID string, currency string) Account {
+	return Account{
+		ID:       "merchant:" + merchantID + ":settlement",
+		Name:     "Settlement merchant " + merchantID,
+		Type:     AccountTypeLiability,
+		Currency: currencyOrDefault(currency),
+	}
+}
+
+// SettlementCash adalah akun kas yang digunakan untuk membayar settlement ke merchant
+func SettlementCash(currency string) Account {
+	currency = currencyOrDefault(currency)
+	return Account{
+		ID:       "settlement:cash:" + currency,
+		Name:     "Kas settlement " + currency,
+		Type:     AccountTypeAsset,
+		Currency: currency,
+	}
+}
+
+// FundingSource adalah akun kas penampung dana yang masuk dari sumber dana eksternal
+func FundingSource(source string, currency string) Account {
+	return Account{
+		ID:       "funding:" + source,
+		Name:     "Kas sumber dana " + source,
+		Type:     AccountTypeAsset,
+		Currency: currencyOrDefault(currency),
+	}
+}
+
+// FeeRevenue adalah akun pendapatan dari biaya merchant (MDR)
+func FeeRevenue(currency string) Account {
+	currency = currencyOrDefault(currency)
+	return Account{
+		ID:       "revenue:fees:" + currency,
+		Name:     "Pendapatan biaya merchant " + currency,
+		Type:     AccountTypeRevenue,
+		Currency: currency,
+	}
+}
+
+// PromotionExpense adalah akun beban promosi yang menanggung cashback kepada pelanggan
+func PromotionExpense(currency string) Account {
+	currency = currencyOrDefault(currency)
+	return Account{
+		ID:       "expense:promotions:" + currency,
+		Name:     "Beban promosi " + currency,
+		Type:     AccountTypeExpense,
+		Currency: currency,
+	}
+}
+
+// LoyaltyExpense adalah akun beban program loyalitas yang menanggung nilai poin yang ditukar pelanggan
+func LoyaltyExpense(currency string) Account {
+	currency = currencyOrDefault(currency)
+	return Account{
+		ID:       "expense:loyalty:" + currency,
+		Name:     "Beban loyalitas " + currency,
+		Type:     AccountTypeExpense,
+		Currency: currency,
+	}
+}
+
+// FXPosition adalah akun posisi valas yang menampung selisih mata uang ketika pembayaran dikonversi
+func FXPosition(currency string) Account {
+	currency = currencyOrDefault(currency)
+	return Account{
+		ID:       "fx:position:" + currency,
+		Name:     "Posisi valas " + currency,
+		Type:     AccountTypeAsset,
+		Currency: currency,
+	}
+}
+
+// Fungsi bantu untuk mengisi mata uang kosong dengan mata uang default
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return money.DefaultCurrency
+	}
+	return currency
+}
@@ -0,0 +1,66 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// FileStore menyimpan akun dan jurnal buku besar ke file JSON
+type FileStore struct {
+	filePath string
+}
+
+// fileData adalah isi file buku besar
+type fileData struct {
+	Accounts []Account `json:"accounts"`
+	Entries  []Entry   `json:"entries"`
+}
+
+// NewFileStore membuat instance baru dari FileStore
+func NewFileStore(filePath string) *FileStore {
+	return &FileStore{
+		filePath: filePath,
+	}
+}
+
+// Load membaca akun dan jurnal dari file, file yang belum ada dianggap kosong
+func (s *FileStore) Load() ([]Account, []Entry, error) {
+	data, err := ioutil.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read ledger data: %v", err)
+	}
+	if len(data) == 0 {
+		return nil, nil, nil
+	}
+
+	var content fileData
+	err = json.Unmarshal(data, &content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal ledger data: %v", err)
+	}
+
+	return content.Accounts, content.Entries, nil
+}
+
+// Save menulis seluruh akun dan jurnal ke file
+func (s *FileStore) Save(accounts []Account, entries []Entry) error {
+	data, err := json.Marshal(fileData{
+		Accounts: accounts,
+		Entries:  entries,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ledger data: %v", err)
+	}
+
+	err = ioutil.WriteFile(s.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write ledger data to file: %v", err)
+	}
+
+	return nil
+}
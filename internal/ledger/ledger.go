@@ -0,0 +1,316 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Jenis-jenis akun
+const (
+	AccountTypeAsset     = "asset"
+	AccountTypeLiability = "liability"
+	AccountTypeEquity    = "equity"
+	AccountTypeRevenue   = "revenue"
+	AccountTypeExpense   = "expense"
+)
+
+// ErrUnbalancedEntry dikembalikan ketika jumlah debit dan kredit sebuah jurnal tidak sama
+var ErrUnbalancedEntry = errors.New("journal entry is not balanced")
+
+// ErrAccountNotFound dikembalikan ketika akun belum pernah dibuka di buku besar
+var ErrAccountNotFound = errors.New("account not found")
+
+// Account adalah akun pada buku besar
+type Account struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+}
+
+// Leg adalah satu baris jurnal. Amount positif berarti debit dan negatif berarti kredit.
+type Leg struct {
+	AccountID string      `json:"account_id"`
+	Amount    money.Money `json:"amount"`
+
+	// account digunakan untuk membuka akun secara otomatis saat jurnal diposting
+	account Account
+}
+
+// Entry adalah jurnal berpasangan yang seluruh baris debit dan kreditnya harus berjumlah nol
+type Entry struct {
+	ID          string    `json:"id"`
+	Reference   string    `json:"reference"`
+	Description string    `json:"description"`
+	Legs        []Leg     `json:"legs"`
+	PostedAt    time.Time `json:"posted_at"`
+}
+
+// Debit membuat baris debit untuk akun
+func Debit(account Account, amount money.Money) Leg {
+	return Leg{AccountID: account.ID, Amount: amount, account: account}
+}
+
+// Credit membuat baris kredit untuk akun
+func Credit(account Account, amount money.Money) Leg {
+	return Leg{AccountID: account.ID, Amount: amount.Neg(), account: account}
+}
+
+// NewEntry membuat jurnal baru untuk transaksi dengan referensi yang diberikan
+func NewEntry(reference string, description string, legs ...Leg) *Entry {
+	return &Entry{
+		Reference:   reference,
+		Description: description,
+		Legs:        legs,
+	}
+}
+
+// Validate memeriksa bahwa jurnal memiliki minimal dua baris, tidak ada baris bernilai nol,
+// dan jumlah debit serta kredit untuk setiap mata uang sama dengan nol
+func (e *Entry) Validate() error {
+	if len(e.Legs) < 2 {
+		return fmt.Errorf("journal entry must have at least two legs")
+	}
+
+	totals := make(map[string]money.Money)
+	for _, leg := range e.Legs {
+		if leg.AccountID == "" {
+			return fmt.Errorf("journal entry leg has no account")
+		}
+		if leg.Amount.IsZero() {
+			return fmt.Errorf("journal entry leg for %s has zero amount", leg.AccountID)
+		}
+
+		currency := leg.Amount.Currency()
+		total, err := totals[currency].Add(leg.Amount)
+		if err != nil {
+			return err
+		}
+		totals[currency] = total
+	}
+
+	for currency, total := range totals {
+		if !total.IsZero() {
+			return fmt.Errorf("%w: %s off by %s", ErrUnbalancedEntry, currency, total.Decimal())
+		}
+	}
+
+	return nil
+}
+
+// Store adalah penyimpanan akun dan jurnal buku besar
+type Store interface {
+	Load() ([]Account, []Entry, error)
+	Save(accounts []Account, entries []Entry) error
+}
+
+// Ledger adalah buku besar berpasangan (double-entry) yang menjadi sumber kebenaran seluruh pergerakan uang
+type Ledger struct {
+	mu       sync.RWMutex
+	store    Store
+	accounts map[string]Account
+	entries  []Entry
+	sequence int64
+}
+
+// New membuat Ledger dan memuat akun serta jurnal dari store
+func New(store Store) (*Ledger, error) {
+	accounts, entries, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Ledger{
+		store:    store,
+		accounts: make(map[string]Account),
+		entries:  entries,
+	}
+	for _, account := range accounts {
+		l.accounts[account.ID] = account
+	}
+	for _, entry := range entries {
+		id, err := strconv.ParseInt(entry.ID, 10, 64)
+		if err == nil && id > l.sequence {
+			l.sequence = id
+		}
+	}
+
+	return l, nil
+}
+
+// Post memvalidasi lalu menyimpan jurnal. Akun yang belum ada dibuka secara otomatis.
+func (l *Ledger) Post(entry *Entry) error {
+	err := entry.Validate()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Memastikan mata uang setiap baris sesuai dengan mata uang akunnya
+	opened := make([]Account, 0)
+	for _, leg := range entry.Legs {
+		account, ok := l.accounts[leg.AccountID]
+		if !ok {
+			account = leg.account
+			if account.ID == "" {
+				return fmt.Errorf("%w: %s", ErrAccountNotFound, leg.AccountID)
+			}
+			if account.Currency == "" {
+				account.Currency = leg.Amount.Currency()
+			}
+			l.accounts[account.ID] = account
+			opened = append(opened, account)
+		}
+		if account.Currency != leg.Amount.Currency() {
+			l.closeAccounts(opened)
+			return fmt.Errorf("%w: account %s is %s", money.ErrCurrencyMismatch, account.ID, account.Currency)
+		}
+	}
+
+	posted := *entry
+	posted.ID = strconv.FormatInt(l.sequence+1, 10)
+	if posted.PostedAt.IsZero() {
+		posted.PostedAt = time.Now()
+	}
+	l.entries = append(l.entries, posted)
+
+	err = l.store.Save(l.accountList(), l.entries)
+	if err != nil {
+		l.entries = l.entries[:len(l.entries)-1]
+		l.closeAccounts(opened)
+		return err
+	}
+
+	l.sequence++
+	*entry = posted
+	return nil
+}
+
+// Account mengambil akun berdasarkan ID
+func (l *Ledger) Account(accountID string) (Account, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	account, ok := l.accounts[accountID]
+	if !ok {
+		return Account{}, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	return account, nil
+}
+
+// Accounts mengambil semua akun diurutkan berdasarkan ID
+func (l *Ledger) Accounts() []Account {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.accountList()
+}
+
+// Balance mengembalikan saldo akun saat ini
+func (l *Ledger) Balance(accountID string) (money.Money, error) {
+	return l.BalanceAt(accountID, time.Time{})
+}
+
+// BalanceAt mengembalikan saldo akun dari semua jurnal yang diposting sampai waktu tertentu.
+// Waktu nol berarti seluruh jurnal. Saldo dihitung sesuai sisi normal akun, sehingga saldo
+// akun kewajiban seperti wallet pelanggan bernilai positif ketika lebih banyak kredit daripada debit.
+func (l *Ledger) BalanceAt(accountID string, at time.Time) (money.Money, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	account, ok := l.accounts[accountID]
+	if !ok {
+		return money.Money{}, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+
+	total := money.Zero(account.Currency)
+	for _, entry := range l.entries {
+		if !at.IsZero() && entry.PostedAt.After(at) {
+			continue
+		}
+		for _, leg := range entry.Legs {
+			if leg.AccountID != accountID {
+				continue
+			}
+			var err error
+			total, err = total.Add(leg.Amount)
+			if err != nil {
+				return money.Money{}, err
+			}
+		}
+	}
+
+	if !isDebitNormal(account.Type) {
+		total = total.Neg()
+	}
+	return total, nil
+}
+
+// Entries mengambil semua jurnal yang menyentuh akun tertentu, atau semua jurnal jika accountID kosong
+func (l *Ledger) Entries(accountID string) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries := make([]Entry, 0)
+	for _, entry := range l.entries {
+		if accountID == "" {
+			entries = append(entries, entry)
+			continue
+		}
+		for _, leg := range entry.Legs {
+			if leg.AccountID == accountID {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// Fungsi bantu untuk menghapus akun yang baru dibuka ketika posting dibatalkan.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (l *Ledger) closeAccounts(accounts []Account) {
+	for _, account := range accounts {
+		delete(l.accounts, account.ID)
+	}
+}
+
+// Fungsi bantu untuk mengambil daftar akun terurut. Harus dipanggil ketika mutex sudah dikunci.
+func (l *Ledger) accountList() []Account {
+	accounts := make([]Account, 0, len(l.accounts))
+	for _, account := range l.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].ID < accounts[j].ID
+	})
+	return accounts
+}
+
+// Fungsi bantu untuk menentukan apakah saldo normal akun berada di sisi debit
+func isDebitNormal(accountType string) bool {
+	return accountType == AccountTypeAsset || accountType == AccountTypeExpense
+}
+
+// Reverse memposting jurnal pembalik untuk membatalkan jurnal yang sudah diposting.
+// Jurnal yang sudah diposting tidak pernah diubah atau dihapus.
+func (l *Ledger) Reverse(entry *Entry, reason string) error {
+	legs := make([]Leg, 0, len(entry.Legs))
+	for _, leg := range entry.Legs {
+		legs = append(legs, Leg{AccountID: leg.AccountID, Amount: leg.Amount.Neg()})
+	}
+
+	description := fmt.Sprintf("reversal of entry %s", entry.ID)
+	if reason != "" {
+		description += ": " + reason
+	}
+	return l.Post(NewEntry(entry.Reference, description, legs...))
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Status split bill
+const (
+	BillStatusOpen      = "open"
+	BillStatusCapturing = "capturing"
+	BillStatusCompleted = "completed"
+	BillStatusExpired   = "expired"
+	BillStatusCancelled = "cancelled"
+)
+
+// Status bagian peserta split bill
+const (
+	BillShareStatusPending  = "pending"
+	BillShareStatusPaid     = "paid"
+	BillShareStatusCaptured = "captured"
+	BillShareStatusReturned = "returned"
+)
+
+// Bill adalah tagihan merchant yang dibagi ke beberapa pelanggan. Setiap peserta membayar bagiannya dengan
+// menahan saldo, dan pembayaran ke merchant baru di-capture setelah seluruh bagian dibayar. Amount adalah
+// total seluruh bagian dalam mata uang merchant.
+type Bill struct {
+	ID          string      `json:"id"`
+	CreatorID   string      `json:"creator_id"`
+	MerchantID  string      `json:"merchant_id"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+	Shares      []BillShare `json:"shares"`
+	Status      string      `json:"status"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+}
+
+// BillShare adalah bagian satu peserta split bill. TransactionID adalah transaksi otorisasi yang menahan saldo peserta.
+type BillShare struct {
+	CustomerID    string      `json:"customer_id"`
+	Amount        money.Money `json:"amount"`
+	Status        string      `json:"status"`
+	TransactionID string      `json:"transaction_id,omitempty"`
+	PaidAt        *time.Time  `json:"paid_at,omitempty"`
+}
@@ -1,5 +1,13 @@
 package models
 
+// Role dan tier pelanggan
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+
+	TierBasic = "basic"
+)
+
 type Customer struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
@@ -7,4 +15,22 @@ type Customer struct {
 	Password string `json:"password"`
 	Phone    int    `json:"phone"`
 	Token    string `json:"token"`
+	Role     string `json:"role,omitempty"`
+	Tier     string `json:"tier,omitempty"`
+}
+
+// RoleName mengembalikan role pelanggan, kosong berarti user
+func (c *Customer) RoleName() string {
+	if c.Role == "" {
+		return RoleUser
+	}
+	return c.Role
+}
+
+// TierName mengembalikan tier pelanggan yang menentukan batas transaksinya, kosong berarti basic
+func (c *Customer) TierName() string {
+	if c.Tier == "" {
+		return TierBasic
+	}
+	return c.Tier
 }
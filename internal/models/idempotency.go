@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// IdempotencyKey menyimpan respons dari permintaan dengan header Idempotency-Key
+// agar permintaan ulang dengan key yang sama mendapatkan respons yang sama
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	UserID      string    `json:"user_id"`
+	RequestHash string    `json:"request_hash"`
+	Completed   bool      `json:"completed"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        string    `json:"body,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
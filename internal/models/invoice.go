@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Status invoice merchant
+const (
+	InvoiceStatusOpen      = "open"
+	InvoiceStatusPaid      = "paid"
+	InvoiceStatusExpired   = "expired"
+	InvoiceStatusCancelled = "cancelled"
+)
+
+// Invoice adalah permintaan pembayaran yang dibuat merchant dan dibayar oleh pelanggan melalui ID invoice.
+// CustomerID kosong berarti invoice dapat dibayar oleh pelanggan mana pun yang menerima ID invoice.
+// Amount adalah jumlah tagihan dalam mata uang merchant.
+type Invoice struct {
+	ID            string        `json:"id"`
+	MerchantID    string        `json:"merchant_id"`
+	CustomerID    string        `json:"customer_id,omitempty"`
+	Description   string        `json:"description"`
+	Items         []InvoiceItem `json:"items,omitempty"`
+	Amount        money.Money   `json:"amount"`
+	Status        string        `json:"status"`
+	TransactionID string        `json:"transaction_id,omitempty"`
+	PaidBy        string        `json:"paid_by,omitempty"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	PaidAt        *time.Time    `json:"paid_at,omitempty"`
+}
+
+// InvoiceItem adalah satu baris tagihan pada invoice. Amount adalah UnitPrice dikali Quantity.
+type InvoiceItem struct {
+	Name      string      `json:"name"`
+	Quantity  int64       `json:"quantity"`
+	UnitPrice money.Money `json:"unit_price"`
+	Amount    money.Money `json:"amount"`
+}
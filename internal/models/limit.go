@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// SpendingLimit adalah batas pengeluaran pelanggan. Nilai kosong berarti tidak dibatasi.
+type SpendingLimit struct {
+	PerTransaction *money.Money `json:"per_transaction,omitempty"`
+	Daily          *money.Money `json:"daily,omitempty"`
+	Monthly        *money.Money `json:"monthly,omitempty"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// LimitConfig berisi batas pengeluaran per tier dan batas khusus per pelanggan.
+// Batas khusus pelanggan menggantikan batas tier untuk setiap nilai yang diisi.
+type LimitConfig struct {
+	Tiers     map[string]SpendingLimit `json:"tiers"`
+	Customers map[string]SpendingLimit `json:"customers"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Jenis-jenis catatan poin loyalitas
+const (
+	// PointsEntryEarn adalah poin yang didapat dari pembayaran
+	PointsEntryEarn = "earn"
+	// PointsEntryRedeem adalah poin yang ditukar sebagai potongan pembayaran
+	PointsEntryRedeem = "redeem"
+	// PointsEntryRelease adalah poin yang dikembalikan karena pembayaran yang menukarnya gagal
+	PointsEntryRelease = "release"
+	// PointsEntryRefund adalah poin yang dikembalikan karena pembayaran yang menukarnya direfund
+	PointsEntryRefund = "refund"
+	// PointsEntryReverse adalah poin yang ditarik kembali karena pembayaran yang menghasilkannya direfund
+	PointsEntryReverse = "reverse"
+	// PointsEntryExpire adalah poin yang hangus karena melewati masa berlakunya
+	PointsEntryExpire = "expire"
+)
+
+// EarnRate adalah aturan perolehan poin: Points poin untuk setiap kelipatan Per yang dibayar dalam mata uang merchant
+type EarnRate struct {
+	Points    int64       `json:"points"`
+	Per       money.Money `json:"per"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// LoyaltyConfig berisi pengaturan program poin loyalitas. PointValue adalah nilai satu poin ketika ditukar,
+// ExpiryDays adalah masa berlaku poin sejak didapat. Merchants berisi aturan perolehan poin khusus per merchant,
+// merchant yang tidak terdaftar menggunakan Default jika mata uangnya sama.
+type LoyaltyConfig struct {
+	PointValue money.Money         `json:"point_value"`
+	ExpiryDays int                 `json:"expiry_days"`
+	Default    *EarnRate           `json:"default,omitempty"`
+	Merchants  map[string]EarnRate `json:"merchants"`
+}
+
+// PointsLot adalah bagian poin yang diambil dari satu catatan poin yang masih tersisa
+type PointsLot struct {
+	EntryID string `json:"entry_id"`
+	Points  int64  `json:"points"`
+}
+
+// PointsEntry adalah satu catatan pada buku poin pelanggan. Points bernilai positif untuk poin yang bertambah
+// dan negatif untuk poin yang berkurang. Catatan yang menambah poin memiliki Remaining, yaitu sisa poin yang
+// belum dipakai sampai ExpiresAt. Catatan yang mengurangi poin mencatat sisa poin yang dipakainya pada Lots.
+type PointsEntry struct {
+	ID            string      `json:"id"`
+	CustomerID    string      `json:"customer_id"`
+	Type          string      `json:"type"`
+	Points        int64       `json:"points"`
+	Remaining     int64       `json:"remaining,omitempty"`
+	Lots          []PointsLot `json:"lots,omitempty"`
+	TransactionID string      `json:"transaction_id,omitempty"`
+	MerchantID    string      `json:"merchant_id,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	ExpiresAt     *time.Time  `json:"expires_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
@@ -1,6 +1,39 @@
 package models
 
+import "github.com/IbnuFarhanS/Golang_MNC/internal/money"
+
+// Jenis-jenis skema biaya merchant (MDR)
+const (
+	FeeTypePercentage = "percentage"
+	FeeTypeFlat       = "flat"
+	FeeTypeTiered     = "tiered"
+)
+
+// Merchant adalah penerima pembayaran. Currency adalah mata uang yang digunakan merchant
+// untuk menagih pembayaran, kosong berarti IDR. Fee adalah skema biaya (MDR) yang dipotong
+// dari setiap pembayaran, kosong berarti tanpa biaya. APIKeyHash adalah hash SHA-256 (hex) dari API key
+// yang digunakan merchant untuk mengakses API merchant, kosong berarti merchant tidak dapat mengakses API.
 type Merchant struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	Currency   string       `json:"currency,omitempty"`
+	Fee        *FeeSchedule `json:"fee,omitempty"`
+	APIKeyHash string       `json:"api_key_hash,omitempty"`
+}
+
+// FeeSchedule adalah skema biaya merchant.
+// Percentage ditulis dalam persen, contoh "0.7" berarti 0.7% dari jumlah pembayaran.
+type FeeSchedule struct {
+	Type       string       `json:"type"`
+	Percentage string       `json:"percentage,omitempty"`
+	Flat       *money.Money `json:"flat,omitempty"`
+	Tiers      []FeeTier    `json:"tiers,omitempty"`
+}
+
+// FeeTier adalah satu tingkatan pada skema biaya bertingkat. Tingkatan berlaku untuk pembayaran
+// sampai dengan UpTo (kosong berarti tanpa batas) dan biayanya adalah Percentage ditambah Flat.
+type FeeTier struct {
+	UpTo       *money.Money `json:"up_to,omitempty"`
+	Percentage string       `json:"percentage,omitempty"`
+	Flat       *money.Money `json:"flat,omitempty"`
 }
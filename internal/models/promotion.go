@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Jenis-jenis promosi
+const (
+	PromotionTypeDiscount = "discount"
+	PromotionTypeCashback = "cashback"
+)
+
+// Status pemakaian promosi
+const (
+	RedemptionStatusReserved = "reserved"
+	RedemptionStatusRedeemed = "redeemed"
+	RedemptionStatusReleased = "released"
+)
+
+// Promotion adalah kampanye diskon atau cashback. Code kosong berarti promosi berlaku otomatis tanpa kode voucher.
+// Diskon mengurangi jumlah yang dibayar ke merchant, sedangkan cashback dikembalikan ke wallet pelanggan setelah
+// pembayaran berhasil. Besarnya adalah Percentage (dalam persen) dari jumlah pembayaran ditambah Flat, dibatasi
+// MaxBenefit. MerchantID kosong berarti berlaku di semua merchant, Tiers dan CustomerIDs kosong berarti berlaku
+// untuk semua pelanggan, EndsAt kosong berarti promosi tidak memiliki batas akhir. Quota adalah jumlah maksimal pemakaian seluruh pelanggan dan PerCustomerLimit adalah
+// jumlah maksimal pemakaian per pelanggan, nol berarti tidak dibatasi.
+type Promotion struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	Code             string       `json:"code,omitempty"`
+	Type             string       `json:"type"`
+	Percentage       string       `json:"percentage,omitempty"`
+	Flat             *money.Money `json:"flat,omitempty"`
+	MaxBenefit       *money.Money `json:"max_benefit,omitempty"`
+	MerchantID       string       `json:"merchant_id,omitempty"`
+	MinAmount        *money.Money `json:"min_amount,omitempty"`
+	Tiers            []string     `json:"tiers,omitempty"`
+	CustomerIDs      []string     `json:"customer_ids,omitempty"`
+	Quota            int          `json:"quota,omitempty"`
+	PerCustomerLimit int          `json:"per_customer_limit,omitempty"`
+	StartsAt         time.Time    `json:"starts_at"`
+	EndsAt           *time.Time   `json:"ends_at,omitempty"`
+	Active           bool         `json:"active"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// Redemption adalah satu pemakaian promosi. Kuota dipesan (reserved) sebelum pembayaran diproses, lalu menjadi
+// redeemed jika pembayaran berhasil atau released jika pembayaran gagal sehingga kuota dapat dipakai kembali.
+type Redemption struct {
+	ID            string      `json:"id"`
+	PromotionID   string      `json:"promotion_id"`
+	CustomerID    string      `json:"customer_id"`
+	TransactionID string      `json:"transaction_id,omitempty"`
+	Benefit       money.Money `json:"benefit"`
+	Status        string      `json:"status"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
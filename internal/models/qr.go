@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Status QR dinamis
+const (
+	QRCodeStatusActive = "active"
+	QRCodeStatusPaid   = "paid"
+)
+
+// QRCode adalah QR dinamis yang dibuat merchant. Jumlah dan referensi QR dinamis disimpan saat QR dibuat sehingga
+// pembayaran tidak hanya bergantung pada isi payload yang dipindai, yang hanya dilindungi checksum CRC16.
+// TransactionID diisi dengan pembayaran yang melunasi QR.
+type QRCode struct {
+	Reference     string      `json:"reference"`
+	MerchantID    string      `json:"merchant_id"`
+	Amount        money.Money `json:"amount"`
+	Status        string      `json:"status"`
+	TransactionID string      `json:"transaction_id,omitempty"`
+	ExpiresAt     time.Time   `json:"expires_at"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
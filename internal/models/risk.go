@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Keputusan pemeriksaan risiko pembayaran, diurutkan dari yang paling ringan
+const (
+	// RiskDecisionAllow berarti pembayaran diproses tanpa catatan
+	RiskDecisionAllow = "allow"
+	// RiskDecisionReview berarti pembayaran tetap diproses tetapi ditandai untuk diperiksa admin
+	RiskDecisionReview = "review"
+	// RiskDecisionDeny berarti pembayaran ditolak
+	RiskDecisionDeny = "deny"
+)
+
+// RiskReason adalah alasan sebuah aturan risiko tidak mengizinkan pembayaran begitu saja
+type RiskReason struct {
+	Rule     string `json:"rule"`
+	Decision string `json:"decision"`
+	Message  string `json:"message"`
+}
+
+// RiskEvent mencatat pembayaran yang ditolak atau ditandai oleh pemeriksaan risiko. TransactionID hanya diisi
+// untuk pembayaran yang ditandai karena pembayaran yang ditolak tidak disimpan sebagai transaksi.
+type RiskEvent struct {
+	ID            string       `json:"id"`
+	CustomerID    string       `json:"customer_id"`
+	MerchantID    string       `json:"merchant_id"`
+	TransactionID string       `json:"transaction_id,omitempty"`
+	Amount        money.Money  `json:"amount"`
+	DeviceID      string       `json:"device_id,omitempty"`
+	Decision      string       `json:"decision"`
+	Reasons       []RiskReason `json:"reasons"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Frekuensi pembayaran terjadwal
+const (
+	ScheduleFrequencyOnce    = "once"
+	ScheduleFrequencyWeekly  = "weekly"
+	ScheduleFrequencyMonthly = "monthly"
+)
+
+// Status pembayaran terjadwal
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusFailed    = "failed"
+)
+
+// Status satu kali eksekusi pembayaran terjadwal
+const (
+	ScheduleRunSucceeded   = "succeeded"
+	ScheduleRunFailed      = "failed"
+	ScheduleRunInterrupted = "interrupted"
+)
+
+// Schedule adalah pembayaran ke merchant yang dijalankan pada waktu tertentu, sekali atau berulang.
+// OccurrenceAt adalah jadwal pembayaran yang sedang diproses, sedangkan NextRunAt adalah waktu percobaan
+// berikutnya yang mundur (backoff) ketika pembayaran gagal. Attempts adalah jumlah percobaan untuk OccurrenceAt.
+// RunningSince diisi selama pembayaran sedang dijalankan sehingga pembayaran yang terputus tidak diulang.
+type Schedule struct {
+	ID           string        `json:"id"`
+	CustomerID   string        `json:"customer_id"`
+	MerchantID   string        `json:"merchant_id"`
+	Amount       money.Money   `json:"amount"`
+	Frequency    string        `json:"frequency"`
+	StartAt      time.Time     `json:"start_at"`
+	EndAt        *time.Time    `json:"end_at,omitempty"`
+	Occurrences  int           `json:"occurrences"`
+	OccurrenceAt time.Time     `json:"occurrence_at"`
+	NextRunAt    *time.Time    `json:"next_run_at,omitempty"`
+	Attempts     int           `json:"attempts"`
+	RunningSince *time.Time    `json:"running_since,omitempty"`
+	Status       string        `json:"status"`
+	Runs         []ScheduleRun `json:"runs"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// ScheduleRun mencatat satu kali percobaan pembayaran terjadwal
+type ScheduleRun struct {
+	OccurrenceAt  time.Time `json:"occurrence_at"`
+	At            time.Time `json:"at"`
+	Attempt       int       `json:"attempt"`
+	Status        string    `json:"status"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
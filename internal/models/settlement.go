@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Status batch settlement
+const (
+	SettlementStatusPending = "pending"
+	SettlementStatusPaid    = "paid"
+)
+
+// SettlementBatch adalah kumpulan transaksi merchant dalam satu periode cut-off yang dibayarkan sekaligus.
+// Seluruh jumlah dalam mata uang merchant (Currency). Gross dan Fees adalah total pembayaran sebelum biaya
+// dan total biaya merchant, Refunds dan RefundedFees adalah total refund dan biaya yang dikembalikan,
+// sedangkan Net adalah jumlah yang harus dibayarkan ke merchant dan bernilai negatif jika refund lebih besar.
+type SettlementBatch struct {
+	ID             string      `json:"id"`
+	MerchantID     string      `json:"merchant_id"`
+	Currency       string      `json:"currency"`
+	PeriodStart    time.Time   `json:"period_start"`
+	PeriodEnd      time.Time   `json:"period_end"`
+	PaymentCount   int         `json:"payment_count"`
+	RefundCount    int         `json:"refund_count"`
+	Gross          money.Money `json:"gross"`
+	Fees           money.Money `json:"fees"`
+	Refunds        money.Money `json:"refunds"`
+	RefundedFees   money.Money `json:"refunded_fees"`
+	Net            money.Money `json:"net"`
+	TransactionIDs []string    `json:"transaction_ids"`
+	Status         string      `json:"status"`
+	CreatedAt      time.Time   `json:"created_at"`
+	PaidAt         *time.Time  `json:"paid_at,omitempty"`
+}
@@ -1,10 +1,92 @@
 package models
 
-// Transaction represents a transaction
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Jenis-jenis transaksi
+const (
+	TransactionTypePayment     = "payment"
+	TransactionTypeTopUp       = "topup"
+	TransactionTypeTransferOut = "transfer_out"
+	TransactionTypeTransferIn  = "transfer_in"
+	TransactionTypeRefund      = "refund"
+	TransactionTypeCashback    = "cashback"
+	// TransactionTypeCashbackReversal adalah penarikan kembali cashback karena pembayarannya direfund
+	TransactionTypeCashbackReversal = "cashback_reversal"
+	// TransactionTypePointsRedemption adalah bagian pembayaran yang dibayar dengan penukaran poin loyalitas
+	TransactionTypePointsRedemption = "points_redemption"
+	// TransactionTypePointsRedemptionReversal adalah penarikan kembali nilai penukaran poin karena pembayarannya direfund
+	TransactionTypePointsRedemptionReversal = "points_redemption_reversal"
+)
+
+// Status siklus hidup transaksi
+const (
+	TransactionStatusPending    = "pending"
+	TransactionStatusAuthorized = "authorized"
+	TransactionStatusCaptured   = "captured"
+	TransactionStatusFailed     = "failed"
+	TransactionStatusVoided     = "voided"
+	TransactionStatusRefunded   = "refunded"
+)
+
+// StatusChange mencatat satu perubahan status transaksi
+type StatusChange struct {
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// Transaction represents a transaction.
+// Amount selalu dalam mata uang wallet pelanggan (Currency). Untuk pembayaran ke merchant dengan
+// mata uang berbeda, OriginalAmount berisi jumlah dalam mata uang merchant dan ExchangeRate berisi
+// kurs yang dikunci pada RateLockedAt untuk mengonversi OriginalAmount menjadi Amount.
+// Gross, Fee, dan Net adalah jumlah dalam mata uang merchant sebelum biaya, biaya merchant (MDR),
+// dan jumlah bersih yang menjadi hak merchant. SettlementID diisi ketika transaksi sudah dibayarkan ke merchant.
+// BillID diisi untuk pembayaran bagian split bill yang di-capture dan dilepas melalui bill tersebut.
+// Reference adalah referensi pembayaran dari QR dinamis merchant.
+// PromotionID dan VoucherCode diisi untuk pembayaran yang mendapat promosi. Discount adalah potongan dalam mata uang
+// merchant yang sudah dikurangkan dari jumlah pembayaran, Cashback adalah jumlah dalam mata uang wallet yang dikembalikan
+// ke wallet pelanggan melalui transaksi cashback.
+// PointsRedeemed adalah poin loyalitas yang ditukar untuk pembayaran senilai PointsDiscount dalam mata uang wallet,
+// sedangkan PointsEarned adalah poin yang didapat pelanggan dari pembayaran.
+// DeviceID adalah perangkat yang digunakan untuk membayar, RiskDecision dan RiskReasons diisi untuk pembayaran
+// yang ditandai untuk diperiksa oleh pemeriksaan risiko.
 type Transaction struct {
-	ID          string  `json:"id"`
-	CustomerID  string  `json:"customer_id"`
-	MerchantID  string  `json:"merchant_id"`
-	Amount      float64 `json:"amount"`
-	Description string  `json:"description"`
+	ID             string         `json:"id"`
+	Type           string         `json:"type,omitempty"`
+	CustomerID     string         `json:"customer_id"`
+	MerchantID     string         `json:"merchant_id,omitempty"`
+	CounterpartyID string         `json:"counterparty_id,omitempty"`
+	OriginalID     string         `json:"original_id,omitempty"`
+	Currency       string         `json:"currency,omitempty"`
+	Amount         money.Money    `json:"amount"`
+	OriginalAmount *money.Money   `json:"original_amount,omitempty"`
+	ExchangeRate   string         `json:"exchange_rate,omitempty"`
+	RateLockedAt   *time.Time     `json:"rate_locked_at,omitempty"`
+	Gross          money.Money    `json:"gross"`
+	Fee            money.Money    `json:"fee"`
+	Net            money.Money    `json:"net"`
+	HoldAmount     *money.Money   `json:"hold_amount,omitempty"`
+	HoldExpiresAt  *time.Time     `json:"hold_expires_at,omitempty"`
+	Description    string         `json:"description"`
+	Status         string         `json:"status,omitempty"`
+	StatusHistory  []StatusChange `json:"status_history,omitempty"`
+	BillID         string         `json:"bill_id,omitempty"`
+	Reference      string         `json:"reference,omitempty"`
+	PromotionID    string         `json:"promotion_id,omitempty"`
+	VoucherCode    string         `json:"voucher_code,omitempty"`
+	Discount       *money.Money   `json:"discount,omitempty"`
+	Cashback       *money.Money   `json:"cashback,omitempty"`
+	PointsRedeemed int64          `json:"points_redeemed,omitempty"`
+	PointsDiscount *money.Money   `json:"points_discount,omitempty"`
+	PointsEarned   int64          `json:"points_earned,omitempty"`
+	DeviceID       string         `json:"device_id,omitempty"`
+	RiskDecision   string         `json:"risk_decision,omitempty"`
+	RiskReasons    []RiskReason   `json:"risk_reasons,omitempty"`
+	SettlementID   string         `json:"settlement_id,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Wallet menyimpan saldo milik seorang pelanggan.
+// Held adalah bagian saldo yang sedang ditahan oleh otorisasi pembayaran dan belum di-capture.
+// Balance dan Held selalu dalam mata uang wallet, default IDR (money.DefaultCurrency).
+type Wallet struct {
+	CustomerID string      `json:"customer_id"`
+	Currency   string      `json:"currency"`
+	Balance    money.Money `json:"balance"`
+	Held       money.Money `json:"held"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// Available mengembalikan saldo yang masih dapat digunakan
+func (w *Wallet) Available() money.Money {
+	// Held selalu dalam mata uang yang sama dengan Balance
+	available, _ := w.Balance.Sub(w.Held)
+	return available
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Jenis-jenis event transaksi yang dikirim ke webhook merchant
+const (
+	EventPaymentSucceeded  = "payment.succeeded"
+	EventPaymentAuthorized = "payment.authorized"
+	EventPaymentVoided     = "payment.voided"
+	EventPaymentFailed     = "payment.failed"
+	EventRefundCreated     = "refund.created"
+)
+
+// EventTypes adalah seluruh jenis event yang dapat dilanggan oleh endpoint webhook
+var EventTypes = []string{
+	EventPaymentSucceeded,
+	EventPaymentAuthorized,
+	EventPaymentVoided,
+	EventPaymentFailed,
+	EventRefundCreated,
+}
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+	// WebhookDeliveryCanceled adalah pengiriman yang tidak dicoba lagi karena endpoint-nya sudah dihapus
+	WebhookDeliveryCanceled = "canceled"
+)
+
+// WebhookConfig adalah pengaturan webhook satu merchant. Secret digunakan untuk menandatangani (HMAC-SHA256)
+// setiap pengiriman ke seluruh endpoint merchant.
+type WebhookConfig struct {
+	MerchantID string            `json:"merchant_id"`
+	Secret     string            `json:"secret"`
+	Endpoints  []WebhookEndpoint `json:"endpoints"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// WebhookEndpoint adalah URL penerima event merchant. Events kosong berarti seluruh jenis event.
+type WebhookEndpoint struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookEvent adalah isi (body) yang dikirim ke endpoint webhook
+type WebhookEvent struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	CreatedAt time.Time         `json:"created_at"`
+	Data      *WebhookEventData `json:"data"`
+}
+
+// WebhookEventData adalah data transaksi yang dikirim ke merchant, hanya berisi data yang perlu diketahui merchant
+// dan bukan seluruh data transaksi internal. Amount dalam mata uang merchant, OriginalID diisi untuk refund dengan
+// ID pembayaran yang direfund, dan Reference adalah referensi pembayaran dari QR dinamis merchant.
+type WebhookEventData struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Status     string      `json:"status"`
+	Amount     money.Money `json:"amount"`
+	Currency   string      `json:"currency"`
+	OriginalID string      `json:"original_id,omitempty"`
+	Reference  string      `json:"reference,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// WebhookDelivery adalah satu pengiriman event ke satu endpoint yang disimpan di outbox sampai berhasil
+// atau percobaan habis. RedeliveryOf diisi untuk pengiriman ulang manual dari pengiriman lain.
+type WebhookDelivery struct {
+	ID            string           `json:"id"`
+	MerchantID    string           `json:"merchant_id"`
+	EndpointID    string           `json:"endpoint_id"`
+	URL           string           `json:"url"`
+	EventID       string           `json:"event_id"`
+	EventType     string           `json:"event_type"`
+	Payload       json.RawMessage  `json:"payload"`
+	Status        string           `json:"status"`
+	Attempts      []WebhookAttempt `json:"attempts"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+	RedeliveryOf  string           `json:"redelivery_of,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	DeliveredAt   *time.Time       `json:"delivered_at,omitempty"`
+}
+
+// WebhookAttempt mencatat satu percobaan pengiriman webhook beserta hasilnya
+type WebhookAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
@@ -0,0 +1,380 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// DefaultCurrency adalah mata uang yang digunakan jika mata uang tidak disebutkan
+const DefaultCurrency = "IDR"
+
+// exponents berisi jumlah digit desimal (minor unit) setiap mata uang yang didukung sesuai ISO 4217
+var exponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"SGD": 2,
+}
+
+// ErrCurrencyMismatch dikembalikan ketika operasi dilakukan pada dua nilai dengan mata uang berbeda
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ErrOverflow dikembalikan ketika hasil operasi berada di luar rentang nilai yang dapat disimpan
+var ErrOverflow = errors.New("money amount overflow")
+
+// decimalPattern adalah format nilai desimal yang diterima Parse, tanpa notasi heksadesimal, biner, pecahan, atau pemisah ribuan
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Money adalah nilai uang yang disimpan sebagai bilangan bulat dalam minor unit (misalnya sen) beserta kode mata uangnya.
+// Nilai nol dari Money adalah nol dalam DefaultCurrency dan dapat dijumlahkan dengan mata uang apa pun.
+// Parse, Add, Sub, dan Mul hanya menghasilkan nilai dalam rentang -MaxInt64 sampai MaxInt64 minor unit
+// sehingga setiap nilai dapat dinegasikan.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// New membuat Money dari jumlah dalam minor unit
+func New(minorUnits int64, currency string) Money {
+	return Money{amount: minorUnits, currency: normalizeCurrency(currency)}
+}
+
+// Zero mengembalikan nilai nol dalam mata uang yang diberikan
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse membaca nilai desimal seperti "10000", "10000.50", atau "-2.5" dalam mata uang yang diberikan.
+// Nilai dengan digit desimal lebih banyak dari yang didukung mata uang akan ditolak, begitu juga bentuk lain
+// seperti "0x10", "100/2", "1_000", atau "1e3".
+func Parse(value string, currency string) (Money, error) {
+	currency = normalizeCurrency(currency)
+	exponent, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if !decimalPattern.MatchString(trimmed) {
+		return Money{}, fmt.Errorf("invalid money amount %q", value)
+	}
+	rat, ok := new(big.Rat).SetString(trimmed)
+	if !ok {
+		return Money{}, fmt.Errorf("invalid money amount %q", value)
+	}
+
+	minor := rat.Mul(rat, new(big.Rat).SetInt(pow10(exponent)))
+	if !minor.IsInt() {
+		return Money{}, fmt.Errorf("money amount %q has more than %d decimal places", value, exponent)
+	}
+	if !minor.Num().IsInt64() || minor.Num().Int64() == math.MinInt64 {
+		return Money{}, fmt.Errorf("money amount %q is out of range", value)
+	}
+
+	return New(minor.Num().Int64(), currency), nil
+}
+
+// MustParse sama seperti Parse namun panic jika nilai tidak valid, hanya untuk nilai konstan
+func MustParse(value string, currency string) Money {
+	m, err := Parse(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Exponent mengembalikan jumlah digit desimal mata uang
+func Exponent(currency string) (int, error) {
+	exponent, ok := exponents[normalizeCurrency(currency)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", currency)
+	}
+	return exponent, nil
+}
+
+// IsSupported memeriksa apakah mata uang didukung
+func IsSupported(currency string) bool {
+	_, err := Exponent(currency)
+	return err == nil
+}
+
+// Amount mengembalikan jumlah dalam minor unit
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency mengembalikan kode mata uang
+func (m Money) Currency() string {
+	return normalizeCurrency(m.currency)
+}
+
+// IsZero memeriksa apakah nilai sama dengan nol
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// IsPositive memeriksa apakah nilai lebih besar dari nol
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// IsNegative memeriksa apakah nilai lebih kecil dari nol
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// Neg mengembalikan nilai negatif. MinInt64 yang hanya dapat dibuat melalui New dibatasi menjadi MaxInt64.
+func (m Money) Neg() Money {
+	if m.amount == math.MinInt64 {
+		return New(math.MaxInt64, m.Currency())
+	}
+	return New(-m.amount, m.Currency())
+}
+
+// SameCurrency memeriksa apakah dua nilai memiliki mata uang yang sama.
+// Nilai nol dari Money dianggap cocok dengan mata uang apa pun.
+func (m Money) SameCurrency(other Money) bool {
+	_, err := commonCurrency(m, other)
+	return err == nil
+}
+
+// Add menjumlahkan dua nilai dengan mata uang yang sama
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	sum := m.amount + other.amount
+	if (other.amount > 0 && sum < m.amount) || (other.amount < 0 && sum > m.amount) || sum == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, other)
+	}
+	return New(sum, currency), nil
+}
+
+// Sub mengurangi nilai dengan nilai lain dalam mata uang yang sama
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	difference := m.amount - other.amount
+	if (other.amount < 0 && difference < m.amount) || (other.amount > 0 && difference > m.amount) || difference == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, other)
+	}
+	return New(difference, currency), nil
+}
+
+// Cmp membandingkan dua nilai dengan mata uang yang sama dan mengembalikan -1, 0, atau 1
+func (m Money) Cmp(other Money) (int, error) {
+	_, err := commonCurrency(m, other)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < other.amount:
+		return -1, nil
+	case m.amount > other.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Mul mengalikan nilai dengan bilangan bulat
+func (m Money) Mul(factor int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(factor))
+	if !product.IsInt64() || product.Int64() == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s x %d", ErrOverflow, m, factor)
+	}
+	return New(product.Int64(), m.Currency()), nil
+}
+
+// MulRat mengalikan nilai dengan pecahan numerator/denominator, dibulatkan ke minor unit terdekat (half up)
+func (m Money) MulRat(numerator, denominator int64) (Money, error) {
+	if denominator == 0 {
+		return Money{}, fmt.Errorf("division by zero: %s x %d/%d", m, numerator, denominator)
+	}
+	return m.Scale(big.NewRat(numerator, denominator))
+}
+
+// Scale mengalikan nilai dengan pecahan factor, dibulatkan ke minor unit terdekat (half up)
+func (m Money) Scale(factor *big.Rat) (Money, error) {
+	product := new(big.Rat).Mul(big.NewRat(m.amount, 1), factor)
+	result, err := roundHalfUp(product)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %s x %s", err, m, factor.RatString())
+	}
+	return New(result, m.Currency()), nil
+}
+
+// Convert mengonversi nilai ke mata uang lain dengan kurs rate (jumlah satuan mata uang tujuan
+// untuk setiap satu satuan mata uang asal), dibulatkan ke minor unit terdekat (half up)
+func (m Money) Convert(currency string, rate *big.Rat) (Money, error) {
+	if rate == nil || rate.Sign() <= 0 {
+		return Money{}, fmt.Errorf("invalid exchange rate")
+	}
+	fromExponent, err := Exponent(m.Currency())
+	if err != nil {
+		return Money{}, err
+	}
+	toExponent, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// amount / 10^fromExponent * rate * 10^toExponent
+	product := new(big.Rat).Mul(big.NewRat(m.amount, 1), rate)
+	product.Mul(product, new(big.Rat).SetFrac(pow10(toExponent), pow10(fromExponent)))
+	if new(big.Rat).Abs(product).Cmp(new(big.Rat).SetInt64(math.MaxInt64)) >= 0 {
+		return Money{}, fmt.Errorf("money amount %s is out of range after conversion", m)
+	}
+
+	result, err := roundHalfUp(product)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %s converted to %s", err, m, currency)
+	}
+	return New(result, currency), nil
+}
+
+// Sum menjumlahkan beberapa nilai dengan mata uang yang sama
+func Sum(currency string, values ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, value := range values {
+		var err error
+		total, err = total.Add(value)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Decimal mengembalikan nilai dalam format desimal, contoh "10000.50"
+func (m Money) Decimal() string {
+	exponent, err := Exponent(m.Currency())
+	if err != nil || exponent == 0 {
+		return fmt.Sprintf("%d", m.amount)
+	}
+
+	// Nilai absolut dihitung sebagai uint64 agar MinInt64 tetap dapat ditulis
+	sign := ""
+	amount := uint64(m.amount)
+	if m.amount < 0 {
+		sign = "-"
+		amount = uint64(-(m.amount + 1)) + 1
+	}
+	unit := pow10(exponent).Uint64()
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, exponent, amount%unit)
+}
+
+// String mengembalikan nilai beserta mata uangnya, contoh "IDR 10000.50"
+func (m Money) String() string {
+	return m.Currency() + " " + m.Decimal()
+}
+
+// moneyJSON adalah bentuk JSON dari Money
+type moneyJSON struct {
+	Value    json.Number `json:"value"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON mengodekan Money menjadi {"value": "10000.50", "currency": "IDR"}.
+// Nilai ditulis sebagai string agar tidak kehilangan presisi.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Value    string `json:"value"`
+		Currency string `json:"currency"`
+	}{
+		Value:    m.Decimal(),
+		Currency: m.Currency(),
+	})
+}
+
+// UnmarshalJSON membaca Money dari angka (10000.5), string ("10000.50"), atau
+// objek {"value": "10000.50", "currency": "USD"}. Angka dan string menggunakan DefaultCurrency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	var value, currency string
+	switch data[0] {
+	case '{':
+		var obj moneyJSON
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err := decoder.Decode(&obj)
+		if err != nil {
+			return fmt.Errorf("invalid money object: %v", err)
+		}
+		value, currency = obj.Value.String(), obj.Currency
+	case '"':
+		err := json.Unmarshal(data, &value)
+		if err != nil {
+			return fmt.Errorf("invalid money string: %v", err)
+		}
+	default:
+		value = string(data)
+	}
+
+	parsed, err := Parse(value, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Fungsi bantu untuk menentukan mata uang hasil operasi dua nilai.
+// Nilai nol dari Money (tanpa mata uang) mengikuti mata uang nilai lainnya.
+func commonCurrency(a, b Money) (string, error) {
+	switch {
+	case a.currency == "" && a.amount == 0:
+		return b.Currency(), nil
+	case b.currency == "" && b.amount == 0:
+		return a.Currency(), nil
+	case a.Currency() != b.Currency():
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.Currency(), b.Currency())
+	}
+	return a.Currency(), nil
+}
+
+// Fungsi bantu untuk mengisi mata uang kosong dengan DefaultCurrency
+func normalizeCurrency(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+// Fungsi bantu untuk menghitung 10 pangkat n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Fungsi bantu untuk membulatkan pecahan ke bilangan bulat terdekat, nilai tengah dibulatkan menjauhi nol.
+// Mengembalikan ErrOverflow jika hasilnya di luar rentang -MaxInt64 sampai MaxInt64.
+func roundHalfUp(r *big.Rat) (int64, error) {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+	negative := num.Sign() < 0
+	num.Abs(num)
+
+	// (2*num + den) / (2*den)
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, den)
+	result := num.Quo(num, new(big.Int).Mul(den, big.NewInt(2)))
+	if !result.IsInt64() {
+		return 0, ErrOverflow
+	}
+	if negative {
+		return -result.Int64(), nil
+	}
+	return result.Int64(), nil
+}
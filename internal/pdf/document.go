@@ -0,0 +1,167 @@
+// Package pdf menyediakan penulis dokumen PDF sederhana berisi teks tanpa dependensi eksternal.
+// Dokumen menggunakan font standar PDF (Helvetica dan Courier) dengan encoding WinAnsi sehingga
+// karakter di luar Latin-1 diganti dengan tanda tanya.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Ukuran halaman A4 dan margin dalam satuan point (1/72 inci)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+	Margin     = 50.0
+)
+
+// lineSpacing adalah jarak antar baris relatif terhadap ukuran font
+const lineSpacing = 1.4
+
+// Font adalah jenis font standar yang dapat digunakan pada dokumen
+type Font int
+
+// Jenis-jenis font standar
+const (
+	FontRegular Font = iota
+	FontBold
+	FontMono
+)
+
+// Nama font standar PDF sesuai urutan Font
+var fontNames = []string{"Helvetica", "Helvetica-Bold", "Courier"}
+
+// Document adalah dokumen PDF yang ditulis baris per baris dari atas ke bawah.
+// Halaman baru dibuat otomatis ketika baris berikutnya melewati margin bawah.
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	y     float64
+	font  Font
+	size  float64
+}
+
+// New membuat dokumen PDF baru dengan judul pada metadata dokumen
+func New(title string) *Document {
+	d := &Document{title: title, font: FontRegular, size: 11}
+	d.AddPage()
+	return d
+}
+
+// AddPage menambahkan halaman baru dan memindahkan posisi tulis ke atas halaman
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = PageHeight - Margin
+}
+
+// SetFont mengatur font dan ukuran font untuk baris berikutnya
+func (d *Document) SetFont(font Font, size float64) {
+	d.font = font
+	d.size = size
+}
+
+// Writeln menulis satu baris teks pada margin kiri lalu pindah ke baris berikutnya
+func (d *Document) Writeln(text string) {
+	d.WritelnAt(Margin, text)
+}
+
+// WritelnAt menulis satu baris teks pada posisi x lalu pindah ke baris berikutnya
+func (d *Document) WritelnAt(x float64, text string) {
+	d.Row([]float64{x}, text)
+}
+
+// Row menulis beberapa teks pada satu baris, teks ke-i ditulis pada posisi x ke-i, lalu pindah ke baris berikutnya
+func (d *Document) Row(positions []float64, cells ...string) {
+	height := d.size * lineSpacing
+	if d.y-height < Margin {
+		d.AddPage()
+	}
+	d.y -= height
+
+	page := d.pages[len(d.pages)-1]
+	for i, text := range cells {
+		if i >= len(positions) || text == "" {
+			continue
+		}
+		fmt.Fprintf(page, "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", int(d.font)+1, d.size, positions[i], d.y, escape(text))
+	}
+}
+
+// Space menambahkan jarak kosong setinggi height point
+func (d *Document) Space(height float64) {
+	d.y -= height
+	if d.y < Margin {
+		d.AddPage()
+	}
+}
+
+// Rule menggambar garis horizontal selebar area tulis lalu pindah sedikit ke bawah
+func (d *Document) Rule() {
+	d.Space(4)
+	page := d.pages[len(d.pages)-1]
+	fmt.Fprintf(page, "%.2f w %.2f %.2f m %.2f %.2f l S\n", 0.5, Margin, d.y, PageWidth-Margin, d.y)
+	d.Space(4)
+}
+
+// Bytes menghasilkan isi file PDF
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Urutan objek: katalog, daftar halaman, info, font, lalu pasangan halaman dan isi halaman
+	fontStart := 4
+	pageStart := fontStart + len(fontNames)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageStart+i*2)
+	}
+	fonts := make([]string, len(fontNames))
+	for i := range fontNames {
+		fonts[i] = fmt.Sprintf("/F%d %d 0 R", i+1, fontStart+i)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Golang_MNC) >>", escape(d.title)))
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, strings.Join(fonts, " "), pageStart+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// Fungsi bantu untuk mengubah teks menjadi string PDF dengan encoding WinAnsi
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 255:
+			b.WriteByte('?')
+		case r < 128:
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, "\\%03o", r)
+		}
+	}
+	return b.String()
+}
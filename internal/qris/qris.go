@@ -0,0 +1,368 @@
+// Package qris membuat dan membaca payload QR pembayaran merchant dengan format EMV merchant-presented
+// (seperti QRIS). Payload terdiri dari data object TLV: ID dua digit, panjang dua digit, lalu nilai,
+// dan diakhiri dengan checksum CRC16 pada tag 63.
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// ID data object EMV yang digunakan
+const (
+	TagPayloadFormat     = "00"
+	TagPointOfInitiation = "01"
+	TagMerchantAccount   = "26"
+	TagMerchantCategory  = "52"
+	TagCurrency          = "53"
+	TagAmount            = "54"
+	TagCountryCode       = "58"
+	TagMerchantName      = "59"
+	TagMerchantCity      = "60"
+	TagAdditionalData    = "62"
+	TagCRC               = "63"
+)
+
+// ID data object di dalam template merchant account information dan additional data
+const (
+	subTagGloballyUnique = "00"
+	subTagMerchantID     = "01"
+	subTagReferenceLabel = "05"
+)
+
+const (
+	payloadFormatVersion  = "01"
+	merchantAccountFirst  = 26
+	merchantAccountLast   = 51
+	crcFieldLength        = 4
+	maxMerchantCityLength = 15
+)
+
+// MaxMerchantNameLength adalah panjang maksimum nama merchant pada payload, nama yang lebih panjang dipotong
+const MaxMerchantNameLength = 25
+
+// Nilai point of initiation method: statis dapat dipakai berulang kali dengan jumlah diisi pelanggan,
+// dinamis hanya untuk satu pembayaran dengan jumlah dan referensi dari merchant
+const (
+	InitiationStatic  = "11"
+	InitiationDynamic = "12"
+)
+
+// Nilai default payload merchant
+const (
+	// GloballyUniqueID adalah identitas penyelenggara pada template merchant account information
+	GloballyUniqueID = "COM.GOLANGMNC.WALLET"
+	// DefaultCategoryCode adalah merchant category code (MCC) jika tidak diisi
+	DefaultCategoryCode = "5999"
+	// DefaultCountryCode adalah kode negara merchant jika tidak diisi
+	DefaultCountryCode = "ID"
+	// DefaultMerchantCity adalah kota merchant jika tidak diisi
+	DefaultMerchantCity = "JAKARTA"
+)
+
+// ErrInvalidChecksum dikembalikan ketika CRC payload tidak sesuai dengan isinya
+var ErrInvalidChecksum = errors.New("checksum QR tidak valid")
+
+// Kode numerik ISO 4217 untuk mata uang yang didukung
+var currencyCodes = map[string]string{
+	"IDR": "360",
+	"USD": "840",
+	"SGD": "702",
+}
+
+// Payload adalah isi QR pembayaran merchant. Amount kosong berarti QR statis yang jumlahnya diisi pelanggan,
+// QR dinamis selalu memiliki Amount dan Reference.
+type Payload struct {
+	MerchantID   string
+	MerchantName string
+	MerchantCity string
+	CategoryCode string
+	CountryCode  string
+	Currency     string
+	Amount       *money.Money
+	Reference    string
+}
+
+// Dynamic mengembalikan true jika payload adalah QR dinamis untuk satu pembayaran
+func (p *Payload) Dynamic() bool {
+	return p.Amount != nil
+}
+
+// Field adalah satu data object TLV
+type Field struct {
+	ID    string
+	Value string
+}
+
+// Encode mengodekan payload menjadi string QR beserta checksum CRC16
+func Encode(p Payload) (string, error) {
+	if p.MerchantID == "" {
+		return "", errors.New("ID merchant tidak boleh kosong")
+	}
+	if p.MerchantName == "" {
+		return "", errors.New("nama merchant tidak boleh kosong")
+	}
+	if p.MerchantCity == "" {
+		p.MerchantCity = DefaultMerchantCity
+	}
+	if p.CategoryCode == "" {
+		p.CategoryCode = DefaultCategoryCode
+	}
+	if p.CountryCode == "" {
+		p.CountryCode = DefaultCountryCode
+	}
+	currency := money.Zero(p.Currency).Currency()
+	currencyCode, ok := currencyCodes[currency]
+	if !ok {
+		return "", fmt.Errorf("mata uang %s tidak didukung QR", currency)
+	}
+
+	initiation := InitiationStatic
+	if p.Dynamic() {
+		initiation = InitiationDynamic
+		if !p.Amount.IsPositive() {
+			return "", errors.New("jumlah QR dinamis harus lebih dari nol")
+		}
+		if p.Amount.Currency() != currency {
+			return "", fmt.Errorf("jumlah QR harus dalam %s", currency)
+		}
+		if p.Reference == "" {
+			return "", errors.New("referensi QR dinamis tidak boleh kosong")
+		}
+	}
+
+	account, err := encodeFields(
+		Field{ID: subTagGloballyUnique, Value: GloballyUniqueID},
+		Field{ID: subTagMerchantID, Value: p.MerchantID},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	fields := []Field{
+		{ID: TagPayloadFormat, Value: payloadFormatVersion},
+		{ID: TagPointOfInitiation, Value: initiation},
+		{ID: TagMerchantAccount, Value: account},
+		{ID: TagMerchantCategory, Value: p.CategoryCode},
+		{ID: TagCurrency, Value: currencyCode},
+	}
+	if p.Dynamic() {
+		fields = append(fields, Field{ID: TagAmount, Value: p.Amount.Decimal()})
+	}
+	fields = append(fields,
+		Field{ID: TagCountryCode, Value: p.CountryCode},
+		Field{ID: TagMerchantName, Value: Truncate(p.MerchantName, MaxMerchantNameLength)},
+		Field{ID: TagMerchantCity, Value: Truncate(p.MerchantCity, maxMerchantCityLength)},
+	)
+	if p.Reference != "" {
+		additional, err := encodeFields(Field{ID: subTagReferenceLabel, Value: p.Reference})
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, Field{ID: TagAdditionalData, Value: additional})
+	}
+
+	data, err := encodeFields(fields...)
+	if err != nil {
+		return "", err
+	}
+
+	// CRC dihitung dari seluruh payload termasuk ID dan panjang tag CRC
+	data += TagCRC + fmt.Sprintf("%02d", crcFieldLength)
+	return data + fmt.Sprintf("%04X", CRC16([]byte(data))), nil
+}
+
+// Decode membaca string QR, memeriksa checksum CRC16, dan mengembalikan payload merchant
+func Decode(data string) (*Payload, error) {
+	fields, err := ParseFields(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0].ID != TagPayloadFormat || fields[0].Value != payloadFormatVersion {
+		return nil, errors.New("format payload QR tidak valid")
+	}
+
+	// Tag CRC harus menjadi data object terakhir
+	last := fields[len(fields)-1]
+	if last.ID != TagCRC || len(last.Value) != crcFieldLength {
+		return nil, errors.New("payload QR tidak memiliki checksum")
+	}
+	checksum, err := strconv.ParseUint(last.Value, 16, 16)
+	if err != nil {
+		return nil, ErrInvalidChecksum
+	}
+	if uint16(checksum) != CRC16([]byte(data[:len(data)-crcFieldLength])) {
+		return nil, ErrInvalidChecksum
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if _, ok := values[field.ID]; ok {
+			return nil, fmt.Errorf("tag %s tercantum lebih dari satu kali", field.ID)
+		}
+		values[field.ID] = field.Value
+	}
+
+	p := &Payload{
+		MerchantName: values[TagMerchantName],
+		MerchantCity: values[TagMerchantCity],
+		CategoryCode: values[TagMerchantCategory],
+		CountryCode:  values[TagCountryCode],
+	}
+
+	// Mencari template merchant account information milik penyelenggara ini
+	for id := merchantAccountFirst; id <= merchantAccountLast; id++ {
+		account, ok := values[strconv.Itoa(id)]
+		if !ok {
+			continue
+		}
+		subFields, err := ParseFields(account)
+		if err != nil {
+			return nil, fmt.Errorf("merchant account information tidak valid: %w", err)
+		}
+		sub := fieldMap(subFields)
+		if sub[subTagGloballyUnique] == GloballyUniqueID {
+			p.MerchantID = sub[subTagMerchantID]
+			break
+		}
+	}
+	if p.MerchantID == "" {
+		return nil, errors.New("QR bukan milik merchant yang terdaftar")
+	}
+
+	p.Currency, err = currencyFromCode(values[TagCurrency])
+	if err != nil {
+		return nil, err
+	}
+
+	if additional, ok := values[TagAdditionalData]; ok {
+		subFields, err := ParseFields(additional)
+		if err != nil {
+			return nil, fmt.Errorf("additional data tidak valid: %w", err)
+		}
+		p.Reference = fieldMap(subFields)[subTagReferenceLabel]
+	}
+
+	switch values[TagPointOfInitiation] {
+	case InitiationStatic:
+		if _, ok := values[TagAmount]; ok {
+			return nil, errors.New("QR statis tidak boleh memiliki jumlah")
+		}
+	case InitiationDynamic:
+		value, ok := values[TagAmount]
+		if !ok {
+			return nil, errors.New("QR dinamis harus memiliki jumlah")
+		}
+		amount, err := money.Parse(value, p.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("jumlah QR tidak valid: %w", err)
+		}
+		if !amount.IsPositive() {
+			return nil, errors.New("jumlah QR harus lebih dari nol")
+		}
+		if p.Reference == "" {
+			return nil, errors.New("QR dinamis harus memiliki referensi")
+		}
+		p.Amount = &amount
+	default:
+		return nil, errors.New("point of initiation QR tidak valid")
+	}
+
+	return p, nil
+}
+
+// ParseFields membaca rangkaian data object TLV
+func ParseFields(data string) ([]Field, error) {
+	var fields []Field
+	for i := 0; i < len(data); {
+		if len(data)-i < 4 {
+			return nil, fmt.Errorf("data object terpotong pada posisi %d", i)
+		}
+		id := data[i : i+2]
+		if !isDigits(id) || !isDigits(data[i+2:i+4]) {
+			return nil, fmt.Errorf("data object tidak valid pada posisi %d", i)
+		}
+		length, _ := strconv.Atoi(data[i+2 : i+4])
+		start := i + 4
+		if start+length > len(data) {
+			return nil, fmt.Errorf("panjang tag %s melebihi payload", id)
+		}
+		fields = append(fields, Field{ID: id, Value: data[start : start+length]})
+		i = start + length
+	}
+	return fields, nil
+}
+
+// CRC16 menghitung checksum CRC-16/CCITT-FALSE (polinomial 0x1021, nilai awal 0xFFFF) sesuai spesifikasi EMV QR
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// Fungsi bantu untuk mengodekan data object TLV secara berurutan
+func encodeFields(fields ...Field) (string, error) {
+	var b strings.Builder
+	for _, field := range fields {
+		if len(field.Value) > 99 {
+			return "", fmt.Errorf("nilai tag %s melebihi 99 karakter", field.ID)
+		}
+		fmt.Fprintf(&b, "%s%02d%s", field.ID, len(field.Value), field.Value)
+	}
+	return b.String(), nil
+}
+
+// Fungsi bantu untuk mengubah data object TLV menjadi map berdasarkan ID
+func fieldMap(fields []Field) map[string]string {
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		values[field.ID] = field.Value
+	}
+	return values
+}
+
+// Fungsi bantu untuk mendapatkan kode mata uang dari kode numerik ISO 4217
+func currencyFromCode(code string) (string, error) {
+	for currency, numeric := range currencyCodes {
+		if numeric == code {
+			return currency, nil
+		}
+	}
+	return "", fmt.Errorf("kode mata uang QR %q tidak didukung", code)
+}
+
+// Fungsi bantu untuk memeriksa apakah string hanya berisi angka
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Truncate memotong string sampai panjang maksimum dalam byte sesuai panjang pada data object TLV.
+// Pemotongan dilakukan pada batas karakter sehingga karakter multi-byte UTF-8 tidak terpotong di tengah.
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
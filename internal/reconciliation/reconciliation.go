@@ -0,0 +1,474 @@
+package reconciliation
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/ledger"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// Jenis-jenis pemeriksaan rekonsiliasi
+const (
+	CheckTransaction        = "transaction"
+	CheckWalletBalance      = "wallet_balance"
+	CheckWalletHeld         = "wallet_held"
+	CheckLedgerEntry        = "ledger_entry"
+	CheckLedgerWallet       = "ledger_wallet"
+	CheckLedgerHold         = "ledger_hold"
+	CheckMerchantPayable    = "merchant_payable"
+	CheckMerchantSettlement = "merchant_settlement"
+	CheckFeeRevenue         = "fee_revenue"
+	CheckPromotionExpense   = "promotion_expense"
+	CheckLoyaltyExpense     = "loyalty_expense"
+	CheckSettlement         = "settlement"
+)
+
+// Snapshot berisi seluruh data tersimpan yang dicocokkan oleh rekonsiliasi
+type Snapshot struct {
+	Transactions []models.Transaction
+	Wallets      []models.Wallet
+	Settlements  []models.SettlementBatch
+	Ledger       *ledger.Ledger
+}
+
+// Discrepancy adalah satu selisih antara nilai yang dihitung ulang dari riwayat transaksi dan nilai yang tersimpan
+type Discrepancy struct {
+	Check    string `json:"check"`
+	Subject  string `json:"subject"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// Report adalah hasil rekonsiliasi
+type Report struct {
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Transactions  int           `json:"transactions"`
+	Wallets       int           `json:"wallets"`
+	LedgerEntries int           `json:"ledger_entries"`
+	Settlements   int           `json:"settlements"`
+	Checks        int           `json:"checks"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// OK mengembalikan true jika tidak ada selisih
+func (r *Report) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// WriteText menuliskan laporan rekonsiliasi dalam bentuk teks
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Laporan rekonsiliasi %s\n", r.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Transaksi: %d, wallet: %d, jurnal: %d, settlement: %d, pemeriksaan: %d\n",
+		r.Transactions, r.Wallets, r.LedgerEntries, r.Settlements, r.Checks)
+
+	if r.OK() {
+		b.WriteString("OK: tidak ada selisih\n")
+	} else {
+		fmt.Fprintf(&b, "SELISIH: %d\n", len(r.Discrepancies))
+		for _, d := range r.Discrepancies {
+			fmt.Fprintf(&b, "- [%s] %s", d.Check, d.Subject)
+			if d.Expected != "" || d.Actual != "" {
+				fmt.Fprintf(&b, ": seharusnya %s, tercatat %s", d.Expected, d.Actual)
+			}
+			if d.Detail != "" {
+				fmt.Fprintf(&b, " (%s)", d.Detail)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// LoadSnapshot membaca data transaksi, wallet, settlement, dan buku besar dari direktori json
+func LoadSnapshot(dir string) (*Snapshot, error) {
+	transactions, err := repository.NewTransactionRepository(filepath.Join(dir, "transactions.json")).GetAllTransactions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transactions: %v", err)
+	}
+
+	walletRepo, err := repository.NewInMemoryWalletRepository(filepath.Join(dir, "wallets.json"))
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := walletRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	settlementRepo, err := repository.NewInMemorySettlementRepository(filepath.Join(dir, "settlements.json"))
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := settlementRepo.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := ledger.New(ledger.NewFileStore(filepath.Join(dir, "ledger.json")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ledger: %v", err)
+	}
+
+	return &Snapshot{
+		Transactions: transactions,
+		Wallets:      wallets,
+		Settlements:  settlements,
+		Ledger:       book,
+	}, nil
+}
+
+// Reconcile menghitung ulang saldo wallet, saldo yang ditahan, utang merchant, dan pendapatan biaya
+// dari riwayat transaksi, lalu mencocokkannya dengan wallet, batch settlement, dan buku besar yang tersimpan
+func Reconcile(snapshot *Snapshot, now time.Time) *Report {
+	r := &reconciler{
+		report: &Report{
+			GeneratedAt:   now,
+			Transactions:  len(snapshot.Transactions),
+			Wallets:       len(snapshot.Wallets),
+			Settlements:   len(snapshot.Settlements),
+			Discrepancies: make([]Discrepancy, 0),
+		},
+		balances: make(map[string]money.Money),
+		held:     make(map[string]money.Money),
+		accounts: make(map[string]money.Money),
+	}
+
+	r.replayTransactions(snapshot.Transactions)
+	r.checkSettlements(snapshot.Transactions, snapshot.Settlements)
+	r.checkWallets(snapshot.Wallets)
+	if snapshot.Ledger != nil {
+		r.checkLedger(snapshot.Ledger)
+	}
+
+	return r.report
+}
+
+// reconciler menyimpan nilai yang dihitung ulang dari riwayat transaksi
+type reconciler struct {
+	report *Report
+
+	// balances dan held adalah saldo dan saldo yang ditahan per pelanggan
+	balances map[string]money.Money
+	held     map[string]money.Money
+	// accounts adalah saldo akun buku besar yang seharusnya, dengan kunci ID akun
+	accounts map[string]money.Money
+}
+
+// Fungsi bantu untuk menghitung ulang saldo dari setiap transaksi yang berhasil
+func (r *reconciler) replayTransactions(transactions []models.Transaction) {
+	seen := make(map[string]bool, len(transactions))
+	for i := range transactions {
+		transaction := &transactions[i]
+		r.report.Checks++
+		if seen[transaction.ID] {
+			r.addDiscrepancy(Discrepancy{Check: CheckTransaction, Subject: "transaksi " + transaction.ID, Detail: "ID transaksi duplikat"})
+			continue
+		}
+		seen[transaction.ID] = true
+
+		status := transactionStatus(transaction)
+		switch transaction.Type {
+		case models.TransactionTypeTopUp, models.TransactionTypeTransferIn:
+			if status == models.TransactionStatusCaptured {
+				r.add(r.balances, transaction.CustomerID, transaction.Amount, transaction)
+			}
+		case models.TransactionTypeTransferOut:
+			if status == models.TransactionStatusCaptured {
+				r.add(r.balances, transaction.CustomerID, transaction.Amount.Neg(), transaction)
+			}
+		case models.TransactionTypeRefund:
+			if status == models.TransactionStatusCaptured {
+				r.add(r.balances, transaction.CustomerID, transaction.Amount, transaction)
+				r.addMerchant(transaction, true)
+			}
+		case models.TransactionTypeCashback:
+			if status == models.TransactionStatusCaptured {
+				r.add(r.balances, transaction.CustomerID, transaction.Amount, transaction)
+				r.add(r.accounts, ledger.PromotionExpense(transaction.Amount.Currency()).ID, transaction.Amount, transaction)
+			}
+		case models.TransactionTypeCashbackReversal:
+			if status == models.TransactionStatusCaptured {
+				r.add(r.balances, transaction.CustomerID, transaction.Amount.Neg(), transaction)
+				r.add(r.accounts, ledger.PromotionExpense(transaction.Amount.Currency()).ID, transaction.Amount.Neg(), transaction)
+			}
+		case models.TransactionTypePointsRedemption:
+			if status == models.TransactionStatusCaptured {
+				r.add(r.balances, transaction.CustomerID, transaction.Amount, transaction)
+				r.add(r.accounts, ledger.LoyaltyExpense(transaction.Amount.Currency()).ID, transaction.Amount, transaction)
+			}
+		case models.TransactionTypePointsRedemptionReversal:
+			if status == models.TransactionStatusCaptured {
+				r.add(r.balances, transaction.CustomerID, transaction.Amount.Neg(), transaction)
+				r.add(r.accounts, ledger.LoyaltyExpense(transaction.Amount.Currency()).ID, transaction.Amount.Neg(), transaction)
+			}
+		case "", models.TransactionTypePayment:
+			switch status {
+			case models.TransactionStatusAuthorized:
+				hold := transaction.Amount
+				if transaction.HoldAmount != nil {
+					hold = *transaction.HoldAmount
+				}
+				r.add(r.held, transaction.CustomerID, hold, transaction)
+			case models.TransactionStatusCaptured, models.TransactionStatusRefunded:
+				r.add(r.balances, transaction.CustomerID, transaction.Amount.Neg(), transaction)
+				r.addMerchant(transaction, false)
+			}
+		default:
+			r.addDiscrepancy(Discrepancy{Check: CheckTransaction, Subject: "transaksi " + transaction.ID, Detail: "jenis transaksi tidak dikenal: " + transaction.Type})
+		}
+	}
+}
+
+// Fungsi bantu untuk menambahkan jumlah bersih ke utang merchant dan biaya ke pendapatan biaya.
+// Refund mengurangi keduanya.
+func (r *reconciler) addMerchant(transaction *models.Transaction, refund bool) {
+	net, fee := transaction.Net, transaction.Fee
+	if net.IsZero() && fee.IsZero() {
+		// Transaksi lama yang belum memiliki rincian biaya
+		net = transaction.Amount
+		if transaction.OriginalAmount != nil {
+			net = *transaction.OriginalAmount
+		}
+	}
+	if refund {
+		net, fee = net.Neg(), fee.Neg()
+	}
+
+	r.add(r.accounts, ledger.MerchantPayable(transaction.MerchantID, net.Currency()).ID, net, transaction)
+	if !fee.IsZero() {
+		r.add(r.accounts, ledger.FeeRevenue(fee.Currency()).ID, fee, transaction)
+	}
+}
+
+// Fungsi bantu untuk mencocokkan batch settlement dengan transaksi yang ditandainya.
+// Batch memindahkan jumlah bersihnya dari utang merchant ke utang settlement sampai dibayarkan.
+func (r *reconciler) checkSettlements(transactions []models.Transaction, batches []models.SettlementBatch) {
+	byID := make(map[string]*models.Transaction, len(transactions))
+	for i := range transactions {
+		byID[transactions[i].ID] = &transactions[i]
+	}
+
+	known := make(map[string]bool, len(batches))
+	for i := range batches {
+		batch := &batches[i]
+		known[batch.ID] = true
+		r.report.Checks++
+
+		net := money.Zero(batch.Currency)
+		for _, id := range batch.TransactionIDs {
+			transaction, ok := byID[id]
+			if !ok {
+				r.addDiscrepancy(Discrepancy{Check: CheckSettlement, Subject: "settlement " + batch.ID, Detail: "transaksi " + id + " tidak ditemukan"})
+				continue
+			}
+			if transaction.SettlementID != batch.ID {
+				r.addDiscrepancy(Discrepancy{Check: CheckSettlement, Subject: "settlement " + batch.ID, Detail: fmt.Sprintf("transaksi %s ditandai dengan settlement %q", id, transaction.SettlementID)})
+			}
+
+			amount := transaction.Net
+			if amount.IsZero() && transaction.Fee.IsZero() {
+				amount = transaction.Amount
+				if transaction.OriginalAmount != nil {
+					amount = *transaction.OriginalAmount
+				}
+			}
+			if transaction.Type == models.TransactionTypeRefund {
+				amount = amount.Neg()
+			}
+			sum, err := net.Add(amount)
+			if err != nil {
+				r.addDiscrepancy(Discrepancy{Check: CheckSettlement, Subject: "settlement " + batch.ID, Detail: err.Error()})
+				continue
+			}
+			net = sum
+		}
+		r.compare(CheckSettlement, "settlement "+batch.ID+" net", net, batch.Net)
+
+		r.add(r.accounts, ledger.MerchantPayable(batch.MerchantID, batch.Currency).ID, batch.Net.Neg(), nil)
+		if batch.Status != models.SettlementStatusPaid {
+			r.add(r.accounts, ledger.MerchantSettlement(batch.MerchantID, batch.Currency).ID, batch.Net, nil)
+		}
+	}
+
+	for i := range transactions {
+		if id := transactions[i].SettlementID; id != "" && !known[id] {
+			r.addDiscrepancy(Discrepancy{Check: CheckSettlement, Subject: "transaksi " + transactions[i].ID, Detail: "settlement " + id + " tidak ditemukan"})
+		}
+	}
+}
+
+// Fungsi bantu untuk mencocokkan saldo wallet tersimpan dengan saldo yang dihitung ulang
+func (r *reconciler) checkWallets(wallets []models.Wallet) {
+	stored := make(map[string]bool, len(wallets))
+	for _, wallet := range wallets {
+		stored[wallet.CustomerID] = true
+		subject := "pelanggan " + wallet.CustomerID
+		r.compare(CheckWalletBalance, subject, r.expected(r.balances, wallet.CustomerID, wallet.Currency), wallet.Balance)
+		r.compare(CheckWalletHeld, subject, r.expected(r.held, wallet.CustomerID, wallet.Currency), wallet.Held)
+	}
+
+	// Pelanggan yang memiliki riwayat saldo namun tidak memiliki wallet tersimpan
+	for _, customerID := range sortedKeys(r.balances, r.held) {
+		if stored[customerID] {
+			continue
+		}
+		subject := "pelanggan " + customerID
+		balance := r.expected(r.balances, customerID, "")
+		held := r.expected(r.held, customerID, balance.Currency())
+		r.compare(CheckWalletBalance, subject, balance, money.Zero(balance.Currency()))
+		r.compare(CheckWalletHeld, subject, held, money.Zero(held.Currency()))
+	}
+}
+
+// Fungsi bantu untuk memeriksa setiap jurnal seimbang dan mencocokkan saldo akun buku besar.
+// Akun wallet pelanggan seharusnya berisi saldo tersedia (saldo dikurangi saldo yang ditahan).
+func (r *reconciler) checkLedger(book *ledger.Ledger) {
+	entries := book.Entries("")
+	r.report.LedgerEntries = len(entries)
+	for i := range entries {
+		r.report.Checks++
+		err := entries[i].Validate()
+		if err != nil {
+			r.addDiscrepancy(Discrepancy{Check: CheckLedgerEntry, Subject: "jurnal " + entries[i].ID, Detail: err.Error()})
+		}
+	}
+
+	for _, customerID := range sortedKeys(r.balances, r.held) {
+		balance := r.expected(r.balances, customerID, "")
+		held := r.expected(r.held, customerID, balance.Currency())
+		available, err := balance.Sub(held)
+		if err != nil {
+			r.addDiscrepancy(Discrepancy{Check: CheckLedgerWallet, Subject: "pelanggan " + customerID, Detail: err.Error()})
+			continue
+		}
+		r.accounts[ledger.CustomerWallet(customerID, available.Currency()).ID] = available
+		if !held.IsZero() {
+			r.accounts[ledger.CustomerHold(customerID, held.Currency()).ID] = held
+		}
+	}
+
+	// Akun yang sudah dibuka di buku besar namun tidak memiliki riwayat transaksi seharusnya bersaldo nol
+	for _, account := range book.Accounts() {
+		if _, ok := r.accounts[account.ID]; !ok && ledgerCheck(account.ID) != "" {
+			r.accounts[account.ID] = money.Zero(account.Currency)
+		}
+	}
+
+	for _, accountID := range sortedKeys(r.accounts) {
+		check := ledgerCheck(accountID)
+		expected := r.accounts[accountID]
+		actual, err := book.Balance(accountID)
+		if err != nil {
+			// Akun yang belum pernah diposting dianggap bersaldo nol
+			actual = money.Zero(expected.Currency())
+		}
+		r.compare(check, "akun "+accountID, expected, actual)
+	}
+}
+
+// Fungsi bantu untuk membandingkan nilai yang seharusnya dengan nilai yang tercatat
+func (r *reconciler) compare(check string, subject string, expected money.Money, actual money.Money) {
+	r.report.Checks++
+	cmp, err := expected.Cmp(actual)
+	if err == nil && cmp == 0 {
+		return
+	}
+
+	discrepancy := Discrepancy{
+		Check:    check,
+		Subject:  subject,
+		Expected: expected.String(),
+		Actual:   actual.String(),
+	}
+	if err != nil {
+		discrepancy.Detail = err.Error()
+	} else {
+		difference, _ := actual.Sub(expected)
+		discrepancy.Detail = "selisih " + difference.String()
+	}
+	r.addDiscrepancy(discrepancy)
+}
+
+// Fungsi bantu untuk menambahkan jumlah ke total dengan kunci tertentu
+func (r *reconciler) add(totals map[string]money.Money, key string, amount money.Money, transaction *models.Transaction) {
+	total, err := totals[key].Add(amount)
+	if err != nil {
+		subject := key
+		if transaction != nil {
+			subject = "transaksi " + transaction.ID
+		}
+		r.addDiscrepancy(Discrepancy{Check: CheckTransaction, Subject: subject, Detail: err.Error()})
+		return
+	}
+	totals[key] = total
+}
+
+// Fungsi bantu untuk mengambil total yang seharusnya, total kosong bernilai nol dalam mata uang yang diberikan
+func (r *reconciler) expected(totals map[string]money.Money, key string, currency string) money.Money {
+	total, ok := totals[key]
+	if !ok || (total.IsZero() && currency != "") {
+		return money.Zero(currency)
+	}
+	return total
+}
+
+func (r *reconciler) addDiscrepancy(discrepancy Discrepancy) {
+	r.report.Discrepancies = append(r.report.Discrepancies, discrepancy)
+}
+
+// Fungsi bantu untuk menentukan jenis pemeriksaan akun buku besar. Akun kas, sumber dana, dan posisi valas
+// tidak dapat dihitung ulang dari riwayat transaksi sehingga tidak diperiksa.
+func ledgerCheck(accountID string) string {
+	switch {
+	case strings.HasPrefix(accountID, "customer:") && strings.HasSuffix(accountID, ":wallet"):
+		return CheckLedgerWallet
+	case strings.HasPrefix(accountID, "customer:") && strings.HasSuffix(accountID, ":hold"):
+		return CheckLedgerHold
+	case strings.HasPrefix(accountID, "merchant:") && strings.HasSuffix(accountID, ":payable"):
+		return CheckMerchantPayable
+	case strings.HasPrefix(accountID, "merchant:") && strings.HasSuffix(accountID, ":settlement"):
+		return CheckMerchantSettlement
+	case strings.HasPrefix(accountID, "revenue:fees:"):
+		return CheckFeeRevenue
+	case strings.HasPrefix(accountID, "expense:promotions:"):
+		return CheckPromotionExpense
+	case strings.HasPrefix(accountID, "expense:loyalty:"):
+		return CheckLoyaltyExpense
+	}
+	return ""
+}
+
+// Fungsi bantu untuk mengambil status transaksi, transaksi lama tanpa status dianggap sudah captured
+func transactionStatus(transaction *models.Transaction) string {
+	if transaction.Status == "" {
+		return models.TransactionStatusCaptured
+	}
+	return transaction.Status
+}
+
+// Fungsi bantu untuk mengambil gabungan kunci beberapa map secara terurut
+func sortedKeys(maps ...map[string]money.Money) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
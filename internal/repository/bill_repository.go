@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrBillNotFound dikembalikan ketika split bill tidak ditemukan
+var ErrBillNotFound = errors.New("bill not found")
+
+// Mendefinisikan interface BillRepository yang menyediakan method-method
+type BillRepository interface {
+	Save(bill *models.Bill) error
+	GetByID(billID string) (*models.Bill, error)
+	GetByCustomerID(customerID string) ([]models.Bill, error)
+	GetByStatus(status string) ([]models.Bill, error)
+}
+
+// InMemoryBillRepository menyimpan split bill di memori dan menuliskannya ke file JSON
+type InMemoryBillRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	bills    []models.Bill
+}
+
+// NewInMemoryBillRepository membuat instance baru dari InMemoryBillRepository
+func NewInMemoryBillRepository(filePath string) (*InMemoryBillRepository, error) {
+	// Membaca file yang berisi data split bill, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read bill data: %v", err)
+	}
+
+	var bills []models.Bill
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &bills)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bill data: %v", err)
+		}
+	}
+
+	return &InMemoryBillRepository{
+		filePath: filePath,
+		bills:    bills,
+	}, nil
+}
+
+// Save menyimpan split bill baru atau memperbarui yang sudah ada
+func (r *InMemoryBillRepository) Save(bill *models.Bill) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.bills
+	updated := false
+	r.bills = make([]models.Bill, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID == bill.ID {
+			existing = copyBill(bill)
+			updated = true
+		}
+		r.bills = append(r.bills, existing)
+	}
+	if !updated {
+		r.bills = append(r.bills, copyBill(bill))
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.bills = previous
+		return err
+	}
+	return nil
+}
+
+// GetByID mengambil salinan split bill berdasarkan ID
+func (r *InMemoryBillRepository) GetByID(billID string) (*models.Bill, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, bill := range r.bills {
+		if bill.ID == billID {
+			result := copyBill(&bill)
+			return &result, nil
+		}
+	}
+
+	return nil, ErrBillNotFound
+}
+
+// GetByCustomerID mengambil split bill yang dibuat atau diikuti pelanggan diurutkan dari yang terbaru
+func (r *InMemoryBillRepository) GetByCustomerID(customerID string) ([]models.Bill, error) {
+	return r.filter(func(bill *models.Bill) bool {
+		if bill.CreatorID == customerID {
+			return true
+		}
+		for _, share := range bill.Shares {
+			if share.CustomerID == customerID {
+				return true
+			}
+		}
+		return false
+	}, func(a, b *models.Bill) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}), nil
+}
+
+// GetByStatus mengambil split bill dengan status tertentu diurutkan dari yang paling lama
+func (r *InMemoryBillRepository) GetByStatus(status string) ([]models.Bill, error) {
+	return r.filter(func(bill *models.Bill) bool {
+		return bill.Status == status
+	}, func(a, b *models.Bill) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+// Fungsi bantu untuk mengambil salinan split bill yang memenuhi kondisi secara terurut
+func (r *InMemoryBillRepository) filter(match func(*models.Bill) bool, less func(a, b *models.Bill) bool) []models.Bill {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bills := make([]models.Bill, 0)
+	for i := range r.bills {
+		if match(&r.bills[i]) {
+			bills = append(bills, copyBill(&r.bills[i]))
+		}
+	}
+
+	sort.SliceStable(bills, func(i, j int) bool {
+		return less(&bills[i], &bills[j])
+	})
+	return bills
+}
+
+// Fungsi bantu untuk menyalin split bill beserta bagian pesertanya agar perubahan di luar repository
+// tidak mengubah data yang tersimpan
+func copyBill(bill *models.Bill) models.Bill {
+	result := *bill
+	result.Shares = append([]models.BillShare(nil), bill.Shares...)
+	return result
+}
+
+// Fungsi bantu untuk menyimpan data split bill ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryBillRepository) saveToFile() error {
+	data, err := json.Marshal(r.bills)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bill data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write bill data to file: %v", err)
+	}
+
+	return nil
+}
@@ -20,6 +20,8 @@ type BlacklistToken struct {
 type CustomerRepository interface {
 	GetByUsername(username string) (*models.Customer, error)
 	GetByID(customerID string) (*models.Customer, error)
+	GetByPhone(phone int) (*models.Customer, error)
+	GetAll() ([]models.Customer, error)
 	SaveCustomer(customer *models.Customer) error
 	SaveToFile() error
 	SaveToken(username, token string) error
@@ -85,6 +87,25 @@ func (r *InMemoryCustomerRepository) GetByID(customerID string) (*models.Custome
 	return nil, fmt.Errorf("customer not found")
 }
 
+// Implementasi method GetByPhone yang mengambil data pelanggan berdasarkan nomor telepon
+func (r *InMemoryCustomerRepository) GetByPhone(phone int) (*models.Customer, error) {
+	for _, c := range r.customers {
+		if c.Phone == phone {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("customer not found")
+}
+
+// Implementasi method GetAll yang mengambil salinan data seluruh pelanggan
+func (r *InMemoryCustomerRepository) GetAll() ([]models.Customer, error) {
+	customers := make([]models.Customer, 0, len(r.customers))
+	for _, c := range r.customers {
+		customers = append(customers, *c)
+	}
+	return customers, nil
+}
+
 // Implementasi method SaveCustomer untuk menyimpan data pelanggan baru
 func (r *InMemoryCustomerRepository) SaveCustomer(customer *models.Customer) error {
 	r.customerCounter++ // Increment customer counter
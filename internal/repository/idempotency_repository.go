@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// Mendefinisikan interface IdempotencyRepository yang menyediakan method-method
+type IdempotencyRepository interface {
+	Begin(userID, key, requestHash string, lease time.Duration) (*models.IdempotencyKey, bool, error)
+	Complete(userID, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error
+	Delete(userID, key string) error
+	PurgeExpired(now time.Time) (int, error)
+}
+
+// InMemoryIdempotencyRepository menyimpan idempotency key di memori dan menuliskannya ke file JSON
+type InMemoryIdempotencyRepository struct {
+	mu       sync.Mutex
+	filePath string
+	keys     []*models.IdempotencyKey
+}
+
+// NewInMemoryIdempotencyRepository membuat instance baru dari InMemoryIdempotencyRepository
+func NewInMemoryIdempotencyRepository(filePath string) (*InMemoryIdempotencyRepository, error) {
+	// Membaca file yang berisi data idempotency key, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read idempotency key data: %v", err)
+	}
+
+	var keys []*models.IdempotencyKey
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &keys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotency key data: %v", err)
+		}
+	}
+
+	return &InMemoryIdempotencyRepository{
+		filePath: filePath,
+		keys:     keys,
+	}, nil
+}
+
+// Begin mencatat key baru untuk permintaan yang sedang diproses, key tersebut hanya berlaku selama lease
+// sampai respons disimpan dengan Complete. Jika key milik pengguna yang sama masih berlaku maka key tersebut
+// dikembalikan dengan nilai created false. Key yang sudah kedaluwarsa dibersihkan setiap kali key baru dicatat.
+func (r *InMemoryIdempotencyRepository) Begin(userID, key, requestHash string, lease time.Duration) (*models.IdempotencyKey, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if existing := r.find(userID, key); existing != nil && now.Before(existing.ExpiresAt) {
+		record := *existing
+		return &record, false, nil
+	}
+
+	r.removeExpired(now)
+	record := &models.IdempotencyKey{
+		Key:         key,
+		UserID:      userID,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lease),
+	}
+	r.keys = append(r.keys, record)
+
+	err := r.saveToFile()
+	if err != nil {
+		r.keys = r.keys[:len(r.keys)-1]
+		return nil, false, err
+	}
+
+	result := *record
+	return &result, true, nil
+}
+
+// Complete menyimpan respons akhir dari permintaan dengan key tersebut selama ttl
+func (r *InMemoryIdempotencyRepository) Complete(userID, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := r.find(userID, key)
+	if record == nil {
+		return fmt.Errorf("idempotency key not found")
+	}
+
+	record.Completed = true
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Body = string(body)
+	record.ExpiresAt = time.Now().Add(ttl)
+
+	return r.saveToFile()
+}
+
+// Delete menghapus key sehingga permintaan dengan key tersebut dapat diproses ulang
+func (r *InMemoryIdempotencyRepository) Delete(userID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, record := range r.keys {
+		if record.UserID == userID && record.Key == key {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return r.saveToFile()
+		}
+	}
+
+	return nil
+}
+
+// PurgeExpired menghapus semua key yang sudah melewati masa berlakunya
+func (r *InMemoryIdempotencyRepository) PurgeExpired(now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := r.removeExpired(now)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	return removed, r.saveToFile()
+}
+
+// Fungsi bantu untuk mencari key milik pengguna. Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryIdempotencyRepository) find(userID, key string) *models.IdempotencyKey {
+	for _, record := range r.keys {
+		if record.UserID == userID && record.Key == key {
+			return record
+		}
+	}
+	return nil
+}
+
+// Fungsi bantu untuk membuang key yang kedaluwarsa. Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryIdempotencyRepository) removeExpired(now time.Time) int {
+	kept := r.keys[:0]
+	for _, record := range r.keys {
+		if now.Before(record.ExpiresAt) {
+			kept = append(kept, record)
+		}
+	}
+
+	removed := len(r.keys) - len(kept)
+	r.keys = kept
+	return removed
+}
+
+// Fungsi bantu untuk menyimpan data idempotency key ke file. Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryIdempotencyRepository) saveToFile() error {
+	data, err := json.Marshal(r.keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency key data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write idempotency key data to file: %v", err)
+	}
+
+	return nil
+}
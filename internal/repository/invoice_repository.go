@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrInvoiceNotFound dikembalikan ketika invoice tidak ditemukan
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// Mendefinisikan interface InvoiceRepository yang menyediakan method-method
+type InvoiceRepository interface {
+	Save(invoice *models.Invoice) error
+	GetByID(invoiceID string) (*models.Invoice, error)
+	GetByMerchantID(merchantID string) ([]models.Invoice, error)
+	GetByCustomerID(customerID string) ([]models.Invoice, error)
+	GetByStatus(status string) ([]models.Invoice, error)
+}
+
+// InMemoryInvoiceRepository menyimpan invoice di memori dan menuliskannya ke file JSON
+type InMemoryInvoiceRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	invoices []models.Invoice
+}
+
+// NewInMemoryInvoiceRepository membuat instance baru dari InMemoryInvoiceRepository
+func NewInMemoryInvoiceRepository(filePath string) (*InMemoryInvoiceRepository, error) {
+	// Membaca file yang berisi data invoice, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read invoice data: %v", err)
+	}
+
+	var invoices []models.Invoice
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &invoices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal invoice data: %v", err)
+		}
+	}
+
+	return &InMemoryInvoiceRepository{
+		filePath: filePath,
+		invoices: invoices,
+	}, nil
+}
+
+// Save menyimpan invoice baru atau memperbarui yang sudah ada
+func (r *InMemoryInvoiceRepository) Save(invoice *models.Invoice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.invoices
+	updated := false
+	r.invoices = make([]models.Invoice, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID == invoice.ID {
+			existing = copyInvoice(invoice)
+			updated = true
+		}
+		r.invoices = append(r.invoices, existing)
+	}
+	if !updated {
+		r.invoices = append(r.invoices, copyInvoice(invoice))
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.invoices = previous
+		return err
+	}
+	return nil
+}
+
+// GetByID mengambil salinan invoice berdasarkan ID
+func (r *InMemoryInvoiceRepository) GetByID(invoiceID string) (*models.Invoice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invoice := range r.invoices {
+		if invoice.ID == invoiceID {
+			result := copyInvoice(&invoice)
+			return &result, nil
+		}
+	}
+
+	return nil, ErrInvoiceNotFound
+}
+
+// GetByMerchantID mengambil invoice milik merchant diurutkan dari yang terbaru
+func (r *InMemoryInvoiceRepository) GetByMerchantID(merchantID string) ([]models.Invoice, error) {
+	return r.filter(func(invoice *models.Invoice) bool {
+		return invoice.MerchantID == merchantID
+	}, func(a, b *models.Invoice) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}), nil
+}
+
+// GetByCustomerID mengambil invoice yang ditujukan untuk atau dibayar oleh pelanggan diurutkan dari yang terbaru
+func (r *InMemoryInvoiceRepository) GetByCustomerID(customerID string) ([]models.Invoice, error) {
+	return r.filter(func(invoice *models.Invoice) bool {
+		return invoice.CustomerID == customerID || invoice.PaidBy == customerID
+	}, func(a, b *models.Invoice) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}), nil
+}
+
+// GetByStatus mengambil invoice dengan status tertentu diurutkan dari yang paling lama
+func (r *InMemoryInvoiceRepository) GetByStatus(status string) ([]models.Invoice, error) {
+	return r.filter(func(invoice *models.Invoice) bool {
+		return invoice.Status == status
+	}, func(a, b *models.Invoice) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+// Fungsi bantu untuk mengambil salinan invoice yang memenuhi kondisi secara terurut
+func (r *InMemoryInvoiceRepository) filter(match func(*models.Invoice) bool, less func(a, b *models.Invoice) bool) []models.Invoice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invoices := make([]models.Invoice, 0)
+	for i := range r.invoices {
+		if match(&r.invoices[i]) {
+			invoices = append(invoices, copyInvoice(&r.invoices[i]))
+		}
+	}
+
+	sort.SliceStable(invoices, func(i, j int) bool {
+		return less(&invoices[i], &invoices[j])
+	})
+	return invoices
+}
+
+// Fungsi bantu untuk menyalin invoice beserta baris tagihannya agar perubahan di luar repository
+// tidak mengubah data yang tersimpan
+func copyInvoice(invoice *models.Invoice) models.Invoice {
+	result := *invoice
+	result.Items = append([]models.InvoiceItem(nil), invoice.Items...)
+	return result
+}
+
+// Fungsi bantu untuk menyimpan data invoice ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryInvoiceRepository) saveToFile() error {
+	data, err := json.Marshal(r.invoices)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invoice data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write invoice data to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// Mendefinisikan interface LimitRepository yang menyediakan method-method
+type LimitRepository interface {
+	GetConfig() (*models.LimitConfig, error)
+	GetTierLimit(tier string) (*models.SpendingLimit, error)
+	GetCustomerLimit(customerID string) (*models.SpendingLimit, error)
+	SetTierLimit(tier string, limit models.SpendingLimit) error
+	SetCustomerLimit(customerID string, limit models.SpendingLimit) error
+	DeleteCustomerLimit(customerID string) error
+}
+
+// InMemoryLimitRepository menyimpan batas pengeluaran di memori dan menuliskannya ke file JSON
+type InMemoryLimitRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	config   models.LimitConfig
+}
+
+// NewInMemoryLimitRepository membuat instance baru dari InMemoryLimitRepository
+func NewInMemoryLimitRepository(filePath string) (*InMemoryLimitRepository, error) {
+	// Membaca file yang berisi data batas pengeluaran, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read limit data: %v", err)
+	}
+
+	var config models.LimitConfig
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal limit data: %v", err)
+		}
+	}
+	if config.Tiers == nil {
+		config.Tiers = make(map[string]models.SpendingLimit)
+	}
+	if config.Customers == nil {
+		config.Customers = make(map[string]models.SpendingLimit)
+	}
+
+	return &InMemoryLimitRepository{
+		filePath: filePath,
+		config:   config,
+	}, nil
+}
+
+// GetConfig mengambil salinan seluruh konfigurasi batas pengeluaran
+func (r *InMemoryLimitRepository) GetConfig() (*models.LimitConfig, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config := models.LimitConfig{
+		Tiers:     make(map[string]models.SpendingLimit, len(r.config.Tiers)),
+		Customers: make(map[string]models.SpendingLimit, len(r.config.Customers)),
+	}
+	for tier, limit := range r.config.Tiers {
+		config.Tiers[tier] = limit
+	}
+	for customerID, limit := range r.config.Customers {
+		config.Customers[customerID] = limit
+	}
+	return &config, nil
+}
+
+// GetTierLimit mengambil batas pengeluaran sebuah tier
+func (r *InMemoryLimitRepository) GetTierLimit(tier string) (*models.SpendingLimit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit, ok := r.config.Tiers[tier]
+	if !ok {
+		return nil, fmt.Errorf("tier limit not found")
+	}
+	return &limit, nil
+}
+
+// GetCustomerLimit mengambil batas pengeluaran khusus seorang pelanggan
+func (r *InMemoryLimitRepository) GetCustomerLimit(customerID string) (*models.SpendingLimit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit, ok := r.config.Customers[customerID]
+	if !ok {
+		return nil, fmt.Errorf("customer limit not found")
+	}
+	return &limit, nil
+}
+
+// SetTierLimit menyimpan batas pengeluaran sebuah tier
+func (r *InMemoryLimitRepository) SetTierLimit(tier string, limit models.SpendingLimit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, existed := r.config.Tiers[tier]
+	limit.UpdatedAt = time.Now()
+	r.config.Tiers[tier] = limit
+
+	err := r.saveToFile()
+	if err != nil {
+		if existed {
+			r.config.Tiers[tier] = previous
+		} else {
+			delete(r.config.Tiers, tier)
+		}
+		return err
+	}
+	return nil
+}
+
+// SetCustomerLimit menyimpan batas pengeluaran khusus seorang pelanggan
+func (r *InMemoryLimitRepository) SetCustomerLimit(customerID string, limit models.SpendingLimit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, existed := r.config.Customers[customerID]
+	limit.UpdatedAt = time.Now()
+	r.config.Customers[customerID] = limit
+
+	err := r.saveToFile()
+	if err != nil {
+		if existed {
+			r.config.Customers[customerID] = previous
+		} else {
+			delete(r.config.Customers, customerID)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteCustomerLimit menghapus batas pengeluaran khusus pelanggan sehingga kembali mengikuti batas tier
+func (r *InMemoryLimitRepository) DeleteCustomerLimit(customerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, existed := r.config.Customers[customerID]
+	if !existed {
+		return fmt.Errorf("customer limit not found")
+	}
+	delete(r.config.Customers, customerID)
+
+	err := r.saveToFile()
+	if err != nil {
+		r.config.Customers[customerID] = previous
+		return err
+	}
+	return nil
+}
+
+// Fungsi bantu untuk menyimpan data batas pengeluaran ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryLimitRepository) saveToFile() error {
+	data, err := json.Marshal(r.config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal limit data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write limit data to file: %v", err)
+	}
+
+	return nil
+}
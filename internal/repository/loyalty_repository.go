@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// Mendefinisikan interface LoyaltyRepository yang menyediakan method-method
+type LoyaltyRepository interface {
+	GetConfig() (*models.LoyaltyConfig, error)
+	SetMerchantRate(merchantID string, rate models.EarnRate) error
+	DeleteMerchantRate(merchantID string) error
+}
+
+// InMemoryLoyaltyRepository menyimpan pengaturan poin loyalitas di memori dan menuliskannya ke file JSON
+type InMemoryLoyaltyRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	config   models.LoyaltyConfig
+}
+
+// NewInMemoryLoyaltyRepository membuat instance baru dari InMemoryLoyaltyRepository
+func NewInMemoryLoyaltyRepository(filePath string) (*InMemoryLoyaltyRepository, error) {
+	// Membaca file yang berisi pengaturan poin loyalitas, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read loyalty data: %v", err)
+	}
+
+	var config models.LoyaltyConfig
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loyalty data: %v", err)
+		}
+	}
+	if config.Merchants == nil {
+		config.Merchants = make(map[string]models.EarnRate)
+	}
+
+	return &InMemoryLoyaltyRepository{
+		filePath: filePath,
+		config:   config,
+	}, nil
+}
+
+// GetConfig mengambil salinan seluruh pengaturan poin loyalitas
+func (r *InMemoryLoyaltyRepository) GetConfig() (*models.LoyaltyConfig, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config := r.config
+	if r.config.Default != nil {
+		rate := *r.config.Default
+		config.Default = &rate
+	}
+	config.Merchants = make(map[string]models.EarnRate, len(r.config.Merchants))
+	for merchantID, rate := range r.config.Merchants {
+		config.Merchants[merchantID] = rate
+	}
+	return &config, nil
+}
+
+// SetMerchantRate menyimpan aturan perolehan poin khusus sebuah merchant
+func (r *InMemoryLoyaltyRepository) SetMerchantRate(merchantID string, rate models.EarnRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, existed := r.config.Merchants[merchantID]
+	rate.UpdatedAt = time.Now()
+	r.config.Merchants[merchantID] = rate
+
+	err := r.saveToFile()
+	if err != nil {
+		if existed {
+			r.config.Merchants[merchantID] = previous
+		} else {
+			delete(r.config.Merchants, merchantID)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteMerchantRate menghapus aturan perolehan poin khusus merchant sehingga kembali mengikuti aturan default
+func (r *InMemoryLoyaltyRepository) DeleteMerchantRate(merchantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, existed := r.config.Merchants[merchantID]
+	if !existed {
+		return fmt.Errorf("merchant earn rate not found")
+	}
+	delete(r.config.Merchants, merchantID)
+
+	err := r.saveToFile()
+	if err != nil {
+		r.config.Merchants[merchantID] = previous
+		return err
+	}
+	return nil
+}
+
+// Fungsi bantu untuk menyimpan pengaturan poin loyalitas ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryLoyaltyRepository) saveToFile() error {
+	data, err := json.Marshal(r.config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal loyalty data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write loyalty data to file: %v", err)
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +15,8 @@ import (
 type MerchantRepository interface {
 	GetByID(merchantID string) (*models.Merchant, error)
 	GetMerchantNameByID(merchantID string) (string, error)
+	GetByAPIKey(apiKey string) (*models.Merchant, error)
+	GetAll() ([]models.Merchant, error)
 }
 
 // Data merchant disimpan dalam slice of Merchant
@@ -79,3 +84,32 @@ func (r *InMemoryMerchantRepository) GetMerchantNameByID(merchantID string) (str
 	// Mengembalikan error jika merchant tidak ditemukan
 	return "", fmt.Errorf("merchant not found")
 }
+
+// GetAll mengambil salinan seluruh merchant
+func (r *InMemoryMerchantRepository) GetAll() ([]models.Merchant, error) {
+	merchants := make([]models.Merchant, 0, len(r.merchants))
+	for _, merchant := range r.merchants {
+		merchants = append(merchants, *merchant)
+	}
+	return merchants, nil
+}
+
+func (r *InMemoryMerchantRepository) GetByAPIKey(apiKey string) (*models.Merchant, error) {
+	// API key disimpan dalam bentuk hash SHA-256 sehingga yang dibandingkan adalah hash dari key yang diberikan
+	sum := sha256.Sum256([]byte(apiKey))
+	hash := hex.EncodeToString(sum[:])
+
+	for _, merchant := range r.merchants {
+		// Merchant tanpa API key tidak dapat mengakses API merchant
+		if merchant.APIKeyHash == "" {
+			continue
+		}
+		// Membandingkan hash dengan waktu konstan agar tidak membocorkan informasi melalui waktu respons
+		if subtle.ConstantTimeCompare([]byte(merchant.APIKeyHash), []byte(hash)) == 1 {
+			return merchant, nil
+		}
+	}
+
+	// Mengembalikan error jika tidak ada merchant dengan API key tersebut
+	return nil, fmt.Errorf("merchant not found")
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// Mendefinisikan interface PointsRepository yang menyediakan method-method
+type PointsRepository interface {
+	Save(entries ...*models.PointsEntry) error
+	GetByCustomerID(customerID string) ([]models.PointsEntry, error)
+	GetByTransactionID(transactionID string) ([]models.PointsEntry, error)
+	GetExpiring() ([]models.PointsEntry, error)
+}
+
+// InMemoryPointsRepository menyimpan buku poin loyalitas di memori dan menuliskannya ke file JSON
+type InMemoryPointsRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	entries  []models.PointsEntry
+}
+
+// NewInMemoryPointsRepository membuat instance baru dari InMemoryPointsRepository
+func NewInMemoryPointsRepository(filePath string) (*InMemoryPointsRepository, error) {
+	// Membaca file yang berisi data poin, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read points data: %v", err)
+	}
+
+	var entries []models.PointsEntry
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal points data: %v", err)
+		}
+	}
+
+	return &InMemoryPointsRepository{
+		filePath: filePath,
+		entries:  entries,
+	}, nil
+}
+
+// Save menyimpan beberapa catatan poin baru atau yang diperbarui secara bersamaan.
+// Jika gagal disimpan ke file maka tidak ada catatan yang berubah.
+func (r *InMemoryPointsRepository) Save(entries ...*models.PointsEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending := make(map[string]*models.PointsEntry, len(entries))
+	for _, entry := range entries {
+		pending[entry.ID] = entry
+	}
+
+	previous := r.entries
+	r.entries = make([]models.PointsEntry, 0, len(previous)+len(entries))
+	for _, existing := range previous {
+		if entry, ok := pending[existing.ID]; ok {
+			existing = copyPointsEntry(entry)
+			delete(pending, existing.ID)
+		}
+		r.entries = append(r.entries, existing)
+	}
+	for _, entry := range entries {
+		if _, ok := pending[entry.ID]; ok {
+			r.entries = append(r.entries, copyPointsEntry(entry))
+		}
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.entries = previous
+		return err
+	}
+	return nil
+}
+
+// GetByCustomerID mengambil catatan poin pelanggan diurutkan dari yang paling lama
+func (r *InMemoryPointsRepository) GetByCustomerID(customerID string) ([]models.PointsEntry, error) {
+	return r.filter(func(entry *models.PointsEntry) bool {
+		return entry.CustomerID == customerID
+	}), nil
+}
+
+// GetByTransactionID mengambil catatan poin yang terkait dengan sebuah transaksi diurutkan dari yang paling lama
+func (r *InMemoryPointsRepository) GetByTransactionID(transactionID string) ([]models.PointsEntry, error) {
+	return r.filter(func(entry *models.PointsEntry) bool {
+		return entry.TransactionID == transactionID
+	}), nil
+}
+
+// GetExpiring mengambil catatan poin yang masih memiliki sisa poin dan masa berlaku diurutkan dari yang paling lama
+func (r *InMemoryPointsRepository) GetExpiring() ([]models.PointsEntry, error) {
+	return r.filter(func(entry *models.PointsEntry) bool {
+		return entry.Remaining > 0 && entry.ExpiresAt != nil
+	}), nil
+}
+
+// Fungsi bantu untuk mengambil salinan catatan poin yang memenuhi kondisi diurutkan dari yang paling lama
+func (r *InMemoryPointsRepository) filter(match func(*models.PointsEntry) bool) []models.PointsEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]models.PointsEntry, 0)
+	for i := range r.entries {
+		if match(&r.entries[i]) {
+			entries = append(entries, copyPointsEntry(&r.entries[i]))
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries
+}
+
+// Fungsi bantu untuk menyalin catatan poin beserta rincian sisa poin yang dipakainya agar perubahan
+// di luar repository tidak mengubah data yang tersimpan
+func copyPointsEntry(entry *models.PointsEntry) models.PointsEntry {
+	result := *entry
+	result.Lots = append([]models.PointsLot(nil), entry.Lots...)
+	return result
+}
+
+// Fungsi bantu untuk menyimpan data poin ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryPointsRepository) saveToFile() error {
+	data, err := json.Marshal(r.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal points data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write points data to file: %v", err)
+	}
+
+	return nil
+}
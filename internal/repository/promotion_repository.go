@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrPromotionNotFound dikembalikan ketika promosi tidak ditemukan
+var ErrPromotionNotFound = errors.New("promotion not found")
+
+// Mendefinisikan interface PromotionRepository yang menyediakan method-method
+type PromotionRepository interface {
+	Save(promotion *models.Promotion) error
+	GetByID(promotionID string) (*models.Promotion, error)
+	GetByCode(code string) (*models.Promotion, error)
+	GetAll() ([]models.Promotion, error)
+}
+
+// InMemoryPromotionRepository menyimpan promosi di memori dan menuliskannya ke file JSON
+type InMemoryPromotionRepository struct {
+	mu         sync.RWMutex
+	filePath   string
+	promotions []models.Promotion
+}
+
+// NewInMemoryPromotionRepository membuat instance baru dari InMemoryPromotionRepository
+func NewInMemoryPromotionRepository(filePath string) (*InMemoryPromotionRepository, error) {
+	// Membaca file yang berisi data promosi, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read promotion data: %v", err)
+	}
+
+	var promotions []models.Promotion
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &promotions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal promotion data: %v", err)
+		}
+	}
+
+	return &InMemoryPromotionRepository{
+		filePath:   filePath,
+		promotions: promotions,
+	}, nil
+}
+
+// Save menyimpan promosi baru atau memperbarui yang sudah ada
+func (r *InMemoryPromotionRepository) Save(promotion *models.Promotion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.promotions
+	updated := false
+	r.promotions = make([]models.Promotion, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID == promotion.ID {
+			existing = copyPromotion(promotion)
+			updated = true
+		}
+		r.promotions = append(r.promotions, existing)
+	}
+	if !updated {
+		r.promotions = append(r.promotions, copyPromotion(promotion))
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.promotions = previous
+		return err
+	}
+	return nil
+}
+
+// GetByID mengambil salinan promosi berdasarkan ID
+func (r *InMemoryPromotionRepository) GetByID(promotionID string) (*models.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, promotion := range r.promotions {
+		if promotion.ID == promotionID {
+			result := copyPromotion(&promotion)
+			return &result, nil
+		}
+	}
+
+	return nil, ErrPromotionNotFound
+}
+
+// GetByCode mengambil salinan promosi berdasarkan kode voucher tanpa membedakan huruf besar dan kecil
+func (r *InMemoryPromotionRepository) GetByCode(code string) (*models.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, promotion := range r.promotions {
+		if promotion.Code != "" && strings.EqualFold(promotion.Code, code) {
+			result := copyPromotion(&promotion)
+			return &result, nil
+		}
+	}
+
+	return nil, ErrPromotionNotFound
+}
+
+// GetAll mengambil seluruh promosi diurutkan dari yang terbaru
+func (r *InMemoryPromotionRepository) GetAll() ([]models.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	promotions := make([]models.Promotion, 0, len(r.promotions))
+	for i := range r.promotions {
+		promotions = append(promotions, copyPromotion(&r.promotions[i]))
+	}
+
+	sort.SliceStable(promotions, func(i, j int) bool {
+		return promotions[i].CreatedAt.After(promotions[j].CreatedAt)
+	})
+	return promotions, nil
+}
+
+// Fungsi bantu untuk menyalin promosi beserta daftar tier dan pelanggannya agar perubahan di luar repository
+// tidak mengubah data yang tersimpan
+func copyPromotion(promotion *models.Promotion) models.Promotion {
+	result := *promotion
+	result.Tiers = append([]string(nil), promotion.Tiers...)
+	result.CustomerIDs = append([]string(nil), promotion.CustomerIDs...)
+	return result
+}
+
+// Fungsi bantu untuk menyimpan data promosi ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryPromotionRepository) saveToFile() error {
+	data, err := json.Marshal(r.promotions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal promotion data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write promotion data to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrQRCodeNotFound dikembalikan ketika QR dinamis tidak ditemukan
+var ErrQRCodeNotFound = errors.New("qr code not found")
+
+// Mendefinisikan interface QRCodeRepository yang menyediakan method-method
+type QRCodeRepository interface {
+	Save(code *models.QRCode) error
+	GetByReference(reference string) (*models.QRCode, error)
+}
+
+// InMemoryQRCodeRepository menyimpan QR dinamis di memori dan menuliskannya ke file JSON
+type InMemoryQRCodeRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	codes    []models.QRCode
+}
+
+// NewInMemoryQRCodeRepository membuat instance baru dari InMemoryQRCodeRepository
+func NewInMemoryQRCodeRepository(filePath string) (*InMemoryQRCodeRepository, error) {
+	// Membaca file yang berisi data QR dinamis, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read qr code data: %v", err)
+	}
+
+	var codes []models.QRCode
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &codes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal qr code data: %v", err)
+		}
+	}
+
+	return &InMemoryQRCodeRepository{
+		filePath: filePath,
+		codes:    codes,
+	}, nil
+}
+
+// Save menyimpan QR dinamis baru atau memperbarui QR dengan referensi yang sama
+func (r *InMemoryQRCodeRepository) Save(code *models.QRCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.codes
+	updated := false
+	r.codes = make([]models.QRCode, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.Reference == code.Reference {
+			existing = *code
+			updated = true
+		}
+		r.codes = append(r.codes, existing)
+	}
+	if !updated {
+		r.codes = append(r.codes, *code)
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.codes = previous
+		return err
+	}
+	return nil
+}
+
+// GetByReference mengambil salinan QR dinamis merchant berdasarkan referensinya
+func (r *InMemoryQRCodeRepository) GetByReference(reference string) (*models.QRCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, code := range r.codes {
+		if code.Reference == reference {
+			result := code
+			return &result, nil
+		}
+	}
+
+	return nil, ErrQRCodeNotFound
+}
+
+// Fungsi bantu untuk menyimpan data QR dinamis ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryQRCodeRepository) saveToFile() error {
+	data, err := json.Marshal(r.codes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal qr code data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write qr code data to file: %v", err)
+	}
+
+	return nil
+}
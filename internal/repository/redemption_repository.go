@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrRedemptionNotFound dikembalikan ketika pemakaian promosi tidak ditemukan
+var ErrRedemptionNotFound = errors.New("redemption not found")
+
+// Mendefinisikan interface RedemptionRepository yang menyediakan method-method
+type RedemptionRepository interface {
+	Save(redemption *models.Redemption) error
+	GetByID(redemptionID string) (*models.Redemption, error)
+	GetByPromotionID(promotionID string) ([]models.Redemption, error)
+	GetByTransactionID(transactionID string) (*models.Redemption, error)
+	GetReserved() ([]models.Redemption, error)
+}
+
+// InMemoryRedemptionRepository menyimpan pemakaian promosi di memori dan menuliskannya ke file JSON
+type InMemoryRedemptionRepository struct {
+	mu          sync.RWMutex
+	filePath    string
+	redemptions []models.Redemption
+}
+
+// NewInMemoryRedemptionRepository membuat instance baru dari InMemoryRedemptionRepository
+func NewInMemoryRedemptionRepository(filePath string) (*InMemoryRedemptionRepository, error) {
+	// Membaca file yang berisi data pemakaian promosi, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read redemption data: %v", err)
+	}
+
+	var redemptions []models.Redemption
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &redemptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal redemption data: %v", err)
+		}
+	}
+
+	return &InMemoryRedemptionRepository{
+		filePath:    filePath,
+		redemptions: redemptions,
+	}, nil
+}
+
+// Save menyimpan pemakaian promosi baru atau memperbarui yang sudah ada
+func (r *InMemoryRedemptionRepository) Save(redemption *models.Redemption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.redemptions
+	updated := false
+	r.redemptions = make([]models.Redemption, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID == redemption.ID {
+			existing = *redemption
+			updated = true
+		}
+		r.redemptions = append(r.redemptions, existing)
+	}
+	if !updated {
+		r.redemptions = append(r.redemptions, *redemption)
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.redemptions = previous
+		return err
+	}
+	return nil
+}
+
+// GetByID mengambil salinan pemakaian promosi berdasarkan ID
+func (r *InMemoryRedemptionRepository) GetByID(redemptionID string) (*models.Redemption, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, redemption := range r.redemptions {
+		if redemption.ID == redemptionID {
+			result := redemption
+			return &result, nil
+		}
+	}
+
+	return nil, ErrRedemptionNotFound
+}
+
+// GetByPromotionID mengambil pemakaian sebuah promosi diurutkan dari yang paling lama
+func (r *InMemoryRedemptionRepository) GetByPromotionID(promotionID string) ([]models.Redemption, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	redemptions := make([]models.Redemption, 0)
+	for _, redemption := range r.redemptions {
+		if redemption.PromotionID == promotionID {
+			redemptions = append(redemptions, redemption)
+		}
+	}
+
+	sort.SliceStable(redemptions, func(i, j int) bool {
+		return redemptions[i].CreatedAt.Before(redemptions[j].CreatedAt)
+	})
+	return redemptions, nil
+}
+
+// GetByTransactionID mengambil pemakaian promosi untuk sebuah transaksi pembayaran
+func (r *InMemoryRedemptionRepository) GetByTransactionID(transactionID string) (*models.Redemption, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, redemption := range r.redemptions {
+		if redemption.TransactionID == transactionID {
+			result := redemption
+			return &result, nil
+		}
+	}
+
+	return nil, ErrRedemptionNotFound
+}
+
+// GetReserved mengambil pemakaian promosi yang kuotanya masih dipesan diurutkan dari yang paling lama
+func (r *InMemoryRedemptionRepository) GetReserved() ([]models.Redemption, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	redemptions := make([]models.Redemption, 0)
+	for _, redemption := range r.redemptions {
+		if redemption.Status == models.RedemptionStatusReserved {
+			redemptions = append(redemptions, redemption)
+		}
+	}
+
+	sort.SliceStable(redemptions, func(i, j int) bool {
+		return redemptions[i].CreatedAt.Before(redemptions[j].CreatedAt)
+	})
+	return redemptions, nil
+}
+
+// Fungsi bantu untuk menyimpan data pemakaian promosi ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryRedemptionRepository) saveToFile() error {
+	data, err := json.Marshal(r.redemptions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal redemption data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write redemption data to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// Mendefinisikan interface RiskEventRepository yang menyediakan method-method
+type RiskEventRepository interface {
+	Save(event *models.RiskEvent) error
+	GetAll() ([]models.RiskEvent, error)
+}
+
+// InMemoryRiskEventRepository menyimpan catatan pemeriksaan risiko di memori dan menuliskannya ke file JSON
+type InMemoryRiskEventRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	events   []models.RiskEvent
+}
+
+// NewInMemoryRiskEventRepository membuat instance baru dari InMemoryRiskEventRepository
+func NewInMemoryRiskEventRepository(filePath string) (*InMemoryRiskEventRepository, error) {
+	// Membaca file yang berisi catatan risiko, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read risk event data: %v", err)
+	}
+
+	var events []models.RiskEvent
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &events)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal risk event data: %v", err)
+		}
+	}
+
+	return &InMemoryRiskEventRepository{
+		filePath: filePath,
+		events:   events,
+	}, nil
+}
+
+// Save menambahkan catatan risiko baru
+func (r *InMemoryRiskEventRepository) Save(event *models.RiskEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.events
+	r.events = append(append(make([]models.RiskEvent, 0, len(previous)+1), previous...), copyRiskEvent(event))
+
+	err := r.saveToFile()
+	if err != nil {
+		r.events = previous
+		return err
+	}
+	return nil
+}
+
+// GetAll mengambil seluruh catatan risiko diurutkan dari yang terbaru
+func (r *InMemoryRiskEventRepository) GetAll() ([]models.RiskEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]models.RiskEvent, 0, len(r.events))
+	for i := len(r.events) - 1; i >= 0; i-- {
+		events = append(events, copyRiskEvent(&r.events[i]))
+	}
+	return events, nil
+}
+
+// Fungsi bantu untuk menyalin catatan risiko beserta alasannya agar perubahan di luar repository
+// tidak mengubah data yang tersimpan
+func copyRiskEvent(event *models.RiskEvent) models.RiskEvent {
+	result := *event
+	result.Reasons = append([]models.RiskReason(nil), event.Reasons...)
+	return result
+}
+
+// Fungsi bantu untuk menyimpan catatan risiko ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryRiskEventRepository) saveToFile() error {
+	data, err := json.Marshal(r.events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal risk event data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write risk event data to file: %v", err)
+	}
+
+	return nil
+}
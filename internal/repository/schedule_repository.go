@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrScheduleNotFound dikembalikan ketika pembayaran terjadwal tidak ditemukan
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// Mendefinisikan interface ScheduleRepository yang menyediakan method-method
+type ScheduleRepository interface {
+	Save(schedule *models.Schedule) error
+	GetByID(scheduleID string) (*models.Schedule, error)
+	GetByCustomerID(customerID string) ([]models.Schedule, error)
+	GetDue(now time.Time) ([]models.Schedule, error)
+	GetRunning() ([]models.Schedule, error)
+}
+
+// InMemoryScheduleRepository menyimpan pembayaran terjadwal di memori dan menuliskannya ke file JSON
+type InMemoryScheduleRepository struct {
+	mu        sync.RWMutex
+	filePath  string
+	schedules []models.Schedule
+}
+
+// NewInMemoryScheduleRepository membuat instance baru dari InMemoryScheduleRepository
+func NewInMemoryScheduleRepository(filePath string) (*InMemoryScheduleRepository, error) {
+	// Membaca file yang berisi data pembayaran terjadwal, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read schedule data: %v", err)
+	}
+
+	var schedules []models.Schedule
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &schedules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal schedule data: %v", err)
+		}
+	}
+
+	return &InMemoryScheduleRepository{
+		filePath:  filePath,
+		schedules: schedules,
+	}, nil
+}
+
+// Save menyimpan pembayaran terjadwal baru atau memperbarui yang sudah ada
+func (r *InMemoryScheduleRepository) Save(schedule *models.Schedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.schedules
+	updated := false
+	r.schedules = make([]models.Schedule, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID == schedule.ID {
+			existing = *schedule
+			updated = true
+		}
+		r.schedules = append(r.schedules, existing)
+	}
+	if !updated {
+		r.schedules = append(r.schedules, *schedule)
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.schedules = previous
+		return err
+	}
+	return nil
+}
+
+// GetByID mengambil salinan pembayaran terjadwal berdasarkan ID
+func (r *InMemoryScheduleRepository) GetByID(scheduleID string) (*models.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, schedule := range r.schedules {
+		if schedule.ID == scheduleID {
+			return &schedule, nil
+		}
+	}
+
+	return nil, ErrScheduleNotFound
+}
+
+// GetByCustomerID mengambil pembayaran terjadwal milik pelanggan diurutkan dari yang terbaru
+func (r *InMemoryScheduleRepository) GetByCustomerID(customerID string) ([]models.Schedule, error) {
+	return r.filter(func(schedule *models.Schedule) bool {
+		return schedule.CustomerID == customerID
+	}, func(a, b *models.Schedule) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}), nil
+}
+
+// GetDue mengambil pembayaran terjadwal aktif yang waktu percobaannya sudah tiba, diurutkan dari yang paling lama
+func (r *InMemoryScheduleRepository) GetDue(now time.Time) ([]models.Schedule, error) {
+	return r.filter(func(schedule *models.Schedule) bool {
+		return schedule.Status == models.ScheduleStatusActive && schedule.RunningSince == nil &&
+			schedule.NextRunAt != nil && !schedule.NextRunAt.After(now)
+	}, func(a, b *models.Schedule) bool {
+		return a.NextRunAt.Before(*b.NextRunAt)
+	}), nil
+}
+
+// GetRunning mengambil pembayaran terjadwal yang tercatat sedang dijalankan
+func (r *InMemoryScheduleRepository) GetRunning() ([]models.Schedule, error) {
+	return r.filter(func(schedule *models.Schedule) bool {
+		return schedule.RunningSince != nil
+	}, func(a, b *models.Schedule) bool {
+		return a.RunningSince.Before(*b.RunningSince)
+	}), nil
+}
+
+// Fungsi bantu untuk mengambil salinan pembayaran terjadwal yang memenuhi kondisi secara terurut
+func (r *InMemoryScheduleRepository) filter(match func(*models.Schedule) bool, less func(a, b *models.Schedule) bool) []models.Schedule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := make([]models.Schedule, 0)
+	for i := range r.schedules {
+		if match(&r.schedules[i]) {
+			schedules = append(schedules, r.schedules[i])
+		}
+	}
+
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return less(&schedules[i], &schedules[j])
+	})
+	return schedules
+}
+
+// Fungsi bantu untuk menyimpan data pembayaran terjadwal ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryScheduleRepository) saveToFile() error {
+	data, err := json.Marshal(r.schedules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write schedule data to file: %v", err)
+	}
+
+	return nil
+}
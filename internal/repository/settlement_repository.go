@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrSettlementNotFound dikembalikan ketika batch settlement tidak ditemukan
+var ErrSettlementNotFound = errors.New("settlement batch not found")
+
+// Mendefinisikan interface SettlementRepository yang menyediakan method-method
+type SettlementRepository interface {
+	Save(batch *models.SettlementBatch) error
+	Delete(batchID string) error
+	GetByID(batchID string) (*models.SettlementBatch, error)
+	List(merchantID string) ([]models.SettlementBatch, error)
+}
+
+// InMemorySettlementRepository menyimpan batch settlement di memori dan menuliskannya ke file JSON
+type InMemorySettlementRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	batches  []models.SettlementBatch
+}
+
+// NewInMemorySettlementRepository membuat instance baru dari InMemorySettlementRepository
+func NewInMemorySettlementRepository(filePath string) (*InMemorySettlementRepository, error) {
+	// Membaca file yang berisi data settlement, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read settlement data: %v", err)
+	}
+
+	var batches []models.SettlementBatch
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &batches)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal settlement data: %v", err)
+		}
+	}
+
+	return &InMemorySettlementRepository{
+		filePath: filePath,
+		batches:  batches,
+	}, nil
+}
+
+// Save menyimpan batch settlement baru atau memperbarui batch yang sudah ada
+func (r *InMemorySettlementRepository) Save(batch *models.SettlementBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.batches
+	updated := false
+	r.batches = make([]models.SettlementBatch, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID == batch.ID {
+			existing = *batch
+			updated = true
+		}
+		r.batches = append(r.batches, existing)
+	}
+	if !updated {
+		r.batches = append(r.batches, *batch)
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.batches = previous
+		return err
+	}
+	return nil
+}
+
+// Delete menghapus batch settlement
+func (r *InMemorySettlementRepository) Delete(batchID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.batches
+	r.batches = make([]models.SettlementBatch, 0, len(previous))
+	for _, batch := range previous {
+		if batch.ID != batchID {
+			r.batches = append(r.batches, batch)
+		}
+	}
+	if len(r.batches) == len(previous) {
+		r.batches = previous
+		return ErrSettlementNotFound
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.batches = previous
+		return err
+	}
+	return nil
+}
+
+// GetByID mengambil salinan batch settlement berdasarkan ID
+func (r *InMemorySettlementRepository) GetByID(batchID string) (*models.SettlementBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, batch := range r.batches {
+		if batch.ID == batchID {
+			return &batch, nil
+		}
+	}
+
+	return nil, ErrSettlementNotFound
+}
+
+// List mengambil batch settlement yang diurutkan dari periode terbaru.
+// merchantID kosong berarti seluruh merchant.
+func (r *InMemorySettlementRepository) List(merchantID string) ([]models.SettlementBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	batches := make([]models.SettlementBatch, 0)
+	for _, batch := range r.batches {
+		if merchantID == "" || batch.MerchantID == merchantID {
+			batches = append(batches, batch)
+		}
+	}
+
+	sort.SliceStable(batches, func(i, j int) bool {
+		return batches[i].PeriodEnd.After(batches[j].PeriodEnd)
+	})
+	return batches, nil
+}
+
+// Fungsi bantu untuk menyimpan data settlement ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemorySettlementRepository) saveToFile() error {
+	data, err := json.Marshal(r.batches)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settlement data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write settlement data to file: %v", err)
+	}
+
+	return nil
+}
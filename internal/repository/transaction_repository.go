@@ -2,14 +2,17 @@ package repository
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"sync"
 
 	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
 )
 
 // TransactionRepository menangani penyimpanan dan pengambilan transaksi
 type TransactionRepository struct {
+	mu       sync.Mutex
 	filePath string
 }
 
@@ -20,8 +23,17 @@ func NewTransactionRepository(filePath string) *TransactionRepository {
 	}
 }
 
-// SaveTransaction menyimpan transaksi ke file JSON
+// SaveTransaction menyimpan transaksi baru atau memperbarui transaksi dengan ID yang sama ke file JSON
 func (r *TransactionRepository) SaveTransaction(transaction *models.Transaction) error {
+	return r.SaveTransactions(transaction)
+}
+
+// SaveTransactions menyimpan atau memperbarui beberapa transaksi ke file JSON dalam satu kali penulisan,
+// sehingga seluruh transaksi tersimpan bersama atau tidak tersimpan sama sekali
+func (r *TransactionRepository) SaveTransactions(newTransactions ...*models.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Buka file JSON
 	file, err := os.OpenFile(r.filePath, os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
@@ -35,8 +47,26 @@ func (r *TransactionRepository) SaveTransaction(transaction *models.Transaction)
 		return err
 	}
 
-	// Tambahkan transaksi baru
-	transactions = append(transactions, *transaction)
+	// Perbarui transaksi yang sudah ada atau tambahkan transaksi baru
+	for _, transaction := range newTransactions {
+		updated := false
+		for i := range transactions {
+			if transactions[i].ID == transaction.ID {
+				// ID settlement yang sudah tercatat tidak pernah dihapus oleh pembaruan transaksi
+				// yang dibaca sebelum transaksi tersebut di-settle
+				settlementID := transactions[i].SettlementID
+				transactions[i] = *transaction
+				if transactions[i].SettlementID == "" {
+					transactions[i].SettlementID = settlementID
+				}
+				updated = true
+				break
+			}
+		}
+		if !updated {
+			transactions = append(transactions, *transaction)
+		}
+	}
 
 	// Encode transaksi yang diperbarui menjadi JSON
 	transactionJSON, err := json.Marshal(transactions)
@@ -55,6 +85,9 @@ func (r *TransactionRepository) SaveTransaction(transaction *models.Transaction)
 
 // GetTransactionsByCustomerID mengambil semua transaksi yang terkait dengan ID pelanggan
 func (r *TransactionRepository) GetTransactionsByCustomerID(customerID string) ([]models.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Baca file JSON
 	file, err := ioutil.ReadFile(r.filePath)
 	if err != nil {
@@ -81,6 +114,53 @@ func (r *TransactionRepository) GetTransactionsByCustomerID(customerID string) (
 	return filteredTransactions, nil
 }
 
+// GetAllTransactions mengambil semua transaksi
+func (r *TransactionRepository) GetAllTransactions() ([]models.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.getTransactionsFromFile()
+}
+
+// GetTransactionByID mengambil transaksi berdasarkan ID
+func (r *TransactionRepository) GetTransactionByID(transactionID string) (*models.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transactions, err := r.getTransactionsFromFile()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range transactions {
+		if transaction.ID == transactionID {
+			return &transaction, nil
+		}
+	}
+
+	return nil, fmt.Errorf("transaction not found")
+}
+
+// GetTransactionsByOriginalID mengambil semua transaksi yang merujuk ke transaksi asal, misalnya refund
+func (r *TransactionRepository) GetTransactionsByOriginalID(originalID string) ([]models.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transactions, err := r.getTransactionsFromFile()
+	if err != nil {
+		return nil, err
+	}
+
+	filteredTransactions := make([]models.Transaction, 0)
+	for _, transaction := range transactions {
+		if transaction.OriginalID == originalID {
+			filteredTransactions = append(filteredTransactions, transaction)
+		}
+	}
+
+	return filteredTransactions, nil
+}
+
 // Fungsi bantu untuk mendapatkan transaksi dari file
 func (r *TransactionRepository) getTransactionsFromFile() ([]models.Transaction, error) {
 	// Baca file JSON
@@ -99,3 +179,57 @@ func (r *TransactionRepository) getTransactionsFromFile() ([]models.Transaction,
 
 	return transactions, nil
 }
+
+// MarkSettled menandai transaksi sebagai bagian dari settlement secara atomik.
+// Seluruh transaksi gagal ditandai jika salah satunya sudah masuk ke settlement lain.
+func (r *TransactionRepository) MarkSettled(settlementID string, transactionIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transactions, err := r.getTransactionsFromFile()
+	if err != nil {
+		return err
+	}
+
+	marked := make(map[string]bool, len(transactionIDs))
+	for _, id := range transactionIDs {
+		marked[id] = true
+	}
+	for i := range transactions {
+		if !marked[transactions[i].ID] {
+			continue
+		}
+		if transactions[i].SettlementID != "" && transactions[i].SettlementID != settlementID {
+			return fmt.Errorf("transaction %s is already settled in %s", transactions[i].ID, transactions[i].SettlementID)
+		}
+		transactions[i].SettlementID = settlementID
+		delete(marked, transactions[i].ID)
+	}
+	for id := range marked {
+		return fmt.Errorf("transaction %s not found", id)
+	}
+
+	transactionJSON, err := json.Marshal(transactions)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.filePath, transactionJSON, 0644)
+}
+
+// GetTransactionsBySettlementID mengambil semua transaksi yang termasuk dalam sebuah settlement
+func (r *TransactionRepository) GetTransactionsBySettlementID(settlementID string) ([]models.Transaction, error) {
+	transactions, err := r.GetAllTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	var settled []models.Transaction
+	for _, transaction := range transactions {
+		if transaction.SettlementID == settlementID {
+			settled = append(settled, transaction)
+		}
+	}
+
+	return settled, nil
+}
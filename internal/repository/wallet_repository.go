@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// ErrInsufficientFunds dikembalikan ketika saldo wallet tidak cukup untuk didebit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Mendefinisikan interface WalletRepository yang menyediakan method-method
+type WalletRepository interface {
+	GetByCustomerID(customerID string) (*models.Wallet, error)
+	GetAll() ([]models.Wallet, error)
+	Debit(customerID string, amount money.Money, commit func() error) (*models.Wallet, error)
+	Credit(customerID string, amount money.Money, commit func() error) (*models.Wallet, error)
+	Transfer(fromCustomerID, toCustomerID string, amount money.Money, commit func() error) (*models.Wallet, *models.Wallet, error)
+	Hold(customerID string, amount money.Money, commit func() error) (*models.Wallet, error)
+	ReleaseHold(customerID string, amount money.Money, commit func() error) (*models.Wallet, error)
+	CaptureHold(customerID string, holdAmount, captureAmount money.Money, commit func() error) (*models.Wallet, error)
+}
+
+// InMemoryWalletRepository menyimpan wallet di memori dan menuliskannya ke file JSON.
+// Seluruh perubahan saldo dilakukan di bawah satu mutex sehingga pemeriksaan dan
+// pendebitan saldo terjadi secara atomik.
+type InMemoryWalletRepository struct {
+	mu       sync.Mutex
+	filePath string
+	wallets  []*models.Wallet
+}
+
+// NewInMemoryWalletRepository membuat instance baru dari InMemoryWalletRepository
+func NewInMemoryWalletRepository(filePath string) (*InMemoryWalletRepository, error) {
+	// Membaca file yang berisi data wallet, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read wallet data: %v", err)
+	}
+
+	var wallets []*models.Wallet
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &wallets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal wallet data: %v", err)
+		}
+	}
+
+	// Wallet lama yang belum memiliki mata uang mengikuti mata uang saldonya, default IDR
+	for _, wallet := range wallets {
+		if wallet.Currency == "" {
+			wallet.Currency = wallet.Balance.Currency()
+		}
+	}
+
+	return &InMemoryWalletRepository{
+		filePath: filePath,
+		wallets:  wallets,
+	}, nil
+}
+
+// GetByCustomerID mengambil salinan wallet milik pelanggan.
+// Pelanggan yang belum memiliki wallet dianggap memiliki saldo nol.
+func (r *InMemoryWalletRepository) GetByCustomerID(customerID string) (*models.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, w := range r.wallets {
+		if w.CustomerID == customerID {
+			wallet := *w
+			return &wallet, nil
+		}
+	}
+
+	return newWallet(customerID), nil
+}
+
+// GetAll mengambil salinan seluruh wallet
+func (r *InMemoryWalletRepository) GetAll() ([]models.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallets := make([]models.Wallet, 0, len(r.wallets))
+	for _, w := range r.wallets {
+		wallets = append(wallets, *w)
+	}
+	return wallets, nil
+}
+
+// Debit mengurangi saldo wallet jika saldo mencukupi.
+// Fungsi commit (boleh nil) dipanggil setelah saldo baru disimpan, jika gagal maka saldo dikembalikan.
+// Debit sejumlah nol tidak mengubah saldo dan hanya menjalankan commit selama wallet dikunci.
+func (r *InMemoryWalletRepository) Debit(customerID string, amount money.Money, commit func() error) (*models.Wallet, error) {
+	if amount.IsNegative() {
+		return nil, fmt.Errorf("invalid debit amount: %s", amount)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet := r.findOrCreate(customerID)
+	err := checkCurrency(wallet, amount)
+	if err != nil {
+		return nil, err
+	}
+	err = checkAvailable(wallet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.apply(wallet, money.Money{}, amount.Neg(), commit)
+}
+
+// Credit menambah saldo wallet.
+// Fungsi commit (boleh nil) dipanggil setelah saldo baru disimpan, jika gagal maka saldo dikembalikan.
+func (r *InMemoryWalletRepository) Credit(customerID string, amount money.Money, commit func() error) (*models.Wallet, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("invalid credit amount: %s", amount)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet := r.findOrCreate(customerID)
+	err := checkCurrency(wallet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.apply(wallet, money.Money{}, amount, commit)
+}
+
+// Transfer memindahkan saldo dari satu wallet ke wallet lain secara atomik.
+// Fungsi commit (boleh nil) dipanggil setelah saldo kedua wallet diubah dan disimpan,
+// jika penyimpanan atau commit gagal maka saldo kedua wallet dikembalikan seperti semula.
+func (r *InMemoryWalletRepository) Transfer(fromCustomerID, toCustomerID string, amount money.Money, commit func() error) (*models.Wallet, *models.Wallet, error) {
+	if !amount.IsPositive() {
+		return nil, nil, fmt.Errorf("invalid transfer amount: %s", amount)
+	}
+	if fromCustomerID == toCustomerID {
+		return nil, nil, errors.New("cannot transfer to the same wallet")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	from := r.findOrCreate(fromCustomerID)
+	to := r.findOrCreate(toCustomerID)
+	err := checkCurrency(from, amount)
+	if err == nil {
+		err = checkCurrency(to, amount)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	err = checkAvailable(from, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previousFrom, previousTo := *from, *to
+	restore := func() {
+		*from, *to = previousFrom, previousTo
+	}
+	err = adjust(from, money.Money{}, amount.Neg())
+	if err == nil {
+		err = adjust(to, money.Money{}, amount)
+	}
+	if err != nil {
+		restore()
+		return nil, nil, err
+	}
+	err = r.saveAndCommit(commit, restore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resultFrom, resultTo := *from, *to
+	return &resultFrom, &resultTo, nil
+}
+
+// Hold menahan sebagian saldo yang tersedia untuk otorisasi pembayaran
+func (r *InMemoryWalletRepository) Hold(customerID string, amount money.Money, commit func() error) (*models.Wallet, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("invalid hold amount: %s", amount)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet := r.findOrCreate(customerID)
+	err := checkCurrency(wallet, amount)
+	if err != nil {
+		return nil, err
+	}
+	err = checkAvailable(wallet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.apply(wallet, amount, money.Money{}, commit)
+}
+
+// ReleaseHold melepaskan saldo yang ditahan tanpa mengurangi saldo
+func (r *InMemoryWalletRepository) ReleaseHold(customerID string, amount money.Money, commit func() error) (*models.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet := r.findOrCreate(customerID)
+	err := checkHeld(wallet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.apply(wallet, amount.Neg(), money.Money{}, commit)
+}
+
+// CaptureHold melepaskan saldo yang ditahan lalu mendebit jumlah yang di-capture,
+// jumlah capture tidak boleh melebihi jumlah yang ditahan
+func (r *InMemoryWalletRepository) CaptureHold(customerID string, holdAmount, captureAmount money.Money, commit func() error) (*models.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet := r.findOrCreate(customerID)
+	err := checkHeld(wallet, holdAmount)
+	if err != nil {
+		return nil, err
+	}
+	cmp, err := captureAmount.Cmp(holdAmount)
+	if err != nil {
+		return nil, err
+	}
+	if !captureAmount.IsPositive() || cmp > 0 {
+		return nil, fmt.Errorf("invalid capture amount: %s", captureAmount)
+	}
+
+	return r.apply(wallet, holdAmount.Neg(), captureAmount.Neg(), commit)
+}
+
+// Fungsi bantu untuk mengubah saldo yang ditahan dan saldo, menyimpannya, lalu menjalankan commit (boleh nil).
+// Perubahan dibatalkan jika penyimpanan atau commit gagal. Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryWalletRepository) apply(wallet *models.Wallet, heldDelta, balanceDelta money.Money, commit func() error) (*models.Wallet, error) {
+	previous := *wallet
+	restore := func() {
+		*wallet = previous
+	}
+	err := adjust(wallet, heldDelta, balanceDelta)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	err = r.saveAndCommit(commit, restore)
+	if err != nil {
+		return nil, err
+	}
+
+	result := *wallet
+	return &result, nil
+}
+
+// Fungsi bantu untuk menyimpan data wallet ke file lalu menjalankan commit. Wallet disimpan lebih dulu karena
+// commit memposting jurnal dan menyimpan transaksi yang tidak dapat dibatalkan setelah commit berhasil.
+// Jika penyimpanan atau commit gagal, restore mengembalikan saldo di memori dan data wallet disimpan ulang
+// sehingga file wallet tetap sesuai dengan jurnal dan transaksi. Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryWalletRepository) saveAndCommit(commit func() error, restore func()) error {
+	err := r.saveToFile()
+	if err != nil {
+		restore()
+		return err
+	}
+	if commit == nil {
+		return nil
+	}
+
+	err = commit()
+	if err != nil {
+		restore()
+		saveErr := r.saveToFile()
+		if saveErr != nil {
+			return fmt.Errorf("%w; failed to restore wallet data: %v", err, saveErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Fungsi bantu untuk mencari wallet pelanggan atau membuat wallet baru dengan saldo nol.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryWalletRepository) findOrCreate(customerID string) *models.Wallet {
+	for _, w := range r.wallets {
+		if w.CustomerID == customerID {
+			return w
+		}
+	}
+
+	wallet := newWallet(customerID)
+	r.wallets = append(r.wallets, wallet)
+	return wallet
+}
+
+// Fungsi bantu untuk menyimpan data wallet ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryWalletRepository) saveToFile() error {
+	data, err := json.Marshal(r.wallets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal wallet data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write wallet data to file: %v", err)
+	}
+
+	return nil
+}
+
+// Fungsi bantu untuk membuat wallet baru dengan saldo nol
+func newWallet(customerID string) *models.Wallet {
+	return &models.Wallet{
+		CustomerID: customerID,
+		Currency:   money.DefaultCurrency,
+		Balance:    money.Zero(money.DefaultCurrency),
+		Held:       money.Zero(money.DefaultCurrency),
+	}
+}
+
+// Fungsi bantu untuk memeriksa apakah jumlah dalam mata uang wallet
+func checkCurrency(wallet *models.Wallet, amount money.Money) error {
+	if amount.Currency() != wallet.Currency {
+		return fmt.Errorf("%w: wallet currency is %s, got %s", money.ErrCurrencyMismatch, wallet.Currency, amount.Currency())
+	}
+	return nil
+}
+
+// Fungsi bantu untuk memeriksa apakah saldo yang tersedia mencukupi
+func checkAvailable(wallet *models.Wallet, amount money.Money) error {
+	cmp, err := wallet.Available().Cmp(amount)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// Fungsi bantu untuk memeriksa apakah saldo yang ditahan mencukupi untuk dilepas
+func checkHeld(wallet *models.Wallet, amount money.Money) error {
+	cmp, err := wallet.Held.Cmp(amount)
+	if err != nil {
+		return err
+	}
+	if !amount.IsPositive() || cmp < 0 {
+		return fmt.Errorf("invalid hold amount: %s", amount)
+	}
+	return nil
+}
+
+// Fungsi bantu untuk menambahkan perubahan saldo yang ditahan dan saldo ke wallet
+func adjust(wallet *models.Wallet, heldDelta, balanceDelta money.Money) error {
+	held, err := wallet.Held.Add(heldDelta)
+	if err != nil {
+		return err
+	}
+	balance, err := wallet.Balance.Add(balanceDelta)
+	if err != nil {
+		return err
+	}
+
+	wallet.Held = held
+	wallet.Balance = balance
+	wallet.UpdatedAt = time.Now()
+	return nil
+}
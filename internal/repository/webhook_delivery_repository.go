@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrWebhookDeliveryNotFound dikembalikan ketika pengiriman webhook tidak ditemukan
+var ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+// Mendefinisikan interface WebhookDeliveryRepository yang menyediakan method-method
+type WebhookDeliveryRepository interface {
+	Save(delivery *models.WebhookDelivery) error
+	GetByID(deliveryID string) (*models.WebhookDelivery, error)
+	GetByMerchantID(merchantID string) ([]models.WebhookDelivery, error)
+	GetDue(now time.Time) ([]models.WebhookDelivery, error)
+}
+
+// InMemoryWebhookDeliveryRepository adalah outbox pengiriman webhook yang disimpan di memori dan
+// dituliskan ke file JSON sehingga pengiriman yang tertunda tetap dicoba setelah server dijalankan ulang
+type InMemoryWebhookDeliveryRepository struct {
+	mu         sync.RWMutex
+	filePath   string
+	deliveries []models.WebhookDelivery
+}
+
+// NewInMemoryWebhookDeliveryRepository membuat instance baru dari InMemoryWebhookDeliveryRepository
+func NewInMemoryWebhookDeliveryRepository(filePath string) (*InMemoryWebhookDeliveryRepository, error) {
+	// Membaca file yang berisi pengiriman webhook, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read webhook delivery data: %v", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &deliveries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook delivery data: %v", err)
+		}
+	}
+
+	return &InMemoryWebhookDeliveryRepository{
+		filePath:   filePath,
+		deliveries: deliveries,
+	}, nil
+}
+
+// Save menyimpan pengiriman webhook baru atau memperbarui yang sudah ada
+func (r *InMemoryWebhookDeliveryRepository) Save(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.deliveries
+	updated := false
+	r.deliveries = make([]models.WebhookDelivery, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID == delivery.ID {
+			existing = copyWebhookDelivery(delivery)
+			updated = true
+		}
+		r.deliveries = append(r.deliveries, existing)
+	}
+	if !updated {
+		r.deliveries = append(r.deliveries, copyWebhookDelivery(delivery))
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.deliveries = previous
+		return err
+	}
+	return nil
+}
+
+// GetByID mengambil salinan pengiriman webhook berdasarkan ID
+func (r *InMemoryWebhookDeliveryRepository) GetByID(deliveryID string) (*models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, delivery := range r.deliveries {
+		if delivery.ID == deliveryID {
+			result := copyWebhookDelivery(&delivery)
+			return &result, nil
+		}
+	}
+
+	return nil, ErrWebhookDeliveryNotFound
+}
+
+// GetByMerchantID mengambil pengiriman webhook milik merchant diurutkan dari yang terbaru
+func (r *InMemoryWebhookDeliveryRepository) GetByMerchantID(merchantID string) ([]models.WebhookDelivery, error) {
+	return r.filter(func(delivery *models.WebhookDelivery) bool {
+		return delivery.MerchantID == merchantID
+	}, func(a, b *models.WebhookDelivery) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}), nil
+}
+
+// GetDue mengambil pengiriman yang masih pending dan sudah waktunya dicoba, diurutkan dari yang paling lama
+func (r *InMemoryWebhookDeliveryRepository) GetDue(now time.Time) ([]models.WebhookDelivery, error) {
+	return r.filter(func(delivery *models.WebhookDelivery) bool {
+		return delivery.Status == models.WebhookDeliveryPending && (delivery.NextAttemptAt == nil || !now.Before(*delivery.NextAttemptAt))
+	}, func(a, b *models.WebhookDelivery) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+// Fungsi bantu untuk mengambil salinan pengiriman webhook yang memenuhi kondisi secara terurut
+func (r *InMemoryWebhookDeliveryRepository) filter(match func(*models.WebhookDelivery) bool, less func(a, b *models.WebhookDelivery) bool) []models.WebhookDelivery {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for i := range r.deliveries {
+		if match(&r.deliveries[i]) {
+			deliveries = append(deliveries, copyWebhookDelivery(&r.deliveries[i]))
+		}
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return less(&deliveries[i], &deliveries[j])
+	})
+	return deliveries
+}
+
+// Fungsi bantu untuk menyalin pengiriman webhook beserta riwayat percobaannya agar perubahan di luar repository
+// tidak mengubah data yang tersimpan
+func copyWebhookDelivery(delivery *models.WebhookDelivery) models.WebhookDelivery {
+	result := *delivery
+	result.Attempts = append([]models.WebhookAttempt(nil), delivery.Attempts...)
+	return result
+}
+
+// Fungsi bantu untuk menyimpan data pengiriman webhook ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryWebhookDeliveryRepository) saveToFile() error {
+	data, err := json.Marshal(r.deliveries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook delivery data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write webhook delivery data to file: %v", err)
+	}
+
+	return nil
+}
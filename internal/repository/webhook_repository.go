@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// ErrWebhookConfigNotFound dikembalikan ketika merchant belum memiliki pengaturan webhook
+var ErrWebhookConfigNotFound = errors.New("webhook config not found")
+
+// Mendefinisikan interface WebhookRepository yang menyediakan method-method
+type WebhookRepository interface {
+	Save(config *models.WebhookConfig) error
+	GetByMerchantID(merchantID string) (*models.WebhookConfig, error)
+}
+
+// InMemoryWebhookRepository menyimpan pengaturan webhook merchant di memori dan menuliskannya ke file JSON
+type InMemoryWebhookRepository struct {
+	mu       sync.RWMutex
+	filePath string
+	configs  []models.WebhookConfig
+}
+
+// NewInMemoryWebhookRepository membuat instance baru dari InMemoryWebhookRepository
+func NewInMemoryWebhookRepository(filePath string) (*InMemoryWebhookRepository, error) {
+	// Membaca file yang berisi pengaturan webhook, file yang belum ada dianggap kosong
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read webhook data: %v", err)
+	}
+
+	var configs []models.WebhookConfig
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &configs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook data: %v", err)
+		}
+	}
+
+	return &InMemoryWebhookRepository{
+		filePath: filePath,
+		configs:  configs,
+	}, nil
+}
+
+// Save menyimpan pengaturan webhook merchant baru atau memperbarui yang sudah ada
+func (r *InMemoryWebhookRepository) Save(config *models.WebhookConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.configs
+	updated := false
+	r.configs = make([]models.WebhookConfig, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.MerchantID == config.MerchantID {
+			existing = copyWebhookConfig(config)
+			updated = true
+		}
+		r.configs = append(r.configs, existing)
+	}
+	if !updated {
+		r.configs = append(r.configs, copyWebhookConfig(config))
+	}
+
+	err := r.saveToFile()
+	if err != nil {
+		r.configs = previous
+		return err
+	}
+	return nil
+}
+
+// GetByMerchantID mengambil salinan pengaturan webhook merchant
+func (r *InMemoryWebhookRepository) GetByMerchantID(merchantID string) (*models.WebhookConfig, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, config := range r.configs {
+		if config.MerchantID == merchantID {
+			result := copyWebhookConfig(&config)
+			return &result, nil
+		}
+	}
+
+	return nil, ErrWebhookConfigNotFound
+}
+
+// Fungsi bantu untuk menyalin pengaturan webhook beserta endpointnya agar perubahan di luar repository
+// tidak mengubah data yang tersimpan
+func copyWebhookConfig(config *models.WebhookConfig) models.WebhookConfig {
+	result := *config
+	result.Endpoints = make([]models.WebhookEndpoint, len(config.Endpoints))
+	for i, endpoint := range config.Endpoints {
+		endpoint.Events = append([]string(nil), endpoint.Events...)
+		result.Endpoints[i] = endpoint
+	}
+	return result
+}
+
+// Fungsi bantu untuk menyimpan pengaturan webhook ke file.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (r *InMemoryWebhookRepository) saveToFile() error {
+	data, err := json.Marshal(r.configs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook data: %v", err)
+	}
+
+	err = ioutil.WriteFile(r.filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write webhook data to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// Config berisi pengaturan aturan risiko bawaan. Aturan yang tidak diisi tidak dijalankan.
+type Config struct {
+	Velocity         *VelocityRule        `json:"velocity,omitempty"`
+	AmountSpike      *AmountSpikeRule     `json:"amount_spike,omitempty"`
+	NewDevice        *NewDeviceRule       `json:"new_device,omitempty"`
+	BlockedMerchants *BlockedMerchantRule `json:"blocked_merchants,omitempty"`
+}
+
+// LoadConfig membaca pengaturan aturan risiko dari file JSON
+func LoadConfig(filePath string) (*Config, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read risk rule data: %v", err)
+	}
+
+	var config Config
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal risk rule data: %v", err)
+	}
+
+	return &config, config.validate()
+}
+
+// Rules mengembalikan aturan risiko yang diisi pada pengaturan
+func (c *Config) Rules() []Rule {
+	rules := make([]Rule, 0, 4)
+	if c.Velocity != nil {
+		rules = append(rules, c.Velocity)
+	}
+	if c.AmountSpike != nil {
+		rules = append(rules, c.AmountSpike)
+	}
+	if c.NewDevice != nil {
+		rules = append(rules, c.NewDevice)
+	}
+	if c.BlockedMerchants != nil {
+		rules = append(rules, c.BlockedMerchants)
+	}
+	return rules
+}
+
+// Fungsi bantu untuk memastikan keputusan setiap aturan valid
+func (c *Config) validate() error {
+	decisions := map[string]string{}
+	if c.Velocity != nil {
+		decisions["velocity"] = c.Velocity.Decision
+	}
+	if c.AmountSpike != nil {
+		decisions["amount_spike"] = c.AmountSpike.Decision
+	}
+	if c.NewDevice != nil {
+		decisions["new_device"] = c.NewDevice.Decision
+	}
+	if c.BlockedMerchants != nil {
+		decisions["blocked_merchants"] = c.BlockedMerchants.Decision
+	}
+
+	for rule, decision := range decisions {
+		switch decision {
+		case "", models.RiskDecisionAllow, models.RiskDecisionReview, models.RiskDecisionDeny:
+		default:
+			return fmt.Errorf("invalid decision %q for risk rule %s", decision, rule)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package risk
+
+import (
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Attempt adalah percobaan pembayaran yang diperiksa oleh aturan risiko. Amount dalam mata uang wallet
+// pelanggan dan History berisi pembayaran pelanggan sebelumnya dengan status apa pun.
+type Attempt struct {
+	CustomerID string
+	MerchantID string
+	Amount     money.Money
+	DeviceID   string
+	At         time.Time
+	History    []models.Transaction
+}
+
+// Rule adalah aturan risiko yang dapat ditambahkan ke Engine
+type Rule interface {
+	// Name mengembalikan nama unik aturan, misalnya "velocity"
+	Name() string
+	// Evaluate memeriksa percobaan pembayaran dan mengembalikan alasan jika pembayaran tidak diizinkan
+	// begitu saja, atau nil jika aturan terpenuhi
+	Evaluate(attempt Attempt) *models.RiskReason
+}
+
+// Assessment adalah hasil pemeriksaan risiko. Decision adalah keputusan paling berat dari seluruh alasan.
+type Assessment struct {
+	Decision string              `json:"decision"`
+	Reasons  []models.RiskReason `json:"reasons,omitempty"`
+}
+
+// Engine menjalankan seluruh aturan risiko yang terdaftar terhadap percobaan pembayaran
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine membuat instance baru dari Engine dengan aturan yang diberikan
+func NewEngine(rules ...Rule) *Engine {
+	engine := &Engine{}
+	for _, rule := range rules {
+		engine.Register(rule)
+	}
+	return engine
+}
+
+// Register menambahkan aturan ke dalam engine, aturan dengan nama yang sama akan diganti
+func (e *Engine) Register(rule Rule) {
+	for i, existing := range e.rules {
+		if existing.Name() == rule.Name() {
+			e.rules[i] = rule
+			return
+		}
+	}
+	e.rules = append(e.rules, rule)
+}
+
+// Evaluate menjalankan seluruh aturan dan menggabungkan alasannya menjadi satu keputusan
+func (e *Engine) Evaluate(attempt Attempt) Assessment {
+	assessment := Assessment{Decision: models.RiskDecisionAllow}
+	for _, rule := range e.rules {
+		reason := rule.Evaluate(attempt)
+		if reason == nil {
+			continue
+		}
+		if reason.Rule == "" {
+			reason.Rule = rule.Name()
+		}
+		assessment.Reasons = append(assessment.Reasons, *reason)
+		if severity(reason.Decision) > severity(assessment.Decision) {
+			assessment.Decision = reason.Decision
+		}
+	}
+	return assessment
+}
+
+// Fungsi bantu untuk mengurutkan keputusan risiko, keputusan yang tidak dikenal dianggap allow
+func severity(decision string) int {
+	switch decision {
+	case models.RiskDecisionReview:
+		return 1
+	case models.RiskDecisionDeny:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,175 @@
+package risk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// VelocityRule membatasi jumlah pembayaran pelanggan dalam jangka waktu tertentu. Seluruh percobaan pembayaran
+// yang tersimpan dihitung, termasuk yang gagal.
+type VelocityRule struct {
+	MaxPayments   int    `json:"max_payments"`
+	WindowMinutes int    `json:"window_minutes"`
+	Decision      string `json:"decision,omitempty"`
+}
+
+// Name mengembalikan nama aturan
+func (r *VelocityRule) Name() string {
+	return "velocity"
+}
+
+// Evaluate memeriksa apakah pembayaran ini melebihi MaxPayments dalam WindowMinutes terakhir
+func (r *VelocityRule) Evaluate(attempt Attempt) *models.RiskReason {
+	if r.MaxPayments <= 0 || r.WindowMinutes <= 0 {
+		return nil
+	}
+
+	since := attempt.At.Add(-time.Duration(r.WindowMinutes) * time.Minute)
+	count := 1
+	for _, transaction := range attempt.History {
+		if transaction.CreatedAt.After(since) {
+			count++
+		}
+	}
+	if count <= r.MaxPayments {
+		return nil
+	}
+
+	return &models.RiskReason{
+		Decision: decisionOrDefault(r.Decision, models.RiskDecisionDeny),
+		Message:  fmt.Sprintf("lebih dari %d pembayaran dalam %d menit", r.MaxPayments, r.WindowMinutes),
+	}
+}
+
+// AmountSpikeRule menandai pembayaran yang jauh lebih besar dari rata-rata pembayaran berhasil pelanggan.
+// Aturan baru berlaku setelah pelanggan memiliki paling sedikit MinHistory pembayaran berhasil.
+type AmountSpikeRule struct {
+	Multiplier int64  `json:"multiplier"`
+	MinHistory int    `json:"min_history"`
+	Decision   string `json:"decision,omitempty"`
+}
+
+// Name mengembalikan nama aturan
+func (r *AmountSpikeRule) Name() string {
+	return "amount_spike"
+}
+
+// Evaluate memeriksa apakah jumlah pembayaran melebihi Multiplier kali rata-rata pembayaran sebelumnya
+func (r *AmountSpikeRule) Evaluate(attempt Attempt) *models.RiskReason {
+	if r.Multiplier <= 0 {
+		return nil
+	}
+
+	total := money.Zero(attempt.Amount.Currency())
+	count := int64(0)
+	for _, transaction := range attempt.History {
+		if !succeeded(&transaction) || transaction.Amount.Currency() != total.Currency() {
+			continue
+		}
+		var err error
+		total, err = total.Add(transaction.Amount)
+		if err != nil {
+			return nil
+		}
+		count++
+	}
+	if count == 0 || count < int64(r.MinHistory) {
+		return nil
+	}
+
+	average, err := total.MulRat(1, count)
+	if err != nil {
+		return nil
+	}
+	threshold, err := average.Mul(r.Multiplier)
+	if err != nil {
+		return nil
+	}
+	cmp, err := attempt.Amount.Cmp(threshold)
+	if err != nil || cmp <= 0 {
+		return nil
+	}
+
+	return &models.RiskReason{
+		Decision: decisionOrDefault(r.Decision, models.RiskDecisionReview),
+		Message:  fmt.Sprintf("jumlah %s lebih dari %d kali rata-rata pembayaran %s", attempt.Amount, r.Multiplier, average),
+	}
+}
+
+// NewDeviceRule menandai pembayaran besar dari perangkat yang belum pernah digunakan pelanggan untuk pembayaran
+// yang berhasil. Pembayaran tanpa ID perangkat tidak diperiksa.
+type NewDeviceRule struct {
+	Threshold money.Money `json:"threshold"`
+	Decision  string      `json:"decision,omitempty"`
+}
+
+// Name mengembalikan nama aturan
+func (r *NewDeviceRule) Name() string {
+	return "new_device"
+}
+
+// Evaluate memeriksa apakah pembayaran dari perangkat baru mencapai Threshold
+func (r *NewDeviceRule) Evaluate(attempt Attempt) *models.RiskReason {
+	if attempt.DeviceID == "" || !r.Threshold.IsPositive() {
+		return nil
+	}
+	cmp, err := attempt.Amount.Cmp(r.Threshold)
+	if err != nil || cmp < 0 {
+		return nil
+	}
+	for _, transaction := range attempt.History {
+		if transaction.DeviceID == attempt.DeviceID && succeeded(&transaction) {
+			return nil
+		}
+	}
+
+	return &models.RiskReason{
+		Decision: decisionOrDefault(r.Decision, models.RiskDecisionReview),
+		Message:  fmt.Sprintf("pembayaran %s dari perangkat baru %s", attempt.Amount, attempt.DeviceID),
+	}
+}
+
+// BlockedMerchantRule menolak pembayaran ke merchant yang diblokir
+type BlockedMerchantRule struct {
+	MerchantIDs []string `json:"merchant_ids"`
+	Decision    string   `json:"decision,omitempty"`
+}
+
+// Name mengembalikan nama aturan
+func (r *BlockedMerchantRule) Name() string {
+	return "blocked_merchant"
+}
+
+// Evaluate memeriksa apakah merchant tujuan pembayaran diblokir
+func (r *BlockedMerchantRule) Evaluate(attempt Attempt) *models.RiskReason {
+	for _, merchantID := range r.MerchantIDs {
+		if merchantID == attempt.MerchantID {
+			return &models.RiskReason{
+				Decision: decisionOrDefault(r.Decision, models.RiskDecisionDeny),
+				Message:  fmt.Sprintf("merchant %s diblokir", attempt.MerchantID),
+			}
+		}
+	}
+	return nil
+}
+
+// Fungsi bantu untuk memeriksa apakah pembayaran berhasil, yaitu sudah di-capture termasuk yang kemudian direfund.
+// Transaksi lama tanpa status dianggap sudah captured.
+func succeeded(transaction *models.Transaction) bool {
+	switch transaction.Status {
+	case "", models.TransactionStatusCaptured, models.TransactionStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// Fungsi bantu untuk menggunakan keputusan default jika keputusan aturan tidak diisi
+func decisionOrDefault(decision string, fallback string) string {
+	if decision == "" {
+		return fallback
+	}
+	return decision
+}
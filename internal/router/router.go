@@ -3,12 +3,20 @@ package router
 import (
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/IbnuFarhanS/Golang_MNC/internal/controller"
 	"github.com/IbnuFarhanS/Golang_MNC/middleware"
 	"github.com/gorilla/mux"
 )
 
+// IdempotencyKeyTTL adalah lama respons dengan Idempotency-Key disimpan
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyKeyLease adalah lama Idempotency-Key ditahan selama permintaannya masih diproses. Key dari permintaan
+// yang terhenti sebelum respons disimpan dapat digunakan kembali setelah lease berakhir.
+const IdempotencyKeyLease = time.Minute
+
 // Router mewakili router HTTP
 type Router struct {
 	router *mux.Router
@@ -47,11 +55,208 @@ func (r *Router) RegisterTransactionRoutes(transactionController *controller.Tra
 	// Menerapkan AuthMiddleware ke subrouter transaksi
 	subrouter.Use(middleware.AuthMiddleware(transactionController.CustomerRepo))
 
+	// Pembuatan pembayaran mendukung header Idempotency-Key agar aman dicoba ulang oleh klien
+	idempotency := middleware.IdempotencyMiddleware(transactionController.IdempotencyRepo, IdempotencyKeyTTL, IdempotencyKeyLease)
+
 	// Mendaftarkan rute transaksi
-	subrouter.HandleFunc("", transactionController.ProcessTransaction).Methods(http.MethodPost)
+	subrouter.Handle("", idempotency(http.HandlerFunc(transactionController.ProcessTransaction))).Methods(http.MethodPost)
+	subrouter.HandleFunc("", transactionController.ListTransactions).Methods(http.MethodGet)
+	subrouter.Handle("/qr", idempotency(http.HandlerFunc(transactionController.PayQR))).Methods(http.MethodPost)
+	subrouter.Handle("/authorize", idempotency(http.HandlerFunc(transactionController.AuthorizeTransaction))).Methods(http.MethodPost)
+	subrouter.HandleFunc("/{id}/receipt", transactionController.GetReceipt).Methods(http.MethodGet)
+
+	// Membuat subrouter baru untuk rute transaksi merchant
+	merchantSubrouter := r.router.PathPrefix("/merchant/transactions").Subrouter()
+
+	// Menerapkan MerchantAuthMiddleware ke subrouter transaksi merchant
+	merchantSubrouter.Use(middleware.MerchantAuthMiddleware(transactionController.MerchantRepo))
+
+	// Capture, void, dan refund hanya dapat diminta oleh merchant penerima pembayaran
+	merchantSubrouter.HandleFunc("/{id}/capture", transactionController.CaptureTransaction).Methods(http.MethodPost)
+	merchantSubrouter.HandleFunc("/{id}/void", transactionController.VoidTransaction).Methods(http.MethodPost)
+	merchantSubrouter.HandleFunc("/{id}/refund", transactionController.RefundTransaction).Methods(http.MethodPost)
+
+	// Kode verifikasi struk dapat diperiksa tanpa autentikasi
+	r.router.HandleFunc("/receipts/verify", transactionController.VerifyReceipt).Methods(http.MethodGet)
 	log.Println("Rute transaksi terdaftar.")
 }
 
+// RegisterWalletRoutes mendaftarkan rute terkait wallet pelanggan
+func (r *Router) RegisterWalletRoutes(walletController *controller.WalletController) {
+	log.Println("Mendaftarkan rute wallet...")
+	// Membuat subrouter baru untuk rute wallet di bawah prefix pelanggan
+	subrouter := r.router.PathPrefix("/customer").Subrouter()
+
+	// Menerapkan AuthMiddleware ke subrouter wallet
+	subrouter.Use(middleware.AuthMiddleware(walletController.CustomerRepo))
+
+	// Mendaftarkan rute wallet
+	subrouter.HandleFunc("/wallet", walletController.GetWallet).Methods(http.MethodGet)
+	subrouter.HandleFunc("/ledger", walletController.GetLedger).Methods(http.MethodGet)
+	subrouter.HandleFunc("/topup", walletController.TopUp).Methods(http.MethodPost)
+	subrouter.HandleFunc("/transfer", walletController.Transfer).Methods(http.MethodPost)
+	log.Println("Rute wallet terdaftar.")
+}
+
+// RegisterScheduleRoutes mendaftarkan rute pembayaran terjadwal pelanggan
+func (r *Router) RegisterScheduleRoutes(scheduleController *controller.ScheduleController) {
+	log.Println("Mendaftarkan rute pembayaran terjadwal...")
+	// Membuat subrouter baru untuk rute pembayaran terjadwal di bawah prefix pelanggan
+	subrouter := r.router.PathPrefix("/customer/schedules").Subrouter()
+
+	// Menerapkan AuthMiddleware ke subrouter pembayaran terjadwal
+	subrouter.Use(middleware.AuthMiddleware(scheduleController.CustomerRepo))
+
+	// Mendaftarkan rute pembayaran terjadwal
+	subrouter.HandleFunc("", scheduleController.CreateSchedule).Methods(http.MethodPost)
+	subrouter.HandleFunc("", scheduleController.ListSchedules).Methods(http.MethodGet)
+	subrouter.HandleFunc("/{id}", scheduleController.GetSchedule).Methods(http.MethodGet)
+	subrouter.HandleFunc("/{id}", scheduleController.CancelSchedule).Methods(http.MethodDelete)
+	log.Println("Rute pembayaran terjadwal terdaftar.")
+}
+
+// RegisterBillRoutes mendaftarkan rute split bill pelanggan
+func (r *Router) RegisterBillRoutes(billController *controller.BillController) {
+	log.Println("Mendaftarkan rute split bill...")
+	// Membuat subrouter baru untuk rute split bill di bawah prefix pelanggan
+	subrouter := r.router.PathPrefix("/customer/bills").Subrouter()
+
+	// Menerapkan AuthMiddleware ke subrouter split bill
+	subrouter.Use(middleware.AuthMiddleware(billController.CustomerRepo))
+
+	// Mendaftarkan rute split bill
+	subrouter.HandleFunc("", billController.CreateBill).Methods(http.MethodPost)
+	subrouter.HandleFunc("", billController.ListBills).Methods(http.MethodGet)
+	subrouter.HandleFunc("/{id}", billController.GetBill).Methods(http.MethodGet)
+	subrouter.HandleFunc("/{id}/pay", billController.PayShare).Methods(http.MethodPost)
+	subrouter.HandleFunc("/{id}/cancel", billController.CancelBill).Methods(http.MethodPost)
+	log.Println("Rute split bill terdaftar.")
+}
+
+// RegisterLoyaltyRoutes mendaftarkan rute poin loyalitas pelanggan
+func (r *Router) RegisterLoyaltyRoutes(loyaltyController *controller.LoyaltyController) {
+	log.Println("Mendaftarkan rute poin loyalitas...")
+	// Membuat subrouter baru untuk rute poin loyalitas di bawah prefix pelanggan
+	subrouter := r.router.PathPrefix("/customer/points").Subrouter()
+
+	// Menerapkan AuthMiddleware ke subrouter poin loyalitas
+	subrouter.Use(middleware.AuthMiddleware(loyaltyController.CustomerRepo))
+
+	// Mendaftarkan rute poin loyalitas
+	subrouter.HandleFunc("", loyaltyController.GetBalance).Methods(http.MethodGet)
+	subrouter.HandleFunc("/history", loyaltyController.GetHistory).Methods(http.MethodGet)
+	log.Println("Rute poin loyalitas terdaftar.")
+}
+
+// RegisterStatementRoutes mendaftarkan rute laporan rekening bulanan pelanggan
+func (r *Router) RegisterStatementRoutes(statementController *controller.StatementController) {
+	log.Println("Mendaftarkan rute laporan rekening...")
+	// Membuat subrouter baru untuk rute laporan rekening di bawah prefix pelanggan
+	subrouter := r.router.PathPrefix("/customer/statements").Subrouter()
+
+	// Menerapkan AuthMiddleware ke subrouter laporan rekening
+	subrouter.Use(middleware.AuthMiddleware(statementController.CustomerRepo))
+
+	// Mendaftarkan rute laporan rekening
+	subrouter.HandleFunc("/{period}", statementController.GetStatement).Methods(http.MethodGet)
+	log.Println("Rute laporan rekening terdaftar.")
+}
+
+// RegisterInvoiceRoutes mendaftarkan rute invoice untuk merchant dan pelanggan
+func (r *Router) RegisterInvoiceRoutes(invoiceController *controller.InvoiceController) {
+	log.Println("Mendaftarkan rute invoice...")
+	// Membuat subrouter baru untuk rute invoice merchant
+	merchantSubrouter := r.router.PathPrefix("/merchant/invoices").Subrouter()
+
+	// Menerapkan MerchantAuthMiddleware ke subrouter invoice merchant
+	merchantSubrouter.Use(middleware.MerchantAuthMiddleware(invoiceController.MerchantRepo))
+
+	// Mendaftarkan rute invoice merchant
+	merchantSubrouter.HandleFunc("", invoiceController.CreateInvoice).Methods(http.MethodPost)
+	merchantSubrouter.HandleFunc("", invoiceController.ListMerchantInvoices).Methods(http.MethodGet)
+	merchantSubrouter.HandleFunc("/{id}", invoiceController.GetMerchantInvoice).Methods(http.MethodGet)
+	merchantSubrouter.HandleFunc("/{id}/cancel", invoiceController.CancelInvoice).Methods(http.MethodPost)
+
+	// Membuat subrouter baru untuk rute invoice di bawah prefix pelanggan
+	customerSubrouter := r.router.PathPrefix("/customer/invoices").Subrouter()
+
+	// Menerapkan AuthMiddleware ke subrouter invoice pelanggan
+	customerSubrouter.Use(middleware.AuthMiddleware(invoiceController.CustomerRepo))
+
+	// Mendaftarkan rute invoice pelanggan
+	customerSubrouter.HandleFunc("", invoiceController.ListCustomerInvoices).Methods(http.MethodGet)
+	customerSubrouter.HandleFunc("/{id}", invoiceController.GetCustomerInvoice).Methods(http.MethodGet)
+	customerSubrouter.HandleFunc("/{id}/pay", invoiceController.PayInvoice).Methods(http.MethodPost)
+	log.Println("Rute invoice terdaftar.")
+}
+
+// RegisterQRRoutes mendaftarkan rute pembuatan QR pembayaran merchant
+func (r *Router) RegisterQRRoutes(qrController *controller.QRController) {
+	log.Println("Mendaftarkan rute QR merchant...")
+	// Membuat subrouter baru untuk rute QR merchant
+	subrouter := r.router.PathPrefix("/merchant/qr").Subrouter()
+
+	// Menerapkan MerchantAuthMiddleware ke subrouter QR merchant
+	subrouter.Use(middleware.MerchantAuthMiddleware(qrController.MerchantRepo))
+
+	// Mendaftarkan rute QR merchant
+	subrouter.HandleFunc("", qrController.GenerateQR).Methods(http.MethodPost)
+	log.Println("Rute QR merchant terdaftar.")
+}
+
+// RegisterWebhookRoutes mendaftarkan rute pengelolaan webhook merchant
+func (r *Router) RegisterWebhookRoutes(webhookController *controller.WebhookController) {
+	log.Println("Mendaftarkan rute webhook merchant...")
+	// Membuat subrouter baru untuk rute webhook merchant
+	subrouter := r.router.PathPrefix("/merchant/webhooks").Subrouter()
+
+	// Menerapkan MerchantAuthMiddleware ke subrouter webhook merchant
+	subrouter.Use(middleware.MerchantAuthMiddleware(webhookController.MerchantRepo))
+
+	// Mendaftarkan rute webhook merchant
+	subrouter.HandleFunc("", webhookController.RegisterEndpoint).Methods(http.MethodPost)
+	subrouter.HandleFunc("", webhookController.GetConfig).Methods(http.MethodGet)
+	subrouter.HandleFunc("/secret", webhookController.RotateSecret).Methods(http.MethodPost)
+	subrouter.HandleFunc("/deliveries", webhookController.ListDeliveries).Methods(http.MethodGet)
+	subrouter.HandleFunc("/deliveries/{id}", webhookController.GetDelivery).Methods(http.MethodGet)
+	subrouter.HandleFunc("/deliveries/{id}/redeliver", webhookController.Redeliver).Methods(http.MethodPost)
+	subrouter.HandleFunc("/{id}", webhookController.DeleteEndpoint).Methods(http.MethodDelete)
+	log.Println("Rute webhook merchant terdaftar.")
+}
+
+// RegisterAdminRoutes mendaftarkan rute pengelolaan yang hanya dapat diakses oleh admin
+func (r *Router) RegisterAdminRoutes(adminController *controller.AdminController) {
+	log.Println("Mendaftarkan rute admin...")
+	// Membuat subrouter baru untuk rute admin
+	subrouter := r.router.PathPrefix("/admin").Subrouter()
+
+	// Menerapkan AuthMiddleware lalu AdminMiddleware ke subrouter admin
+	subrouter.Use(middleware.AuthMiddleware(adminController.CustomerRepo))
+	subrouter.Use(middleware.AdminMiddleware(adminController.CustomerRepo))
+
+	// Mendaftarkan rute batas pengeluaran
+	subrouter.HandleFunc("/limits", adminController.GetLimits).Methods(http.MethodGet)
+	subrouter.HandleFunc("/limits/tiers/{tier}", adminController.SetTierLimit).Methods(http.MethodPut)
+	subrouter.HandleFunc("/limits/customers/{id}", adminController.GetCustomerLimit).Methods(http.MethodGet)
+	subrouter.HandleFunc("/limits/customers/{id}", adminController.SetCustomerLimit).Methods(http.MethodPut)
+	subrouter.HandleFunc("/limits/customers/{id}", adminController.DeleteCustomerLimit).Methods(http.MethodDelete)
+	subrouter.HandleFunc("/customers/{id}/tier", adminController.SetCustomerTier).Methods(http.MethodPut)
+	subrouter.HandleFunc("/settlements", adminController.CreateSettlements).Methods(http.MethodPost)
+	subrouter.HandleFunc("/settlements", adminController.ListSettlements).Methods(http.MethodGet)
+	subrouter.HandleFunc("/settlements/{id}", adminController.GetSettlement).Methods(http.MethodGet)
+	subrouter.HandleFunc("/settlements/{id}/export", adminController.ExportSettlement).Methods(http.MethodGet)
+	subrouter.HandleFunc("/settlements/{id}/paid", adminController.MarkSettlementPaid).Methods(http.MethodPost)
+	subrouter.HandleFunc("/promotions", adminController.CreatePromotion).Methods(http.MethodPost)
+	subrouter.HandleFunc("/promotions", adminController.ListPromotions).Methods(http.MethodGet)
+	subrouter.HandleFunc("/promotions/{id}", adminController.GetPromotion).Methods(http.MethodGet)
+	subrouter.HandleFunc("/promotions/{id}", adminController.DeactivatePromotion).Methods(http.MethodDelete)
+	subrouter.HandleFunc("/loyalty", adminController.GetLoyalty).Methods(http.MethodGet)
+	subrouter.HandleFunc("/loyalty/merchants/{id}", adminController.SetMerchantEarnRate).Methods(http.MethodPut)
+	subrouter.HandleFunc("/loyalty/merchants/{id}", adminController.DeleteMerchantEarnRate).Methods(http.MethodDelete)
+	subrouter.HandleFunc("/risk/events", adminController.ListRiskEvents).Methods(http.MethodGet)
+	log.Println("Rute admin terdaftar.")
+}
+
 // GetHandler mengembalikan handler HTTP
 func (r *Router) GetHandler() http.Handler {
 	return r.router
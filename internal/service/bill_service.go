@@ -0,0 +1,400 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// DefaultBillExpiry adalah lama default split bill menunggu seluruh bagian dibayar
+const DefaultBillExpiry = 24 * time.Hour
+
+// BillShareRequest adalah bagian peserta yang diminta saat membuat split bill.
+// Customer berisi username atau nomor telepon peserta.
+type BillShareRequest struct {
+	Customer string      `json:"customer"`
+	Amount   money.Money `json:"amount"`
+}
+
+// BillService menangani split bill: tagihan merchant yang dibayar bersama oleh beberapa pelanggan
+type BillService struct {
+	billRepository     repository.BillRepository
+	customerRepository repository.CustomerRepository
+	merchantRepository repository.MerchantRepository
+	transactionService *TransactionService
+
+	// mu memastikan pembayaran bagian, pembatalan, dan kedaluwarsa bill tidak saling mendahului
+	mu sync.Mutex
+}
+
+// NewBillService membuat instance baru dari BillService
+func NewBillService(billRepository repository.BillRepository, customerRepository repository.CustomerRepository, merchantRepository repository.MerchantRepository, transactionService *TransactionService) *BillService {
+	return &BillService{
+		billRepository:     billRepository,
+		customerRepository: customerRepository,
+		merchantRepository: merchantRepository,
+		transactionService: transactionService,
+	}
+}
+
+// CreateBill membuat split bill untuk merchant dengan bagian untuk setiap peserta.
+// Pembuat bill boleh termasuk peserta. expiresAt kosong berarti DefaultBillExpiry dari sekarang.
+func (s *BillService) CreateBill(creatorID string, merchantID string, description string, shares []BillShareRequest, expiresAt time.Time) (*models.Bill, error) {
+	log.Println("Membuat split bill...")
+
+	merchant, err := s.merchantRepository.GetByID(merchantID)
+	if err != nil {
+		return nil, errors.New("ID merchant tidak valid")
+	}
+	if len(shares) == 0 {
+		return nil, errors.New("split bill harus memiliki minimal satu peserta")
+	}
+
+	now := time.Now()
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(DefaultBillExpiry)
+	}
+	if !expiresAt.After(now) {
+		return nil, errors.New("waktu kedaluwarsa harus di masa depan")
+	}
+
+	// Bagian peserta harus dalam mata uang merchant
+	currency := money.Zero(merchant.Currency).Currency()
+	bill := &models.Bill{
+		ID:          "BILL" + generateTransactionID(),
+		CreatorID:   creatorID,
+		MerchantID:  merchant.ID,
+		Description: description,
+		Amount:      money.Zero(currency),
+		Shares:      make([]models.BillShare, 0, len(shares)),
+		Status:      models.BillStatusOpen,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	participants := make(map[string]bool, len(shares))
+	for _, share := range shares {
+		customer, err := findCustomer(s.customerRepository, share.Customer)
+		if err != nil {
+			return nil, fmt.Errorf("peserta %s tidak ditemukan", share.Customer)
+		}
+		if participants[customer.ID] {
+			return nil, fmt.Errorf("peserta %s tercantum lebih dari satu kali", share.Customer)
+		}
+		participants[customer.ID] = true
+
+		if !share.Amount.IsPositive() {
+			return nil, fmt.Errorf("bagian peserta %s harus lebih dari nol", share.Customer)
+		}
+		if share.Amount.Currency() != currency {
+			return nil, fmt.Errorf("merchant hanya menerima pembayaran dalam %s", currency)
+		}
+		bill.Amount, err = bill.Amount.Add(share.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		bill.Shares = append(bill.Shares, models.BillShare{
+			CustomerID: customer.ID,
+			Amount:     share.Amount,
+			Status:     models.BillShareStatusPending,
+		})
+	}
+
+	err = s.billRepository.Save(bill)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan split bill: %w", err)
+	}
+
+	log.Println("Split bill berhasil dibuat.")
+
+	return bill, nil
+}
+
+// GetBills mengambil split bill yang dibuat atau diikuti pelanggan
+func (s *BillService) GetBills(customerID string) ([]models.Bill, error) {
+	bills, err := s.billRepository.GetByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan split bill: %w", err)
+	}
+	return bills, nil
+}
+
+// GetBill mengambil split bill yang dibuat atau diikuti pelanggan
+func (s *BillService) GetBill(customerID string, billID string) (*models.Bill, error) {
+	bill, err := s.billRepository.GetByID(billID)
+	if err != nil {
+		return nil, errors.New("ID split bill tidak valid")
+	}
+	if bill.CreatorID != customerID && findShare(bill, customerID) == nil {
+		return nil, errors.New("pelanggan bukan peserta split bill")
+	}
+	return bill, nil
+}
+
+// PayShare menyetujui dan membayar bagian pelanggan dengan menahan saldonya sampai bill kedaluwarsa.
+// Ketika seluruh bagian sudah dibayar, semua bagian di-capture sebagai pembayaran ke merchant.
+// deviceID adalah perangkat yang digunakan untuk membayar dan diperiksa oleh pemeriksaan risiko.
+func (s *BillService) PayShare(customerID string, billID string, deviceID string) (*models.Bill, error) {
+	log.Println("Membayar bagian split bill...")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bill, err := s.GetBill(customerID, billID)
+	if err != nil {
+		return nil, err
+	}
+	if bill.Status != models.BillStatusOpen {
+		return nil, fmt.Errorf("split bill dengan status %s tidak dapat dibayar", bill.Status)
+	}
+	now := time.Now()
+	if !now.Before(bill.ExpiresAt) {
+		return nil, errors.New("split bill sudah kedaluwarsa")
+	}
+	share := findShare(bill, customerID)
+	if share == nil {
+		return nil, errors.New("pelanggan tidak memiliki bagian pada split bill")
+	}
+	if share.Status != models.BillShareStatusPending {
+		return nil, errors.New("bagian pelanggan sudah dibayar")
+	}
+
+	transaction, err := s.transactionService.AuthorizeBillShare(customerID, bill.MerchantID, share.Amount, bill.ID, bill.ExpiresAt, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	share.Status = models.BillShareStatusPaid
+	share.TransactionID = transaction.ID
+	share.PaidAt = &now
+	bill.UpdatedAt = now
+	if allSharesPaid(bill) {
+		bill.Status = models.BillStatusCapturing
+	}
+	err = s.billRepository.Save(bill)
+	if err != nil {
+		// Saldo yang sudah ditahan dikembalikan karena bagian gagal dicatat
+		_, releaseErr := s.transactionService.ReleaseBillShare(transaction.ID, "split bill gagal disimpan")
+		if releaseErr != nil {
+			log.Println("Gagal melepas bagian split bill", transaction.ID, ":", releaseErr)
+		}
+		return nil, fmt.Errorf("gagal menyimpan split bill: %w", err)
+	}
+
+	if bill.Status == models.BillStatusCapturing {
+		err = s.captureShares(bill)
+		if err != nil {
+			// Bagian yang belum di-capture dicoba lagi oleh sweeper
+			log.Println("Gagal meng-capture split bill", bill.ID, ":", err)
+		}
+	}
+
+	log.Println("Bagian split bill berhasil dibayar.")
+
+	return bill, nil
+}
+
+// CancelBill membatalkan split bill yang belum lunas dan mengembalikan bagian yang sudah dibayar.
+// Hanya pembuat bill yang dapat membatalkannya.
+func (s *BillService) CancelBill(customerID string, billID string) (*models.Bill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bill, err := s.GetBill(customerID, billID)
+	if err != nil {
+		return nil, err
+	}
+	if bill.CreatorID != customerID {
+		return nil, errors.New("hanya pembuat split bill yang dapat membatalkannya")
+	}
+	if bill.Status != models.BillStatusOpen {
+		return nil, fmt.Errorf("split bill dengan status %s tidak dapat dibatalkan", bill.Status)
+	}
+
+	err = s.closeBill(bill, models.BillStatusCancelled, "split bill dibatalkan")
+	if err != nil {
+		return nil, err
+	}
+
+	return bill, nil
+}
+
+// ProcessBills membatalkan split bill yang sudah kedaluwarsa dengan mengembalikan bagian yang sudah dibayar,
+// lalu mencoba ulang capture split bill yang sudah lunas namun belum selesai di-capture
+func (s *BillService) ProcessBills(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	open, err := s.billRepository.GetByStatus(models.BillStatusOpen)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan split bill: %w", err)
+	}
+
+	expired := 0
+	for i := range open {
+		bill := &open[i]
+		if now.Before(bill.ExpiresAt) {
+			continue
+		}
+		err = s.closeBill(bill, models.BillStatusExpired, "split bill kedaluwarsa")
+		if err != nil {
+			log.Println("Gagal membatalkan split bill kedaluwarsa", bill.ID, ":", err)
+			continue
+		}
+		expired++
+	}
+
+	capturing, err := s.billRepository.GetByStatus(models.BillStatusCapturing)
+	if err != nil {
+		return expired, fmt.Errorf("gagal mendapatkan split bill: %w", err)
+	}
+	for i := range capturing {
+		err = s.captureShares(&capturing[i])
+		if err != nil {
+			log.Println("Gagal meng-capture split bill", capturing[i].ID, ":", err)
+		}
+	}
+
+	return expired, nil
+}
+
+// StartSweeper menjalankan ProcessBills secara berkala di background.
+// Fungsi yang dikembalikan digunakan untuk menghentikan sweeper.
+func (s *BillService) StartSweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				expired, err := s.ProcessBills(now)
+				if err != nil {
+					log.Println("Gagal memproses split bill:", err)
+				} else if expired > 0 {
+					log.Println("Split bill kedaluwarsa dibatalkan:", expired)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Fungsi bantu untuk meng-capture setiap bagian yang sudah dibayar. Biaya merchant dihitung dari total bill
+// dan dibagi sebanding dengan setiap bagian, bagian terakhir menanggung sisa pembulatan.
+// Harus dipanggil ketika mu sudah dikunci.
+func (s *BillService) captureShares(bill *models.Bill) error {
+	merchant, err := s.merchantRepository.GetByID(bill.MerchantID)
+	if err != nil {
+		return errors.New("ID merchant tidak valid")
+	}
+	totalFee, err := CalculateFee(merchant.Fee, bill.Amount)
+	if err != nil {
+		return fmt.Errorf("gagal menghitung biaya merchant: %w", err)
+	}
+
+	allocated := money.Zero(totalFee.Currency())
+	for i := range bill.Shares {
+		share := &bill.Shares[i]
+		fee, err := totalFee.MulRat(share.Amount.Amount(), bill.Amount.Amount())
+		if err != nil {
+			return err
+		}
+		if i == len(bill.Shares)-1 {
+			fee, err = totalFee.Sub(allocated)
+			if err != nil {
+				return err
+			}
+		}
+		allocated, err = allocated.Add(fee)
+		if err != nil {
+			return err
+		}
+		if share.Status != models.BillShareStatusPaid {
+			continue
+		}
+
+		_, err = s.transactionService.CaptureBillShare(share.TransactionID, fee)
+		if err != nil {
+			return err
+		}
+		share.Status = models.BillShareStatusCaptured
+		bill.UpdatedAt = time.Now()
+		err = s.billRepository.Save(bill)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan split bill: %w", err)
+		}
+	}
+
+	completedAt := time.Now()
+	bill.Status = models.BillStatusCompleted
+	bill.CompletedAt = &completedAt
+	bill.UpdatedAt = completedAt
+	err = s.billRepository.Save(bill)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan split bill: %w", err)
+	}
+	return nil
+}
+
+// Fungsi bantu untuk menutup split bill dan mengembalikan saldo setiap bagian yang sudah dibayar.
+// Harus dipanggil ketika mu sudah dikunci.
+func (s *BillService) closeBill(bill *models.Bill, status string, reason string) error {
+	for i := range bill.Shares {
+		share := &bill.Shares[i]
+		if share.Status != models.BillShareStatusPaid {
+			continue
+		}
+
+		_, err := s.transactionService.ReleaseBillShare(share.TransactionID, reason)
+		if err != nil {
+			return err
+		}
+		share.Status = models.BillShareStatusReturned
+		bill.UpdatedAt = time.Now()
+		err = s.billRepository.Save(bill)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan split bill: %w", err)
+		}
+	}
+
+	bill.Status = status
+	bill.UpdatedAt = time.Now()
+	err := s.billRepository.Save(bill)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan split bill: %w", err)
+	}
+	return nil
+}
+
+// Fungsi bantu untuk mencari bagian pelanggan pada split bill
+func findShare(bill *models.Bill, customerID string) *models.BillShare {
+	for i := range bill.Shares {
+		if bill.Shares[i].CustomerID == customerID {
+			return &bill.Shares[i]
+		}
+	}
+	return nil
+}
+
+// Fungsi bantu untuk memeriksa apakah seluruh bagian split bill sudah dibayar
+func allSharesPaid(bill *models.Bill) bool {
+	for _, share := range bill.Shares {
+		if share.Status == models.BillShareStatusPending {
+			return false
+		}
+	}
+	return true
+}
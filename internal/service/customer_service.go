@@ -36,7 +36,7 @@ func (s *CustomerService) Login(username, password string) (bool, error) {
 	}
 
 	// Generate token
-	token, err := utils.GenerateToken(username, "user")
+	token, err := utils.GenerateToken(username, customer.RoleName())
 	if err != nil {
 		return false, fmt.Errorf("gagal menghasilkan token: %w", err)
 	}
@@ -109,6 +109,16 @@ func (s *CustomerService) GetByUsername(username string) (*models.Customer, erro
 	return s.repo.GetByUsername(username)
 }
 
+// GetByPhone untuk mengambil pelanggan berdasarkan nomor telepon
+func (s *CustomerService) GetByPhone(phone int) (*models.Customer, error) {
+	return s.repo.GetByPhone(phone)
+}
+
+// GetAll untuk mengambil seluruh pelanggan
+func (s *CustomerService) GetAll() ([]models.Customer, error) {
+	return s.repo.GetAll()
+}
+
 // SaveCustomer untuk menyimpan pelanggan baru
 func (s *CustomerService) SaveCustomer(customer *models.Customer) error {
 	return s.repo.SaveCustomer(customer)
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// DefaultInvoiceExpiry adalah lama default invoice dapat dibayar
+const DefaultInvoiceExpiry = 7 * 24 * time.Hour
+
+// InvoiceItemRequest adalah baris tagihan yang diminta saat membuat invoice
+type InvoiceItemRequest struct {
+	Name      string      `json:"name"`
+	Quantity  int64       `json:"quantity"`
+	UnitPrice money.Money `json:"unit_price"`
+}
+
+// InvoiceRequest adalah permintaan merchant untuk membuat invoice. Customer berisi username atau nomor telepon
+// pelanggan yang ditagih, kosong berarti invoice dapat dibayar oleh siapa pun yang menerima ID invoice.
+// Amount kosong berarti jumlah seluruh baris tagihan, expiresAt kosong berarti DefaultInvoiceExpiry dari sekarang.
+type InvoiceRequest struct {
+	Customer    string               `json:"customer"`
+	Description string               `json:"description"`
+	Items       []InvoiceItemRequest `json:"items"`
+	Amount      *money.Money         `json:"amount"`
+	ExpiresAt   *time.Time           `json:"expires_at"`
+}
+
+// InvoiceService menangani invoice: permintaan pembayaran yang dibuat merchant dan dibayar oleh pelanggan
+type InvoiceService struct {
+	invoiceRepository  repository.InvoiceRepository
+	customerRepository repository.CustomerRepository
+	merchantRepository repository.MerchantRepository
+	transactionService *TransactionService
+
+	// mu memastikan pembayaran, pembatalan, dan kedaluwarsa invoice tidak saling mendahului
+	mu sync.Mutex
+}
+
+// NewInvoiceService membuat instance baru dari InvoiceService
+func NewInvoiceService(invoiceRepository repository.InvoiceRepository, customerRepository repository.CustomerRepository, merchantRepository repository.MerchantRepository, transactionService *TransactionService) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepository:  invoiceRepository,
+		customerRepository: customerRepository,
+		merchantRepository: merchantRepository,
+		transactionService: transactionService,
+	}
+}
+
+// CreateInvoice membuat invoice merchant. Jika baris tagihan diisi, jumlah invoice harus sama dengan
+// jumlah seluruh baris tagihan.
+func (s *InvoiceService) CreateInvoice(merchantID string, req InvoiceRequest) (*models.Invoice, error) {
+	log.Println("Membuat invoice...")
+
+	merchant, err := s.merchantRepository.GetByID(merchantID)
+	if err != nil {
+		return nil, errors.New("ID merchant tidak valid")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(DefaultInvoiceExpiry)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(now) {
+		return nil, errors.New("waktu kedaluwarsa harus di masa depan")
+	}
+
+	// Invoice harus dalam mata uang merchant
+	currency := money.Zero(merchant.Currency).Currency()
+	invoice := &models.Invoice{
+		ID:          "INV" + generateTransactionID(),
+		MerchantID:  merchant.ID,
+		Description: req.Description,
+		Items:       make([]models.InvoiceItem, 0, len(req.Items)),
+		Status:      models.InvoiceStatusOpen,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if req.Customer != "" {
+		customer, err := findCustomer(s.customerRepository, req.Customer)
+		if err != nil {
+			return nil, fmt.Errorf("pelanggan %s tidak ditemukan", req.Customer)
+		}
+		invoice.CustomerID = customer.ID
+	}
+
+	total := money.Zero(currency)
+	for _, item := range req.Items {
+		if strings.TrimSpace(item.Name) == "" {
+			return nil, errors.New("nama baris tagihan tidak boleh kosong")
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("kuantitas %s harus lebih dari nol", item.Name)
+		}
+		if !item.UnitPrice.IsPositive() {
+			return nil, fmt.Errorf("harga satuan %s harus lebih dari nol", item.Name)
+		}
+		if item.UnitPrice.Currency() != currency {
+			return nil, fmt.Errorf("merchant hanya menerima pembayaran dalam %s", currency)
+		}
+
+		amount, err := item.UnitPrice.Mul(item.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("jumlah baris tagihan %s terlalu besar: %w", item.Name, err)
+		}
+		total, err = total.Add(amount)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Items = append(invoice.Items, models.InvoiceItem{
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Amount:    amount,
+		})
+	}
+
+	invoice.Amount = total
+	if req.Amount != nil {
+		invoice.Amount = *req.Amount
+		if len(invoice.Items) > 0 {
+			cmp, err := invoice.Amount.Cmp(total)
+			if err != nil || cmp != 0 {
+				return nil, fmt.Errorf("jumlah invoice harus sama dengan jumlah baris tagihan %s", total)
+			}
+		}
+	}
+	if !invoice.Amount.IsPositive() {
+		return nil, errors.New("jumlah invoice harus lebih dari nol")
+	}
+	if invoice.Amount.Currency() != currency {
+		return nil, fmt.Errorf("merchant hanya menerima pembayaran dalam %s", currency)
+	}
+
+	err = s.invoiceRepository.Save(invoice)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan invoice: %w", err)
+	}
+
+	log.Println("Invoice berhasil dibuat.")
+
+	return invoice, nil
+}
+
+// GetMerchantInvoices mengambil invoice milik merchant
+func (s *InvoiceService) GetMerchantInvoices(merchantID string) ([]models.Invoice, error) {
+	invoices, err := s.invoiceRepository.GetByMerchantID(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan invoice: %w", err)
+	}
+	return expireInvoices(invoices, time.Now()), nil
+}
+
+// GetMerchantInvoice mengambil invoice milik merchant
+func (s *InvoiceService) GetMerchantInvoice(merchantID string, invoiceID string) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepository.GetByID(invoiceID)
+	if err != nil {
+		return nil, errors.New("ID invoice tidak valid")
+	}
+	if invoice.MerchantID != merchantID {
+		return nil, errors.New("invoice bukan milik merchant")
+	}
+	expireInvoice(invoice, time.Now())
+	return invoice, nil
+}
+
+// GetCustomerInvoices mengambil invoice yang ditujukan untuk atau sudah dibayar oleh pelanggan
+func (s *InvoiceService) GetCustomerInvoices(customerID string) ([]models.Invoice, error) {
+	invoices, err := s.invoiceRepository.GetByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan invoice: %w", err)
+	}
+	return expireInvoices(invoices, time.Now()), nil
+}
+
+// GetCustomerInvoice mengambil invoice berdasarkan ID yang dibagikan merchant.
+// Invoice yang ditujukan untuk pelanggan tertentu hanya dapat dilihat oleh pelanggan tersebut.
+func (s *InvoiceService) GetCustomerInvoice(customerID string, invoiceID string) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepository.GetByID(invoiceID)
+	if err != nil {
+		return nil, errors.New("ID invoice tidak valid")
+	}
+	if invoice.CustomerID != "" && invoice.CustomerID != customerID {
+		return nil, errors.New("invoice bukan untuk pelanggan")
+	}
+	expireInvoice(invoice, time.Now())
+	return invoice, nil
+}
+
+// PayInvoice membayar invoice dari saldo pelanggan melalui ProcessTransaction sehingga pembayaran
+// melewati validasi, biaya merchant, dan batas pengeluaran yang sama dengan pembayaran biasa
+func (s *InvoiceService) PayInvoice(customerID string, invoiceID string) (*models.Invoice, error) {
+	log.Println("Membayar invoice...")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, err := s.GetCustomerInvoice(customerID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != models.InvoiceStatusOpen {
+		return nil, fmt.Errorf("invoice dengan status %s tidak dapat dibayar", invoice.Status)
+	}
+
+	transaction, err := s.transactionService.ProcessTransaction(customerID, invoice.MerchantID, invoice.Amount, PaymentOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invoice.Status = models.InvoiceStatusPaid
+	invoice.TransactionID = transaction.ID
+	invoice.PaidBy = customerID
+	invoice.PaidAt = &now
+	invoice.UpdatedAt = now
+	err = s.invoiceRepository.Save(invoice)
+	if err != nil {
+		// Pembayaran dikembalikan karena invoice gagal ditandai lunas
+		_, refundErr := s.transactionService.RefundTransaction(invoice.MerchantID, transaction.ID, money.Zero(transaction.Currency), "invoice gagal disimpan")
+		if refundErr != nil {
+			log.Println("Gagal mengembalikan pembayaran invoice", invoice.ID, ":", refundErr)
+		}
+		return nil, fmt.Errorf("gagal menyimpan invoice: %w", err)
+	}
+
+	log.Println("Invoice berhasil dibayar.")
+
+	return invoice, nil
+}
+
+// CancelInvoice membatalkan invoice merchant yang belum dibayar
+func (s *InvoiceService) CancelInvoice(merchantID string, invoiceID string) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, err := s.GetMerchantInvoice(merchantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != models.InvoiceStatusOpen {
+		return nil, fmt.Errorf("invoice dengan status %s tidak dapat dibatalkan", invoice.Status)
+	}
+
+	invoice.Status = models.InvoiceStatusCancelled
+	invoice.UpdatedAt = time.Now()
+	err = s.invoiceRepository.Save(invoice)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// ExpireInvoices menandai invoice yang belum dibayar sampai waktu kedaluwarsanya sebagai expired
+func (s *InvoiceService) ExpireInvoices(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	open, err := s.invoiceRepository.GetByStatus(models.InvoiceStatusOpen)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan invoice: %w", err)
+	}
+
+	expired := 0
+	for i := range open {
+		invoice := &open[i]
+		if !expireInvoice(invoice, now) {
+			continue
+		}
+		err = s.invoiceRepository.Save(invoice)
+		if err != nil {
+			log.Println("Gagal menandai invoice kedaluwarsa", invoice.ID, ":", err)
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// StartSweeper menjalankan ExpireInvoices secara berkala di background.
+// Fungsi yang dikembalikan digunakan untuk menghentikan sweeper.
+func (s *InvoiceService) StartSweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				expired, err := s.ExpireInvoices(now)
+				if err != nil {
+					log.Println("Gagal memproses invoice kedaluwarsa:", err)
+				} else if expired > 0 {
+					log.Println("Invoice kedaluwarsa:", expired)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Fungsi bantu untuk menandai invoice terbuka yang sudah melewati waktu kedaluwarsa sebagai expired
+// sehingga statusnya tetap akurat di antara jalannya sweeper. Mengembalikan true jika status berubah.
+func expireInvoice(invoice *models.Invoice, now time.Time) bool {
+	if invoice.Status != models.InvoiceStatusOpen || now.Before(invoice.ExpiresAt) {
+		return false
+	}
+	invoice.Status = models.InvoiceStatusExpired
+	invoice.UpdatedAt = now
+	return true
+}
+
+// Fungsi bantu untuk menerapkan expireInvoice ke setiap invoice
+func expireInvoices(invoices []models.Invoice, now time.Time) []models.Invoice {
+	for i := range invoices {
+		expireInvoice(&invoices[i], now)
+	}
+	return invoices
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/ledger"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// Fungsi bantu untuk memposting jurnal ke buku besar lalu menyimpan transaksi terkait.
+// Jika transaksi gagal disimpan maka jurnal dibatalkan dengan jurnal pembalik.
+// Dipanggil dari dalam fungsi commit wallet sehingga saldo wallet ikut dibatalkan ketika gagal.
+func postAndSave(book *ledger.Ledger, transactionRepository *repository.TransactionRepository, entry *ledger.Entry, transactions ...*models.Transaction) error {
+	return postEntriesAndSave(book, transactionRepository, []*ledger.Entry{entry}, transactions...)
+}
+
+// Fungsi bantu untuk memposting beberapa jurnal ke buku besar lalu menyimpan transaksi terkait.
+// Jika salah satu jurnal gagal diposting atau transaksi gagal disimpan maka jurnal yang sudah diposting dibatalkan.
+func postEntriesAndSave(book *ledger.Ledger, transactionRepository *repository.TransactionRepository, entries []*ledger.Entry, transactions ...*models.Transaction) error {
+	var err error
+	posted := 0
+	for _, entry := range entries {
+		err = book.Post(entry)
+		if err != nil {
+			err = fmt.Errorf("gagal memposting jurnal: %w", err)
+			break
+		}
+		posted++
+	}
+
+	if err == nil {
+		err = transactionRepository.SaveTransactions(transactions...)
+	}
+	if err != nil {
+		for _, entry := range entries[:posted] {
+			reverseErr := book.Reverse(entry, "transaksi gagal disimpan")
+			if reverseErr != nil {
+				log.Println("Gagal membatalkan jurnal", entry.ID, ":", reverseErr)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Fungsi bantu untuk menyeimbangkan jurnal yang melibatkan lebih dari satu mata uang.
+// Selisih setiap mata uang dicatat pada akun posisi valas mata uang tersebut.
+func withFXPosition(legs ...ledger.Leg) []ledger.Leg {
+	currencies := make([]string, 0)
+	totals := make(map[string]money.Money)
+	for _, leg := range legs {
+		currency := leg.Amount.Currency()
+		if _, ok := totals[currency]; !ok {
+			currencies = append(currencies, currency)
+		}
+		totals[currency], _ = totals[currency].Add(leg.Amount)
+	}
+	if len(currencies) < 2 {
+		return legs
+	}
+
+	for _, currency := range currencies {
+		if !totals[currency].IsZero() {
+			legs = append(legs, ledger.Debit(ledger.FXPosition(currency), totals[currency].Neg()))
+		}
+	}
+	return legs
+}
+
+// Fungsi bantu untuk membuat baris jurnal sisi merchant: jumlah bersih ke utang merchant dan
+// biaya merchant ke pendapatan biaya. post adalah ledger.Credit untuk pembayaran atau ledger.Debit untuk refund.
+func merchantLegs(transaction *models.Transaction, post func(ledger.Account, money.Money) ledger.Leg) []ledger.Leg {
+	net, fee := transaction.Net, transaction.Fee
+	if net.IsZero() && fee.IsZero() {
+		// Transaksi lama yang belum memiliki rincian biaya
+		net = merchantAmount(transaction)
+	}
+
+	legs := make([]ledger.Leg, 0, 2)
+	if net.IsPositive() {
+		legs = append(legs, post(ledger.MerchantPayable(transaction.MerchantID, net.Currency()), net))
+	}
+	if fee.IsPositive() {
+		legs = append(legs, post(ledger.FeeRevenue(fee.Currency()), fee))
+	}
+	return legs
+}
+
+// Fungsi bantu untuk membuat jurnal pembayaran langsung dari wallet pelanggan ke merchant
+func paymentEntry(transaction *models.Transaction) *ledger.Entry {
+	legs := append([]ledger.Leg{
+		ledger.Debit(ledger.CustomerWallet(transaction.CustomerID, transaction.Amount.Currency()), transaction.Amount),
+	}, merchantLegs(transaction, ledger.Credit)...)
+	return ledger.NewEntry(transaction.ID, "payment", withFXPosition(legs...)...)
+}
+
+// Fungsi bantu untuk membuat jurnal penahanan saldo oleh otorisasi
+func authorizationEntry(transaction *models.Transaction) *ledger.Entry {
+	amount := holdAmount(transaction)
+	currency := amount.Currency()
+	return ledger.NewEntry(transaction.ID, "authorization hold",
+		ledger.Debit(ledger.CustomerWallet(transaction.CustomerID, currency), amount),
+		ledger.Credit(ledger.CustomerHold(transaction.CustomerID, currency), amount),
+	)
+}
+
+// Fungsi bantu untuk membuat jurnal capture dari transaksi yang jumlahnya sudah diperbarui,
+// sisa saldo yang ditahan dikembalikan ke wallet pelanggan
+func captureEntry(transaction *models.Transaction, held money.Money) (*ledger.Entry, error) {
+	currency := held.Currency()
+	legs := append([]ledger.Leg{
+		ledger.Debit(ledger.CustomerHold(transaction.CustomerID, currency), transaction.Amount),
+	}, merchantLegs(transaction, ledger.Credit)...)
+
+	remainder, err := held.Sub(transaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if remainder.IsPositive() {
+		legs = append(legs,
+			ledger.Debit(ledger.CustomerHold(transaction.CustomerID, currency), remainder),
+			ledger.Credit(ledger.CustomerWallet(transaction.CustomerID, currency), remainder),
+		)
+	}
+
+	return ledger.NewEntry(transaction.ID, "authorization capture", withFXPosition(legs...)...), nil
+}
+
+// Fungsi bantu untuk membuat jurnal pelepasan saldo yang ditahan
+func voidEntry(transaction *models.Transaction) *ledger.Entry {
+	amount := holdAmount(transaction)
+	currency := amount.Currency()
+	return ledger.NewEntry(transaction.ID, "authorization void",
+		ledger.Debit(ledger.CustomerHold(transaction.CustomerID, currency), amount),
+		ledger.Credit(ledger.CustomerWallet(transaction.CustomerID, currency), amount),
+	)
+}
+
+// Fungsi bantu untuk membuat jurnal refund dari merchant ke wallet pelanggan
+func refundEntry(refund *models.Transaction) *ledger.Entry {
+	legs := append(merchantLegs(refund, ledger.Debit),
+		ledger.Credit(ledger.CustomerWallet(refund.CustomerID, refund.Amount.Currency()), refund.Amount),
+	)
+	return ledger.NewEntry(refund.ID, refund.Description, withFXPosition(legs...)...)
+}
+
+// Fungsi bantu untuk membuat jurnal top-up dari sumber dana ke wallet pelanggan
+func topUpEntry(transaction *models.Transaction, source string) *ledger.Entry {
+	currency := transaction.Amount.Currency()
+	return ledger.NewEntry(transaction.ID, transaction.Description,
+		ledger.Debit(ledger.FundingSource(source, currency), transaction.Amount),
+		ledger.Credit(ledger.CustomerWallet(transaction.CustomerID, currency), transaction.Amount),
+	)
+}
+
+// Fungsi bantu untuk membuat jurnal transfer antar wallet pelanggan
+func transferEntry(outgoing *models.Transaction) *ledger.Entry {
+	currency := outgoing.Amount.Currency()
+	return ledger.NewEntry(outgoing.ID, outgoing.Description,
+		ledger.Debit(ledger.CustomerWallet(outgoing.CustomerID, currency), outgoing.Amount),
+		ledger.Credit(ledger.CustomerWallet(outgoing.CounterpartyID, currency), outgoing.Amount),
+	)
+}
+
+// Fungsi bantu untuk membuat jurnal cashback dari beban promosi ke wallet pelanggan
+func cashbackEntry(transaction *models.Transaction) *ledger.Entry {
+	currency := transaction.Amount.Currency()
+	return ledger.NewEntry(transaction.ID, transaction.Description,
+		ledger.Debit(ledger.PromotionExpense(currency), transaction.Amount),
+		ledger.Credit(ledger.CustomerWallet(transaction.CustomerID, currency), transaction.Amount),
+	)
+}
+
+// Fungsi bantu untuk membuat jurnal penarikan kembali cashback dari wallet pelanggan ke beban promosi
+func cashbackReversalEntry(transaction *models.Transaction) *ledger.Entry {
+	currency := transaction.Amount.Currency()
+	return ledger.NewEntry(transaction.ID, transaction.Description,
+		ledger.Debit(ledger.CustomerWallet(transaction.CustomerID, currency), transaction.Amount),
+		ledger.Credit(ledger.PromotionExpense(currency), transaction.Amount),
+	)
+}
+
+// Fungsi bantu untuk membuat jurnal penukaran poin dari beban loyalitas ke wallet pelanggan
+func pointsRedemptionEntry(transaction *models.Transaction) *ledger.Entry {
+	currency := transaction.Amount.Currency()
+	return ledger.NewEntry(transaction.ID, transaction.Description,
+		ledger.Debit(ledger.LoyaltyExpense(currency), transaction.Amount),
+		ledger.Credit(ledger.CustomerWallet(transaction.CustomerID, currency), transaction.Amount),
+	)
+}
+
+// Fungsi bantu untuk membuat jurnal penarikan kembali nilai penukaran poin dari wallet pelanggan ke beban loyalitas
+func pointsRedemptionReversalEntry(transaction *models.Transaction) *ledger.Entry {
+	currency := transaction.Amount.Currency()
+	return ledger.NewEntry(transaction.ID, transaction.Description,
+		ledger.Debit(ledger.CustomerWallet(transaction.CustomerID, currency), transaction.Amount),
+		ledger.Credit(ledger.LoyaltyExpense(currency), transaction.Amount),
+	)
+}
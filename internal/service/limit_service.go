@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// Kode error ketika batas pengeluaran terlampaui
+const (
+	LimitCodePerTransaction = "LIMIT_PER_TRANSACTION_EXCEEDED"
+	LimitCodeDaily          = "LIMIT_DAILY_EXCEEDED"
+	LimitCodeMonthly        = "LIMIT_MONTHLY_EXCEEDED"
+)
+
+// LimitError dikembalikan ketika transaksi melampaui batas pengeluaran pelanggan
+type LimitError struct {
+	Code   string
+	Limit  money.Money
+	Used   money.Money
+	Amount money.Money
+}
+
+func (e *LimitError) Error() string {
+	switch e.Code {
+	case LimitCodeDaily:
+		return fmt.Sprintf("batas pengeluaran harian %s terlampaui, sudah digunakan %s", e.Limit, e.Used)
+	case LimitCodeMonthly:
+		return fmt.Sprintf("batas pengeluaran bulanan %s terlampaui, sudah digunakan %s", e.Limit, e.Used)
+	}
+	return fmt.Sprintf("jumlah transaksi %s melebihi batas per transaksi %s", e.Amount, e.Limit)
+}
+
+// LimitUsage berisi batas pengeluaran yang berlaku untuk pelanggan beserta pemakaiannya
+type LimitUsage struct {
+	CustomerID  string               `json:"customer_id"`
+	Tier        string               `json:"tier"`
+	Limit       models.SpendingLimit `json:"limit"`
+	DailyUsed   money.Money          `json:"daily_used"`
+	MonthlyUsed money.Money          `json:"monthly_used"`
+}
+
+// LimitService menangani batas pengeluaran pelanggan per transaksi, harian, dan bulanan
+type LimitService struct {
+	limitRepository       repository.LimitRepository
+	customerRepository    repository.CustomerRepository
+	transactionRepository *repository.TransactionRepository
+}
+
+// NewLimitService membuat instance baru dari LimitService
+func NewLimitService(limitRepository repository.LimitRepository, customerRepository repository.CustomerRepository, transactionRepository *repository.TransactionRepository) *LimitService {
+	return &LimitService{
+		limitRepository:       limitRepository,
+		customerRepository:    customerRepository,
+		transactionRepository: transactionRepository,
+	}
+}
+
+// CheckSpend memeriksa apakah pengeluaran sejumlah amount pada waktu now masih dalam batas pelanggan.
+// Pemakaian harian dan bulanan dihitung dari riwayat transaksi pelanggan.
+func (s *LimitService) CheckSpend(customerID string, amount money.Money, now time.Time) error {
+	usage, err := s.Usage(customerID, now)
+	if err != nil {
+		return err
+	}
+
+	limit := usage.Limit
+	if limit.PerTransaction != nil {
+		err = checkLimit(LimitCodePerTransaction, *limit.PerTransaction, money.Zero(amount.Currency()), amount)
+		if err != nil {
+			return err
+		}
+	}
+	if limit.Daily != nil {
+		err = checkLimit(LimitCodeDaily, *limit.Daily, usage.DailyUsed, amount)
+		if err != nil {
+			return err
+		}
+	}
+	if limit.Monthly != nil {
+		err = checkLimit(LimitCodeMonthly, *limit.Monthly, usage.MonthlyUsed, amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Usage mengambil batas pengeluaran yang berlaku untuk pelanggan beserta pemakaian hari dan bulan berjalan
+func (s *LimitService) Usage(customerID string, now time.Time) (*LimitUsage, error) {
+	customer, err := s.customerRepository.GetByID(customerID)
+	if err != nil {
+		return nil, errors.New("ID customer tidak valid")
+	}
+
+	limit, err := s.EffectiveLimit(customer)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepository.GetTransactionsByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan riwayat transaksi: %w", err)
+	}
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	usage := &LimitUsage{
+		CustomerID: customerID,
+		Tier:       customer.TierName(),
+		Limit:      limit,
+	}
+	for i := range transactions {
+		transaction := &transactions[i]
+		if !countsTowardsLimit(transaction) || transaction.CreatedAt.Before(startOfMonth) || transaction.CreatedAt.After(now) {
+			continue
+		}
+
+		usage.MonthlyUsed, err = usage.MonthlyUsed.Add(transaction.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if !transaction.CreatedAt.Before(startOfDay) {
+			usage.DailyUsed, err = usage.DailyUsed.Add(transaction.Amount)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// EffectiveLimit menggabungkan batas tier pelanggan dengan batas khusus pelanggan.
+// Setiap nilai pada batas khusus menggantikan nilai yang sama pada batas tier.
+func (s *LimitService) EffectiveLimit(customer *models.Customer) (models.SpendingLimit, error) {
+	var limit models.SpendingLimit
+	tierLimit, err := s.limitRepository.GetTierLimit(customer.TierName())
+	if err == nil {
+		limit = *tierLimit
+	}
+
+	customerLimit, err := s.limitRepository.GetCustomerLimit(customer.ID)
+	if err == nil {
+		if customerLimit.PerTransaction != nil {
+			limit.PerTransaction = customerLimit.PerTransaction
+		}
+		if customerLimit.Daily != nil {
+			limit.Daily = customerLimit.Daily
+		}
+		if customerLimit.Monthly != nil {
+			limit.Monthly = customerLimit.Monthly
+		}
+		if customerLimit.UpdatedAt.After(limit.UpdatedAt) {
+			limit.UpdatedAt = customerLimit.UpdatedAt
+		}
+	}
+
+	return limit, nil
+}
+
+// GetConfig mengambil seluruh konfigurasi batas pengeluaran
+func (s *LimitService) GetConfig() (*models.LimitConfig, error) {
+	return s.limitRepository.GetConfig()
+}
+
+// SetTierLimit mengatur batas pengeluaran sebuah tier
+func (s *LimitService) SetTierLimit(tier string, limit models.SpendingLimit) error {
+	if tier == "" {
+		return errors.New("tier tidak boleh kosong")
+	}
+	err := validateLimit(limit)
+	if err != nil {
+		return err
+	}
+
+	return s.limitRepository.SetTierLimit(tier, limit)
+}
+
+// SetCustomerLimit mengatur batas pengeluaran khusus seorang pelanggan
+func (s *LimitService) SetCustomerLimit(customerID string, limit models.SpendingLimit) error {
+	_, err := s.customerRepository.GetByID(customerID)
+	if err != nil {
+		return errors.New("ID customer tidak valid")
+	}
+	err = validateLimit(limit)
+	if err != nil {
+		return err
+	}
+
+	return s.limitRepository.SetCustomerLimit(customerID, limit)
+}
+
+// DeleteCustomerLimit menghapus batas pengeluaran khusus pelanggan
+func (s *LimitService) DeleteCustomerLimit(customerID string) error {
+	err := s.limitRepository.DeleteCustomerLimit(customerID)
+	if err != nil {
+		return errors.New("pelanggan tidak memiliki batas khusus")
+	}
+	return nil
+}
+
+// SetCustomerTier memindahkan pelanggan ke tier lain yang sudah memiliki batas pengeluaran
+func (s *LimitService) SetCustomerTier(customerID string, tier string) (*models.Customer, error) {
+	customer, err := s.customerRepository.GetByID(customerID)
+	if err != nil {
+		return nil, errors.New("ID customer tidak valid")
+	}
+	_, err = s.limitRepository.GetTierLimit(tier)
+	if err != nil {
+		return nil, fmt.Errorf("tier %s tidak terdaftar", tier)
+	}
+
+	previous := customer.Tier
+	customer.Tier = tier
+	err = s.customerRepository.SaveToFile()
+	if err != nil {
+		customer.Tier = previous
+		return nil, fmt.Errorf("gagal menyimpan tier pelanggan: %w", err)
+	}
+
+	return customer, nil
+}
+
+// Fungsi bantu untuk menentukan apakah transaksi dihitung sebagai pengeluaran pelanggan.
+// Pembayaran yang sedang diotorisasi ikut dihitung karena saldonya sudah ditahan.
+func countsTowardsLimit(transaction *models.Transaction) bool {
+	status := TransactionStatus(transaction)
+	switch transaction.Type {
+	case "", models.TransactionTypePayment:
+		return status == models.TransactionStatusAuthorized || status == models.TransactionStatusCaptured
+	case models.TransactionTypeTransferOut:
+		return status == models.TransactionStatusCaptured
+	}
+	return false
+}
+
+// Fungsi bantu untuk memeriksa apakah pemakaian ditambah amount melampaui batas
+func checkLimit(code string, limit, used, amount money.Money) error {
+	total, err := used.Add(amount)
+	if err != nil {
+		return err
+	}
+	cmp, err := total.Cmp(limit)
+	if err != nil {
+		return fmt.Errorf("mata uang batas pengeluaran tidak sesuai: %w", err)
+	}
+	if cmp > 0 {
+		return &LimitError{Code: code, Limit: limit, Used: used, Amount: amount}
+	}
+	return nil
+}
+
+// Fungsi bantu untuk memvalidasi nilai batas pengeluaran
+func validateLimit(limit models.SpendingLimit) error {
+	for _, value := range []*money.Money{limit.PerTransaction, limit.Daily, limit.Monthly} {
+		if value != nil && !value.IsPositive() {
+			return errors.New("batas pengeluaran harus lebih besar dari nol")
+		}
+	}
+	return nil
+}
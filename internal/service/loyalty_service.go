@@ -0,0 +1,472 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// DefaultPointsExpiry adalah masa berlaku poin jika expiry_days pada pengaturan tidak diisi
+const DefaultPointsExpiry = 365 * 24 * time.Hour
+
+// PointsBalance adalah saldo poin pelanggan beserta nilainya. NextExpiry dan ExpiringPoints menunjukkan
+// sisa poin yang paling cepat hangus.
+type PointsBalance struct {
+	CustomerID     string      `json:"customer_id"`
+	Points         int64       `json:"points"`
+	Value          money.Money `json:"value"`
+	NextExpiry     *time.Time  `json:"next_expiry,omitempty"`
+	ExpiringPoints int64       `json:"expiring_points,omitempty"`
+}
+
+// LoyaltyService menangani buku poin loyalitas pelanggan: perolehan poin dari pembayaran, penukaran poin
+// sebagai potongan pembayaran, dan poin yang hangus
+type LoyaltyService struct {
+	loyaltyRepository  repository.LoyaltyRepository
+	pointsRepository   repository.PointsRepository
+	merchantRepository repository.MerchantRepository
+
+	// mu memastikan perubahan sisa poin tidak saling mendahului sehingga poin tidak ditukar dua kali
+	mu sync.Mutex
+}
+
+// NewLoyaltyService membuat instance baru dari LoyaltyService
+func NewLoyaltyService(loyaltyRepository repository.LoyaltyRepository, pointsRepository repository.PointsRepository, merchantRepository repository.MerchantRepository) *LoyaltyService {
+	return &LoyaltyService{
+		loyaltyRepository:  loyaltyRepository,
+		pointsRepository:   pointsRepository,
+		merchantRepository: merchantRepository,
+	}
+}
+
+// GetConfig mengambil pengaturan program poin loyalitas
+func (s *LoyaltyService) GetConfig() (*models.LoyaltyConfig, error) {
+	config, err := s.loyaltyRepository.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pengaturan poin: %w", err)
+	}
+	return config, nil
+}
+
+// SetMerchantRate mengatur perolehan poin khusus merchant. Kelipatan pembayaran harus dalam mata uang merchant.
+func (s *LoyaltyService) SetMerchantRate(merchantID string, rate models.EarnRate) error {
+	merchant, err := s.merchantRepository.GetByID(merchantID)
+	if err != nil {
+		return errors.New("ID merchant tidak valid")
+	}
+	if rate.Points <= 0 {
+		return errors.New("jumlah poin harus lebih dari nol")
+	}
+	if !rate.Per.IsPositive() {
+		return errors.New("kelipatan pembayaran harus lebih dari nol")
+	}
+	if currency := money.Zero(merchant.Currency).Currency(); rate.Per.Currency() != currency {
+		return fmt.Errorf("kelipatan pembayaran harus dalam %s sesuai mata uang merchant", currency)
+	}
+
+	err = s.loyaltyRepository.SetMerchantRate(merchant.ID, rate)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan perolehan poin merchant: %w", err)
+	}
+	return nil
+}
+
+// DeleteMerchantRate menghapus perolehan poin khusus merchant sehingga kembali mengikuti perolehan default
+func (s *LoyaltyService) DeleteMerchantRate(merchantID string) error {
+	err := s.loyaltyRepository.DeleteMerchantRate(merchantID)
+	if err != nil {
+		return errors.New("merchant tidak memiliki perolehan poin khusus")
+	}
+	return nil
+}
+
+// Balance menghitung saldo poin pelanggan setelah poin yang melewati masa berlakunya dihanguskan
+func (s *LoyaltyService) Balance(customerID string) (*PointsBalance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	lots, err := s.availableLots(customerID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &PointsBalance{CustomerID: customerID}
+	for i := range lots {
+		balance.Points += lots[i].Remaining
+		if lots[i].ExpiresAt == nil {
+			continue
+		}
+		if balance.NextExpiry == nil || lots[i].ExpiresAt.Before(*balance.NextExpiry) {
+			expiresAt := *lots[i].ExpiresAt
+			balance.NextExpiry = &expiresAt
+			balance.ExpiringPoints = 0
+		}
+		if lots[i].ExpiresAt.Equal(*balance.NextExpiry) {
+			balance.ExpiringPoints += lots[i].Remaining
+		}
+	}
+
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	balance.Value, err = config.PointValue.Mul(balance.Points)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung nilai poin: %w", err)
+	}
+	return balance, nil
+}
+
+// History mengambil seluruh catatan poin pelanggan diurutkan dari yang terbaru
+func (s *LoyaltyService) History(customerID string) ([]models.PointsEntry, error) {
+	entries, err := s.pointsRepository.GetByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan riwayat poin: %w", err)
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// EarnedPoints menghitung poin yang didapat dari jumlah yang dibayar ke merchant. Merchant tanpa perolehan poin
+// khusus menggunakan perolehan default jika mata uangnya sama, selain itu tidak mendapat poin.
+func (s *LoyaltyService) EarnedPoints(merchantID string, paid money.Money) (int64, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	rate, ok := config.Merchants[merchantID]
+	if !ok {
+		if config.Default == nil {
+			return 0, nil
+		}
+		rate = *config.Default
+	}
+	if rate.Points <= 0 || !rate.Per.IsPositive() || rate.Per.Currency() != paid.Currency() || !paid.IsPositive() {
+		return 0, nil
+	}
+
+	return paid.Amount() / rate.Per.Amount() * rate.Points, nil
+}
+
+// Earn mencatat poin yang didapat dari pembayaran sebesar PointsEarned pada transaksi
+func (s *LoyaltyService) Earn(transaction *models.Transaction) error {
+	if transaction.PointsEarned <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.credit(transaction.CustomerID, models.PointsEntryEarn, transaction.PointsEarned, transaction.ID, transaction.MerchantID,
+		fmt.Sprintf("points for transaction %s", transaction.ID), transaction.CreatedAt)
+}
+
+// RedeemValue menghitung nilai penukaran sejumlah poin
+func (s *LoyaltyService) RedeemValue(points int64) (money.Money, error) {
+	if points <= 0 {
+		return money.Money{}, errors.New("jumlah poin yang ditukar harus lebih dari nol")
+	}
+	config, err := s.GetConfig()
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !config.PointValue.IsPositive() {
+		return money.Money{}, errors.New("poin tidak dapat ditukar")
+	}
+	value, err := config.PointValue.Mul(points)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("gagal menghitung nilai poin: %w", err)
+	}
+	return value, nil
+}
+
+// Redeem menukar poin pelanggan untuk pembayaran. Poin diambil dari sisa poin yang paling cepat hangus.
+// Jika pembayaran gagal, poin harus dikembalikan dengan Release.
+func (s *LoyaltyService) Redeem(customerID string, points int64, transactionID string) (*models.PointsEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	lots, err := s.availableLots(customerID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, updated := consumeLots(lots, points)
+	if entry.Points+points != 0 {
+		return nil, fmt.Errorf("poin tidak mencukupi, saldo poin %d", -entry.Points)
+	}
+	entry.ID = generatePointsEntryID()
+	entry.CustomerID = customerID
+	entry.Type = models.PointsEntryRedeem
+	entry.TransactionID = transactionID
+	entry.Description = fmt.Sprintf("redeem for transaction %s", transactionID)
+	entry.CreatedAt = now
+
+	err = s.pointsRepository.Save(append(updated, entry)...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menukar poin: %w", err)
+	}
+	return entry, nil
+}
+
+// Release mengembalikan poin yang ditukar oleh pembayaran yang gagal ke sisa poin asalnya
+func (s *LoyaltyService) Release(redeemed *models.PointsEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.pointsRepository.GetByCustomerID(redeemed.CustomerID)
+	if err != nil {
+		log.Println("Gagal mengembalikan poin transaksi", redeemed.TransactionID, ":", err)
+		return
+	}
+	byID := make(map[string]*models.PointsEntry, len(entries))
+	for i := range entries {
+		byID[entries[i].ID] = &entries[i]
+	}
+
+	release := &models.PointsEntry{
+		ID:            generatePointsEntryID(),
+		CustomerID:    redeemed.CustomerID,
+		Type:          models.PointsEntryRelease,
+		Points:        -redeemed.Points,
+		Lots:          redeemed.Lots,
+		TransactionID: redeemed.TransactionID,
+		Description:   fmt.Sprintf("release for failed transaction %s", redeemed.TransactionID),
+		CreatedAt:     time.Now(),
+	}
+	updated := []*models.PointsEntry{release}
+	for _, lot := range redeemed.Lots {
+		if entry, ok := byID[lot.EntryID]; ok {
+			entry.Remaining += lot.Points
+			updated = append(updated, entry)
+		}
+	}
+
+	err = s.pointsRepository.Save(updated...)
+	if err != nil {
+		log.Println("Gagal mengembalikan poin transaksi", redeemed.TransactionID, ":", err)
+	}
+}
+
+// Refund mengembalikan poin yang ditukar oleh pembayaran yang direfund sebagai poin baru dengan masa berlaku baru
+func (s *LoyaltyService) Refund(customerID string, points int64, transactionID string, description string) error {
+	if points <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.credit(customerID, models.PointsEntryRefund, points, transactionID, "", description, time.Now())
+}
+
+// Reverse menarik kembali poin yang didapat dari pembayaran yang direfund, diutamakan dari sisa poin pembayaran
+// tersebut. Poin yang sudah terpakai atau hangus tidak dapat ditarik sehingga jumlah yang ditarik dapat lebih
+// kecil dari yang diminta.
+func (s *LoyaltyService) Reverse(customerID string, points int64, transactionID string, description string) (int64, error) {
+	if points <= 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	lots, err := s.availableLots(customerID, now)
+	if err != nil {
+		return 0, err
+	}
+	sort.SliceStable(lots, func(i, j int) bool {
+		return lots[i].TransactionID == transactionID && lots[j].TransactionID != transactionID
+	})
+
+	entry, updated := consumeLots(lots, points)
+	if entry.Points == 0 {
+		return 0, nil
+	}
+	entry.ID = generatePointsEntryID()
+	entry.CustomerID = customerID
+	entry.Type = models.PointsEntryReverse
+	entry.TransactionID = transactionID
+	entry.Description = description
+	entry.CreatedAt = now
+
+	err = s.pointsRepository.Save(append(updated, entry)...)
+	if err != nil {
+		return 0, fmt.Errorf("gagal menarik kembali poin: %w", err)
+	}
+	return -entry.Points, nil
+}
+
+// ExpirePoints menghanguskan seluruh sisa poin yang melewati masa berlakunya dan mengembalikan jumlah poin yang hangus
+func (s *LoyaltyService) ExpirePoints(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lots, err := s.pointsRepository.GetExpiring()
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan poin: %w", err)
+	}
+
+	expired := int64(0)
+	for i := range lots {
+		if lots[i].ExpiresAt.After(now) {
+			continue
+		}
+		points := lots[i].Remaining
+		err = s.pointsRepository.Save(expireLot(&lots[i], now)...)
+		if err != nil {
+			log.Println("Gagal menghanguskan poin", lots[i].ID, ":", err)
+			continue
+		}
+		expired += points
+	}
+	return expired, nil
+}
+
+// StartSweeper menjalankan ExpirePoints secara berkala di background.
+// Fungsi yang dikembalikan digunakan untuk menghentikan sweeper.
+func (s *LoyaltyService) StartSweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				expired, err := s.ExpirePoints(now)
+				if err != nil {
+					log.Println("Gagal menghanguskan poin:", err)
+				} else if expired > 0 {
+					log.Println("Poin hangus:", expired)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Fungsi bantu untuk mencatat poin baru beserta masa berlakunya.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (s *LoyaltyService) credit(customerID string, entryType string, points int64, transactionID string, merchantID string, description string, at time.Time) error {
+	config, err := s.GetConfig()
+	if err != nil {
+		return err
+	}
+	expiry := DefaultPointsExpiry
+	if config.ExpiryDays > 0 {
+		expiry = time.Duration(config.ExpiryDays) * 24 * time.Hour
+	}
+	expiresAt := at.Add(expiry)
+
+	entry := &models.PointsEntry{
+		ID:            generatePointsEntryID(),
+		CustomerID:    customerID,
+		Type:          entryType,
+		Points:        points,
+		Remaining:     points,
+		TransactionID: transactionID,
+		MerchantID:    merchantID,
+		Description:   description,
+		ExpiresAt:     &expiresAt,
+		CreatedAt:     at,
+	}
+	err = s.pointsRepository.Save(entry)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan poin: %w", err)
+	}
+	return nil
+}
+
+// Fungsi bantu untuk menghanguskan sisa poin pelanggan yang melewati masa berlakunya lalu mengembalikan
+// sisa poin yang masih berlaku diurutkan dari yang paling cepat hangus.
+// Harus dipanggil ketika mutex sudah dikunci.
+func (s *LoyaltyService) availableLots(customerID string, now time.Time) ([]models.PointsEntry, error) {
+	entries, err := s.pointsRepository.GetByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan poin: %w", err)
+	}
+
+	lots := make([]models.PointsEntry, 0)
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Remaining <= 0 {
+			continue
+		}
+		if entry.ExpiresAt != nil && !entry.ExpiresAt.After(now) {
+			err = s.pointsRepository.Save(expireLot(entry, now)...)
+			if err != nil {
+				return nil, fmt.Errorf("gagal menghanguskan poin: %w", err)
+			}
+			continue
+		}
+		lots = append(lots, *entry)
+	}
+
+	sort.SliceStable(lots, func(i, j int) bool {
+		if lots[i].ExpiresAt == nil || lots[j].ExpiresAt == nil {
+			return lots[j].ExpiresAt == nil && lots[i].ExpiresAt != nil
+		}
+		return lots[i].ExpiresAt.Before(*lots[j].ExpiresAt)
+	})
+	return lots, nil
+}
+
+// Fungsi bantu untuk mengambil sampai sejumlah poin dari sisa poin secara berurutan. Mengembalikan catatan
+// pengurangan poin yang belum diberi ID beserta sisa poin yang berubah.
+func consumeLots(lots []models.PointsEntry, points int64) (*models.PointsEntry, []*models.PointsEntry) {
+	entry := &models.PointsEntry{}
+	updated := make([]*models.PointsEntry, 0)
+	for i := range lots {
+		if points <= 0 {
+			break
+		}
+		lot := &lots[i]
+		taken := lot.Remaining
+		if taken > points {
+			taken = points
+		}
+		lot.Remaining -= taken
+		points -= taken
+		entry.Points -= taken
+		entry.Lots = append(entry.Lots, models.PointsLot{EntryID: lot.ID, Points: taken})
+		updated = append(updated, lot)
+	}
+	return entry, updated
+}
+
+// Fungsi bantu untuk membuat catatan poin hangus untuk seluruh sisa poin lalu mengosongkan sisa poin tersebut
+func expireLot(lot *models.PointsEntry, now time.Time) []*models.PointsEntry {
+	expire := &models.PointsEntry{
+		ID:          generatePointsEntryID(),
+		CustomerID:  lot.CustomerID,
+		Type:        models.PointsEntryExpire,
+		Points:      -lot.Remaining,
+		Lots:        []models.PointsLot{{EntryID: lot.ID, Points: lot.Remaining}},
+		Description: fmt.Sprintf("points from %s expired", lot.ID),
+		CreatedAt:   now,
+	}
+	lot.Remaining = 0
+	return []*models.PointsEntry{lot, expire}
+}
+
+// Fungsi bantu untuk menghasilkan ID catatan poin yang unik
+func generatePointsEntryID() string {
+	return "PTS" + generateTransactionID()
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// MaxPercentageDecimals adalah jumlah angka desimal maksimum persentase biaya merchant dan promosi
+const MaxPercentageDecimals = 4
+
+// percentagePattern adalah format persentase yang diterima: bilangan desimal tidak negatif dengan paling banyak
+// MaxPercentageDecimals angka desimal
+var percentagePattern = regexp.MustCompile(fmt.Sprintf(`^[0-9]+(\.[0-9]{1,%d})?$`, MaxPercentageDecimals))
+
+// ValidateMerchantFees memeriksa skema biaya seluruh merchant. Merchant ditulis manual di file merchants.json
+// sehingga skema biaya diperiksa saat server dijalankan, bukan saat pembayaran pertama ke merchant tersebut.
+func ValidateMerchantFees(merchantRepository repository.MerchantRepository) error {
+	merchants, err := merchantRepository.GetAll()
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan merchant: %w", err)
+	}
+	for _, merchant := range merchants {
+		err = validateFeeSchedule(merchant.Fee, merchant.Currency)
+		if err != nil {
+			return fmt.Errorf("skema biaya merchant %s tidak valid: %w", merchant.ID, err)
+		}
+	}
+	return nil
+}
+
+// CalculateFee menghitung biaya merchant (MDR) untuk jumlah pembayaran gross sesuai skema biaya.
+// Biaya tidak pernah melebihi jumlah pembayaran.
+func CalculateFee(schedule *models.FeeSchedule, gross money.Money) (money.Money, error) {
+	fee := money.Zero(gross.Currency())
+	if schedule == nil {
+		return fee, nil
+	}
+
+	var err error
+	switch schedule.Type {
+	case models.FeeTypePercentage:
+		fee, err = feeComponents(gross, schedule.Percentage, nil)
+	case models.FeeTypeFlat:
+		if schedule.Flat == nil {
+			return money.Money{}, fmt.Errorf("skema biaya flat tidak memiliki nilai flat")
+		}
+		fee, err = feeComponents(gross, "", schedule.Flat)
+	case models.FeeTypeTiered:
+		tier, tierErr := feeTier(schedule.Tiers, gross)
+		if tierErr != nil {
+			return money.Money{}, tierErr
+		}
+		fee, err = feeComponents(gross, tier.Percentage, tier.Flat)
+	default:
+		return money.Money{}, fmt.Errorf("jenis skema biaya %q tidak valid", schedule.Type)
+	}
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	// Biaya dibatasi sebesar jumlah pembayaran
+	cmp, err := fee.Cmp(gross)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if cmp > 0 {
+		fee = gross
+	}
+
+	return fee, nil
+}
+
+// Fungsi bantu untuk mengisi Gross, Fee, dan Net transaksi pembayaran berdasarkan skema biaya merchant
+func applyMerchantFee(transaction *models.Transaction, merchant *models.Merchant) error {
+	gross := merchantAmount(transaction)
+	fee, err := CalculateFee(merchant.Fee, gross)
+	if err != nil {
+		return fmt.Errorf("gagal menghitung biaya merchant: %w", err)
+	}
+
+	return setFee(transaction, gross, fee)
+}
+
+// Fungsi bantu untuk mengisi Gross, Fee, dan Net refund. Biaya yang dikembalikan sebanding dengan
+// jumlah refund, refund terakhir mengembalikan seluruh sisa biaya pembayaran asal.
+func applyRefundFee(refund *models.Transaction, original *models.Transaction, refundedFee money.Money, final bool) error {
+	gross := merchantAmount(refund)
+	fee := money.Zero(gross.Currency())
+
+	if original.Fee.IsPositive() && original.Gross.IsPositive() {
+		var err error
+		fee, err = original.Fee.MulRat(gross.Amount(), original.Gross.Amount())
+		if err != nil {
+			return err
+		}
+		if final {
+			fee, err = original.Fee.Sub(refundedFee)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return setFee(refund, gross, fee)
+}
+
+// Fungsi bantu untuk mengisi Gross, Fee, dan Net transaksi tanpa biaya seperti top-up dan transfer
+func applyNoFee(transaction *models.Transaction) {
+	_ = setFee(transaction, transaction.Amount, money.Zero(transaction.Amount.Currency()))
+}
+
+// Fungsi bantu untuk mengisi Gross, Fee, dan Net transaksi
+func setFee(transaction *models.Transaction, gross, fee money.Money) error {
+	net, err := gross.Sub(fee)
+	if err != nil {
+		return err
+	}
+
+	transaction.Gross = gross
+	transaction.Fee = fee
+	transaction.Net = net
+	return nil
+}
+
+// Fungsi bantu untuk memilih tingkatan biaya yang berlaku untuk jumlah pembayaran
+func feeTier(tiers []models.FeeTier, gross money.Money) (models.FeeTier, error) {
+	for _, tier := range tiers {
+		if tier.UpTo == nil {
+			return tier, nil
+		}
+		cmp, err := gross.Cmp(*tier.UpTo)
+		if err != nil {
+			return models.FeeTier{}, err
+		}
+		if cmp <= 0 {
+			return tier, nil
+		}
+	}
+
+	return models.FeeTier{}, fmt.Errorf("tidak ada tingkatan biaya untuk jumlah %s", gross)
+}
+
+// Fungsi bantu untuk memeriksa skema biaya dengan menghitung biaya dari jumlah nol dalam mata uang merchant,
+// skema kosong berarti tanpa biaya
+func validateFeeSchedule(schedule *models.FeeSchedule, currency string) error {
+	if schedule == nil {
+		return nil
+	}
+	for _, tier := range schedule.Tiers {
+		// Seluruh tingkatan diperiksa karena CalculateFee hanya menghitung tingkatan pertama untuk jumlah nol
+		_, err := feeComponents(money.Zero(currency), tier.Percentage, tier.Flat)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := CalculateFee(schedule, money.Zero(currency))
+	return err
+}
+
+// Fungsi bantu untuk membaca persentase dengan paling banyak MaxPercentageDecimals angka desimal sebagai pecahan
+func parsePercentage(percentage string) (*big.Rat, error) {
+	percentage = strings.TrimSpace(percentage)
+	if !percentagePattern.MatchString(percentage) {
+		return nil, fmt.Errorf("persentase %q tidak valid, gunakan bilangan tidak negatif dengan paling banyak %d angka desimal", percentage, MaxPercentageDecimals)
+	}
+	rate, ok := new(big.Rat).SetString(percentage)
+	if !ok {
+		return nil, fmt.Errorf("persentase %q tidak valid", percentage)
+	}
+	return rate, nil
+}
+
+// Fungsi bantu untuk menghitung biaya persentase ditambah biaya flat
+func feeComponents(gross money.Money, percentage string, flat *money.Money) (money.Money, error) {
+	fee := money.Zero(gross.Currency())
+
+	if percentage != "" {
+		rate, err := parsePercentage(percentage)
+		if err != nil {
+			return money.Money{}, err
+		}
+		// Persentase diubah menjadi pecahan dalam big.Rat agar penyebutnya tidak meluap
+		fee, err = gross.Scale(rate.Quo(rate, big.NewRat(100, 1)))
+		if err != nil {
+			return money.Money{}, fmt.Errorf("gagal menghitung biaya persentase: %w", err)
+		}
+	}
+
+	if flat != nil {
+		if flat.IsNegative() {
+			return money.Money{}, fmt.Errorf("biaya flat %s tidak valid", flat)
+		}
+		var err error
+		fee, err = fee.Add(*flat)
+		if err != nil {
+			return money.Money{}, fmt.Errorf("mata uang biaya flat tidak sesuai: %w", err)
+		}
+	}
+
+	return fee, nil
+}
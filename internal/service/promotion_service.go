@@ -0,0 +1,448 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// PromotionRequest adalah permintaan admin untuk membuat promosi. Customers berisi username atau nomor telepon
+// pelanggan yang boleh memakai promosi, kosong berarti semua pelanggan. StartsAt kosong berarti promosi berlaku
+// sejak dibuat dan EndsAt kosong berarti promosi tidak memiliki batas akhir.
+type PromotionRequest struct {
+	Name             string       `json:"name"`
+	Code             string       `json:"code"`
+	Type             string       `json:"type"`
+	Percentage       string       `json:"percentage"`
+	Flat             *money.Money `json:"flat"`
+	MaxBenefit       *money.Money `json:"max_benefit"`
+	MerchantID       string       `json:"merchant_id"`
+	MinAmount        *money.Money `json:"min_amount"`
+	Tiers            []string     `json:"tiers"`
+	Customers        []string     `json:"customers"`
+	Quota            int          `json:"quota"`
+	PerCustomerLimit int          `json:"per_customer_limit"`
+	StartsAt         *time.Time   `json:"starts_at"`
+	EndsAt           *time.Time   `json:"ends_at"`
+}
+
+// PromotionSummary adalah promosi beserta jumlah pemakaiannya. Used menghitung pemakaian yang berhasil dan
+// yang sedang dipesan oleh pembayaran yang masih diproses.
+type PromotionSummary struct {
+	models.Promotion
+	Used      int  `json:"used"`
+	Remaining *int `json:"remaining,omitempty"`
+}
+
+// PromotionService menangani promosi diskon dan cashback beserta kuotanya
+type PromotionService struct {
+	promotionRepository  repository.PromotionRepository
+	redemptionRepository repository.RedemptionRepository
+	customerRepository   repository.CustomerRepository
+	merchantRepository   repository.MerchantRepository
+
+	// mu memastikan pemeriksaan kuota dan pemesanannya tidak saling mendahului
+	// sehingga kuota tidak terlampaui oleh pembayaran bersamaan
+	mu sync.Mutex
+}
+
+// NewPromotionService membuat instance baru dari PromotionService
+func NewPromotionService(promotionRepository repository.PromotionRepository, redemptionRepository repository.RedemptionRepository, customerRepository repository.CustomerRepository, merchantRepository repository.MerchantRepository) *PromotionService {
+	return &PromotionService{
+		promotionRepository:  promotionRepository,
+		redemptionRepository: redemptionRepository,
+		customerRepository:   customerRepository,
+		merchantRepository:   merchantRepository,
+	}
+}
+
+// CreatePromotion membuat promosi baru yang langsung aktif
+func (s *PromotionService) CreatePromotion(req PromotionRequest) (*models.Promotion, error) {
+	log.Println("Membuat promosi...")
+
+	now := time.Now()
+	promotion := &models.Promotion{
+		ID:               "PRM" + generateTransactionID(),
+		Name:             strings.TrimSpace(req.Name),
+		Code:             strings.ToUpper(strings.TrimSpace(req.Code)),
+		Type:             req.Type,
+		Percentage:       strings.TrimSpace(req.Percentage),
+		Flat:             req.Flat,
+		MaxBenefit:       req.MaxBenefit,
+		MerchantID:       req.MerchantID,
+		MinAmount:        req.MinAmount,
+		Tiers:            req.Tiers,
+		Quota:            req.Quota,
+		PerCustomerLimit: req.PerCustomerLimit,
+		StartsAt:         now,
+		EndsAt:           req.EndsAt,
+		Active:           true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = *req.StartsAt
+	}
+
+	if promotion.Name == "" {
+		return nil, errors.New("nama promosi tidak boleh kosong")
+	}
+	if promotion.Type != models.PromotionTypeDiscount && promotion.Type != models.PromotionTypeCashback {
+		return nil, fmt.Errorf("jenis promosi %q tidak valid", promotion.Type)
+	}
+	if promotion.Percentage == "" && promotion.Flat == nil {
+		return nil, errors.New("persentase atau nilai flat promosi wajib diisi")
+	}
+	if promotion.Quota < 0 || promotion.PerCustomerLimit < 0 {
+		return nil, errors.New("kuota promosi tidak boleh kurang dari nol")
+	}
+	if promotion.EndsAt != nil && !promotion.EndsAt.After(promotion.StartsAt) {
+		return nil, errors.New("waktu berakhir promosi harus setelah waktu mulai")
+	}
+
+	// Persentase dan nilai flat diperiksa dengan menghitung manfaat dari jumlah contoh
+	currency := ""
+	for _, amount := range []*money.Money{promotion.Flat, promotion.MaxBenefit, promotion.MinAmount} {
+		if amount == nil {
+			continue
+		}
+		if amount.IsNegative() {
+			return nil, fmt.Errorf("nilai promosi %s tidak valid", amount)
+		}
+		if currency != "" && amount.Currency() != currency {
+			return nil, errors.New("seluruh nilai promosi harus dalam mata uang yang sama")
+		}
+		currency = amount.Currency()
+	}
+	_, err := feeComponents(money.Zero(currency), promotion.Percentage, promotion.Flat)
+	if err != nil {
+		return nil, err
+	}
+
+	if promotion.MerchantID != "" {
+		merchant, err := s.merchantRepository.GetByID(promotion.MerchantID)
+		if err != nil {
+			return nil, errors.New("ID merchant tidak valid")
+		}
+		if merchantCurrency := money.Zero(merchant.Currency).Currency(); currency != "" && currency != merchantCurrency {
+			return nil, fmt.Errorf("nilai promosi harus dalam %s sesuai mata uang merchant", merchantCurrency)
+		}
+	}
+
+	if promotion.Code != "" {
+		_, err := s.promotionRepository.GetByCode(promotion.Code)
+		if err == nil {
+			return nil, fmt.Errorf("kode voucher %s sudah digunakan", promotion.Code)
+		}
+	}
+
+	for _, identifier := range req.Customers {
+		customer, err := findCustomer(s.customerRepository, identifier)
+		if err != nil {
+			return nil, fmt.Errorf("pelanggan %s tidak ditemukan", identifier)
+		}
+		promotion.CustomerIDs = append(promotion.CustomerIDs, customer.ID)
+	}
+
+	err = s.promotionRepository.Save(promotion)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan promosi: %w", err)
+	}
+
+	log.Println("Promosi berhasil dibuat.")
+
+	return promotion, nil
+}
+
+// GetPromotions mengambil seluruh promosi beserta jumlah pemakaiannya
+func (s *PromotionService) GetPromotions() ([]PromotionSummary, error) {
+	promotions, err := s.promotionRepository.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan promosi: %w", err)
+	}
+
+	summaries := make([]PromotionSummary, 0, len(promotions))
+	for i := range promotions {
+		summary, err := s.summarize(&promotions[i])
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, *summary)
+	}
+	return summaries, nil
+}
+
+// GetPromotion mengambil promosi beserta jumlah pemakaiannya
+func (s *PromotionService) GetPromotion(promotionID string) (*PromotionSummary, error) {
+	promotion, err := s.promotionRepository.GetByID(promotionID)
+	if err != nil {
+		return nil, errors.New("ID promosi tidak valid")
+	}
+	return s.summarize(promotion)
+}
+
+// DeactivatePromotion menghentikan promosi sehingga tidak dapat dipakai lagi. Pemakaian yang sudah terjadi tetap tercatat.
+func (s *PromotionService) DeactivatePromotion(promotionID string) (*models.Promotion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	promotion, err := s.promotionRepository.GetByID(promotionID)
+	if err != nil {
+		return nil, errors.New("ID promosi tidak valid")
+	}
+	if !promotion.Active {
+		return nil, errors.New("promosi sudah tidak aktif")
+	}
+
+	promotion.Active = false
+	promotion.UpdatedAt = time.Now()
+	err = s.promotionRepository.Save(promotion)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan promosi: %w", err)
+	}
+	return promotion, nil
+}
+
+// Reserve memilih promosi untuk pembayaran dan memesan satu kuotanya. Jika kode voucher diisi hanya promosi
+// dengan kode tersebut yang dipertimbangkan dan pembayaran ditolak jika voucher tidak dapat dipakai. Tanpa kode
+// voucher dipilih promosi otomatis dengan manfaat terbesar, nil berarti tidak ada promosi yang berlaku.
+// Pemesanan harus diakhiri dengan Confirm jika pembayaran berhasil atau Release jika gagal.
+func (s *PromotionService) Reserve(customerID string, merchantID string, amount money.Money, code string) (*models.Promotion, *models.Redemption, error) {
+	customer, err := s.customerRepository.GetByID(customerID)
+	if err != nil {
+		return nil, nil, errors.New("ID customer tidak valid")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var best *models.Promotion
+	var benefit money.Money
+
+	code = strings.TrimSpace(code)
+	if code != "" {
+		best, err = s.promotionRepository.GetByCode(code)
+		if err != nil {
+			return nil, nil, fmt.Errorf("kode voucher %s tidak valid", code)
+		}
+		benefit, err = s.eligibleBenefit(best, customer, merchantID, amount, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("voucher %s tidak dapat digunakan: %w", code, err)
+		}
+	} else {
+		promotions, err := s.promotionRepository.GetAll()
+		if err != nil {
+			return nil, nil, fmt.Errorf("gagal mendapatkan promosi: %w", err)
+		}
+		for i := range promotions {
+			promotion := &promotions[i]
+			if promotion.Code != "" {
+				continue
+			}
+			candidate, err := s.eligibleBenefit(promotion, customer, merchantID, amount, now)
+			if err != nil {
+				continue
+			}
+			if best == nil {
+				best, benefit = promotion, candidate
+				continue
+			}
+			if cmp, err := candidate.Cmp(benefit); err == nil && cmp > 0 {
+				best, benefit = promotion, candidate
+			}
+		}
+		if best == nil {
+			return nil, nil, nil
+		}
+	}
+
+	redemption := &models.Redemption{
+		ID:          "RDM" + generateTransactionID(),
+		PromotionID: best.ID,
+		CustomerID:  customer.ID,
+		Benefit:     benefit,
+		Status:      models.RedemptionStatusReserved,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.redemptionRepository.Save(redemption)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal memesan kuota promosi: %w", err)
+	}
+
+	return best, redemption, nil
+}
+
+// Confirm menandai pemakaian promosi berhasil untuk transaksi pembayaran
+func (s *PromotionService) Confirm(redemption *models.Redemption, transactionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	redemption.TransactionID = transactionID
+	redemption.Status = models.RedemptionStatusRedeemed
+	redemption.UpdatedAt = time.Now()
+	return s.redemptionRepository.Save(redemption)
+}
+
+// Release mengembalikan kuota yang dipesan oleh pembayaran yang gagal
+func (s *PromotionService) Release(redemption *models.Redemption, transactionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	redemption.TransactionID = transactionID
+	redemption.Status = models.RedemptionStatusReleased
+	redemption.UpdatedAt = time.Now()
+	err := s.redemptionRepository.Save(redemption)
+	if err != nil {
+		log.Println("Gagal mengembalikan kuota promosi", redemption.PromotionID, ":", err)
+	}
+}
+
+// Fungsi bantu untuk mengambil pemakaian promosi yang kuotanya masih dipesan
+func (s *PromotionService) reserved() ([]models.Redemption, error) {
+	redemptions, err := s.redemptionRepository.GetReserved()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pemakaian promosi: %w", err)
+	}
+	return redemptions, nil
+}
+
+// Fungsi bantu untuk memeriksa apakah transaksi pembayaran sudah tercatat pada pemakaian promosi
+func (s *PromotionService) recorded(transactionID string) bool {
+	_, err := s.redemptionRepository.GetByTransactionID(transactionID)
+	return err == nil
+}
+
+// Fungsi bantu untuk memeriksa apakah promosi berlaku untuk pembayaran lalu menghitung manfaatnya.
+// Harus dipanggil ketika mutex sudah dikunci agar penghitungan kuota akurat.
+func (s *PromotionService) eligibleBenefit(promotion *models.Promotion, customer *models.Customer, merchantID string, amount money.Money, now time.Time) (money.Money, error) {
+	if !promotion.Active {
+		return money.Money{}, errors.New("promosi tidak aktif")
+	}
+	if now.Before(promotion.StartsAt) || (promotion.EndsAt != nil && !now.Before(*promotion.EndsAt)) {
+		return money.Money{}, errors.New("promosi di luar masa berlaku")
+	}
+	if promotion.MerchantID != "" && promotion.MerchantID != merchantID {
+		return money.Money{}, errors.New("promosi tidak berlaku untuk merchant ini")
+	}
+	if len(promotion.Tiers) > 0 && !containsString(promotion.Tiers, customer.TierName()) {
+		return money.Money{}, errors.New("promosi tidak berlaku untuk tier pelanggan")
+	}
+	if len(promotion.CustomerIDs) > 0 && !containsString(promotion.CustomerIDs, customer.ID) {
+		return money.Money{}, errors.New("promosi tidak berlaku untuk pelanggan ini")
+	}
+	if promotion.MinAmount != nil {
+		cmp, err := amount.Cmp(*promotion.MinAmount)
+		if err != nil {
+			return money.Money{}, errors.New("mata uang pembayaran tidak sesuai dengan promosi")
+		}
+		if cmp < 0 {
+			return money.Money{}, fmt.Errorf("minimal pembayaran %s", promotion.MinAmount)
+		}
+	}
+
+	benefit, err := promotionBenefit(promotion, amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !benefit.IsPositive() {
+		return money.Money{}, errors.New("promosi tidak memberikan manfaat untuk jumlah ini")
+	}
+
+	// Kuota dihitung dari pemakaian yang berhasil maupun yang sedang dipesan
+	if promotion.Quota > 0 || promotion.PerCustomerLimit > 0 {
+		total, perCustomer, err := s.usage(promotion.ID, customer.ID)
+		if err != nil {
+			return money.Money{}, err
+		}
+		if promotion.Quota > 0 && total >= promotion.Quota {
+			return money.Money{}, errors.New("kuota promosi sudah habis")
+		}
+		if promotion.PerCustomerLimit > 0 && perCustomer >= promotion.PerCustomerLimit {
+			return money.Money{}, errors.New("batas pemakaian promosi per pelanggan sudah tercapai")
+		}
+	}
+
+	return benefit, nil
+}
+
+// Fungsi bantu untuk menghitung pemakaian promosi yang belum dikembalikan, total dan milik pelanggan tertentu
+func (s *PromotionService) usage(promotionID string, customerID string) (int, int, error) {
+	redemptions, err := s.redemptionRepository.GetByPromotionID(promotionID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("gagal mendapatkan pemakaian promosi: %w", err)
+	}
+
+	total, perCustomer := 0, 0
+	for _, redemption := range redemptions {
+		if redemption.Status == models.RedemptionStatusReleased {
+			continue
+		}
+		total++
+		if redemption.CustomerID == customerID {
+			perCustomer++
+		}
+	}
+	return total, perCustomer, nil
+}
+
+// Fungsi bantu untuk melengkapi promosi dengan jumlah pemakaian dan sisa kuotanya
+func (s *PromotionService) summarize(promotion *models.Promotion) (*PromotionSummary, error) {
+	used, _, err := s.usage(promotion.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &PromotionSummary{Promotion: *promotion, Used: used}
+	if promotion.Quota > 0 {
+		remaining := promotion.Quota - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		summary.Remaining = &remaining
+	}
+	return summary, nil
+}
+
+// Fungsi bantu untuk menghitung manfaat promosi dari jumlah pembayaran: persentase ditambah nilai flat,
+// dibatasi oleh MaxBenefit dan tidak pernah melebihi jumlah pembayaran
+func promotionBenefit(promotion *models.Promotion, amount money.Money) (money.Money, error) {
+	benefit, err := feeComponents(amount, promotion.Percentage, promotion.Flat)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	limits := []money.Money{amount}
+	if promotion.MaxBenefit != nil {
+		limits = append(limits, *promotion.MaxBenefit)
+	}
+	for _, limit := range limits {
+		cmp, err := benefit.Cmp(limit)
+		if err != nil {
+			return money.Money{}, errors.New("mata uang pembayaran tidak sesuai dengan promosi")
+		}
+		if cmp > 0 {
+			benefit = limit
+		}
+	}
+
+	return benefit, nil
+}
+
+// Fungsi bantu untuk memeriksa apakah daftar berisi nilai tertentu
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/pdf"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// ErrReceiptNotFound dikembalikan ketika transaksi tidak ditemukan atau bukan milik pelanggan
+var ErrReceiptNotFound = errors.New("struk transaksi tidak ditemukan")
+
+// Receipt adalah struk transaksi pelanggan. Amount dalam mata uang wallet, Gross, Fee, dan Net dalam mata uang
+// merchant. VerificationCode dihitung dari data transaksi sehingga struk dapat dicocokkan dengan VerifyReceipt.
+type Receipt struct {
+	TransactionID    string       `json:"transaction_id"`
+	Type             string       `json:"type"`
+	Status           string       `json:"status"`
+	CustomerID       string       `json:"customer_id"`
+	CustomerName     string       `json:"customer_name"`
+	MerchantID       string       `json:"merchant_id,omitempty"`
+	MerchantName     string       `json:"merchant_name,omitempty"`
+	CounterpartyName string       `json:"counterparty_name,omitempty"`
+	Amount           money.Money  `json:"amount"`
+	OriginalAmount   *money.Money `json:"original_amount,omitempty"`
+	ExchangeRate     string       `json:"exchange_rate,omitempty"`
+	Gross            money.Money  `json:"gross"`
+	Fee              money.Money  `json:"fee"`
+	Net              money.Money  `json:"net"`
+	Discount         *money.Money `json:"discount,omitempty"`
+	PointsDiscount   *money.Money `json:"points_discount,omitempty"`
+	Cashback         *money.Money `json:"cashback,omitempty"`
+	PointsEarned     int64        `json:"points_earned,omitempty"`
+	Description      string       `json:"description,omitempty"`
+	CreatedAt        time.Time    `json:"created_at"`
+	VerificationCode string       `json:"verification_code"`
+}
+
+// ReceiptService membuat struk transaksi dalam format teks, HTML, dan PDF
+type ReceiptService struct {
+	transactionRepository *repository.TransactionRepository
+	customerRepository    repository.CustomerRepository
+	merchantRepository    repository.MerchantRepository
+	secret                []byte
+}
+
+// NewReceiptService membuat instance baru dari ReceiptService. secret digunakan untuk menghitung kode verifikasi
+// struk sehingga harus tetap sama setelah aplikasi dijalankan ulang.
+func NewReceiptService(transactionRepository *repository.TransactionRepository, customerRepository repository.CustomerRepository, merchantRepository repository.MerchantRepository, secret string) *ReceiptService {
+	return &ReceiptService{
+		transactionRepository: transactionRepository,
+		customerRepository:    customerRepository,
+		merchantRepository:    merchantRepository,
+		secret:                []byte(secret),
+	}
+}
+
+// GetReceipt membuat struk transaksi milik pelanggan. Transaksi milik pelanggan lain dianggap tidak ditemukan.
+func (s *ReceiptService) GetReceipt(customerID string, transactionID string) (*Receipt, error) {
+	transaction, err := s.transactionRepository.GetTransactionByID(transactionID)
+	if err != nil || transaction.CustomerID != customerID {
+		return nil, ErrReceiptNotFound
+	}
+
+	receipt := &Receipt{
+		TransactionID:    transaction.ID,
+		Type:             transaction.Type,
+		Status:           TransactionStatus(transaction),
+		CustomerID:       transaction.CustomerID,
+		MerchantID:       transaction.MerchantID,
+		Amount:           transaction.Amount,
+		OriginalAmount:   transaction.OriginalAmount,
+		ExchangeRate:     transaction.ExchangeRate,
+		Gross:            transaction.Gross,
+		Fee:              transaction.Fee,
+		Net:              transaction.Net,
+		Discount:         transaction.Discount,
+		PointsDiscount:   transaction.PointsDiscount,
+		Cashback:         transaction.Cashback,
+		PointsEarned:     transaction.PointsEarned,
+		Description:      transaction.Description,
+		CreatedAt:        transaction.CreatedAt,
+		VerificationCode: s.verificationCode(transaction),
+	}
+	if receipt.Type == "" {
+		receipt.Type = models.TransactionTypePayment
+	}
+	if customer, err := s.customerRepository.GetByID(transaction.CustomerID); err == nil {
+		receipt.CustomerName = customer.Name
+	}
+	if transaction.MerchantID != "" {
+		if merchant, err := s.merchantRepository.GetByID(transaction.MerchantID); err == nil {
+			receipt.MerchantName = merchant.Name
+		}
+	}
+	if transaction.CounterpartyID != "" {
+		if counterparty, err := s.customerRepository.GetByID(transaction.CounterpartyID); err == nil {
+			receipt.CounterpartyName = counterparty.Name
+		}
+	}
+
+	return receipt, nil
+}
+
+// VerifyReceipt memeriksa apakah kode verifikasi sesuai dengan struk transaksi
+func (s *ReceiptService) VerifyReceipt(transactionID string, code string) bool {
+	transaction, err := s.transactionRepository.GetTransactionByID(transactionID)
+	if err != nil {
+		return false
+	}
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	expected := strings.ReplaceAll(s.verificationCode(transaction), "-", "")
+	return hmac.Equal([]byte(normalized), []byte(expected))
+}
+
+// RenderText membuat struk dalam format teks biasa
+func (s *ReceiptService) RenderText(receipt *Receipt) []byte {
+	var b strings.Builder
+	b.WriteString("STRUK TRANSAKSI\n")
+	b.WriteString(strings.Repeat("=", 48) + "\n")
+	for _, line := range receiptLines(receipt) {
+		if line[0] == "" {
+			b.WriteString(strings.Repeat("-", 48) + "\n")
+			continue
+		}
+		fmt.Fprintf(&b, "%-20s %s\n", line[0], line[1])
+	}
+	b.WriteString(strings.Repeat("=", 48) + "\n")
+	fmt.Fprintf(&b, "%-20s %s\n", "Kode verifikasi", receipt.VerificationCode)
+	return []byte(b.String())
+}
+
+// receiptTemplate adalah template HTML struk, baris dengan label kosong digambar sebagai garis pemisah
+var receiptTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>Struk {{.Receipt.TransactionID}}</title>
+<style>
+body { font-family: sans-serif; max-width: 480px; margin: 2em auto; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: 4px 0; }
+td.value { text-align: right; }
+tr.separator td { border-top: 1px solid #ccc; }
+.code { font-family: monospace; font-size: 1.2em; }
+</style>
+</head>
+<body>
+<h1>Struk Transaksi</h1>
+<table>
+{{- range .Lines}}
+{{- if index . 0}}
+<tr><td>{{index . 0}}</td><td class="value">{{index . 1}}</td></tr>
+{{- else}}
+<tr class="separator"><td colspan="2"></td></tr>
+{{- end}}
+{{- end}}
+</table>
+<p>Kode verifikasi: <span class="code">{{.Receipt.VerificationCode}}</span></p>
+</body>
+</html>
+`))
+
+// RenderHTML membuat struk dalam format HTML
+func (s *ReceiptService) RenderHTML(receipt *Receipt) ([]byte, error) {
+	var buf bytes.Buffer
+	err := receiptTemplate.Execute(&buf, struct {
+		Receipt *Receipt
+		Lines   [][2]string
+	}{receipt, receiptLines(receipt)})
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat struk HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPDF membuat struk dalam format PDF
+func (s *ReceiptService) RenderPDF(receipt *Receipt) []byte {
+	document := pdf.New("Struk " + receipt.TransactionID)
+	document.SetFont(pdf.FontBold, 16)
+	document.Writeln("Struk Transaksi")
+	document.Rule()
+	for _, line := range receiptLines(receipt) {
+		if line[0] == "" {
+			document.Rule()
+			continue
+		}
+		document.SetFont(pdf.FontRegular, 11)
+		document.Row([]float64{pdf.Margin, 220}, line[0], line[1])
+	}
+	document.Rule()
+	document.SetFont(pdf.FontRegular, 11)
+	document.Writeln("Kode verifikasi")
+	document.SetFont(pdf.FontMono, 14)
+	document.Writeln(receipt.VerificationCode)
+	return document.Bytes()
+}
+
+// Fungsi bantu untuk menyusun baris label dan nilai struk. Label kosong menandai garis pemisah.
+func receiptLines(receipt *Receipt) [][2]string {
+	lines := [][2]string{
+		{"ID transaksi", receipt.TransactionID},
+		{"Waktu", receipt.CreatedAt.Format("02-01-2006 15:04:05 MST")},
+		{"Jenis", receipt.Type},
+		{"Status", receipt.Status},
+		{"Pelanggan", receipt.CustomerName},
+	}
+	if receipt.MerchantName != "" {
+		lines = append(lines, [2]string{"Merchant", receipt.MerchantName})
+	}
+	if receipt.CounterpartyName != "" {
+		lines = append(lines, [2]string{"Pihak lawan", receipt.CounterpartyName})
+	}
+	lines = append(lines, [2]string{}, [2]string{"Jumlah", receipt.Amount.String()})
+	if receipt.OriginalAmount != nil {
+		lines = append(lines,
+			[2]string{"Jumlah merchant", receipt.OriginalAmount.String()},
+			[2]string{"Kurs", receipt.ExchangeRate},
+		)
+	}
+	if receipt.Discount != nil {
+		lines = append(lines, [2]string{"Diskon", receipt.Discount.String()})
+	}
+	if receipt.PointsDiscount != nil {
+		lines = append(lines, [2]string{"Potongan poin", receipt.PointsDiscount.String()})
+	}
+	if receipt.Cashback != nil {
+		lines = append(lines, [2]string{"Cashback", receipt.Cashback.String()})
+	}
+	if receipt.MerchantID != "" {
+		lines = append(lines,
+			[2]string{"Biaya merchant", receipt.Fee.String()},
+			[2]string{"Diterima merchant", receipt.Net.String()},
+		)
+	}
+	if receipt.PointsEarned > 0 {
+		lines = append(lines, [2]string{"Poin didapat", fmt.Sprintf("%d", receipt.PointsEarned)})
+	}
+	return lines
+}
+
+// Fungsi bantu untuk menghitung kode verifikasi struk, yaitu HMAC-SHA256 dari data transaksi yang tidak berubah
+// dengan secret struk, ditulis sebagai 12 karakter base32 dalam tiga kelompok. Jumlah yang digunakan adalah jumlah
+// yang ditahan saat otorisasi karena Amount berubah ketika otorisasi di-capture sebagian, sehingga kode verifikasi
+// struk yang diterbitkan sebelum capture tetap berlaku.
+func (s *ReceiptService) verificationCode(transaction *models.Transaction) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s|%s|%s|%s|%s", transaction.ID, transaction.CustomerID, transaction.MerchantID,
+		holdAmount(transaction), transaction.CreatedAt.UTC().Format(time.RFC3339Nano))
+	code := base32.StdEncoding.EncodeToString(mac.Sum(nil))[:12]
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12]
+}
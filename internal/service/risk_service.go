@@ -0,0 +1,111 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/risk"
+)
+
+// RiskError dikembalikan ketika pembayaran ditolak oleh pemeriksaan risiko
+type RiskError struct {
+	Reasons []models.RiskReason
+}
+
+func (e *RiskError) Error() string {
+	messages := make([]string, 0, len(e.Reasons))
+	for _, reason := range e.Reasons {
+		if reason.Decision == models.RiskDecisionDeny {
+			messages = append(messages, reason.Message)
+		}
+	}
+	return "pembayaran ditolak oleh pemeriksaan risiko: " + strings.Join(messages, "; ")
+}
+
+// RiskService menjalankan engine risiko terhadap pembayaran dan mencatat pembayaran yang ditolak atau ditandai
+type RiskService struct {
+	engine                *risk.Engine
+	eventRepository       repository.RiskEventRepository
+	transactionRepository *repository.TransactionRepository
+}
+
+// NewRiskService membuat instance baru dari RiskService
+func NewRiskService(engine *risk.Engine, eventRepository repository.RiskEventRepository, transactionRepository *repository.TransactionRepository) *RiskService {
+	return &RiskService{
+		engine:                engine,
+		eventRepository:       eventRepository,
+		transactionRepository: transactionRepository,
+	}
+}
+
+// Assess memeriksa pembayaran dengan seluruh aturan risiko berdasarkan riwayat pembayaran pelanggan
+func (s *RiskService) Assess(transaction *models.Transaction) (risk.Assessment, error) {
+	transactions, err := s.transactionRepository.GetTransactionsByCustomerID(transaction.CustomerID)
+	if err != nil {
+		return risk.Assessment{}, fmt.Errorf("gagal mendapatkan riwayat pembayaran: %w", err)
+	}
+
+	history := make([]models.Transaction, 0, len(transactions))
+	for _, previous := range transactions {
+		if previous.ID == transaction.ID {
+			continue
+		}
+		if previous.Type == "" || previous.Type == models.TransactionTypePayment {
+			history = append(history, previous)
+		}
+	}
+
+	return s.engine.Evaluate(risk.Attempt{
+		CustomerID: transaction.CustomerID,
+		MerchantID: transaction.MerchantID,
+		Amount:     transaction.Amount,
+		DeviceID:   transaction.DeviceID,
+		At:         transaction.CreatedAt,
+		History:    history,
+	}), nil
+}
+
+// Record mencatat hasil pemeriksaan risiko pembayaran yang ditolak atau ditandai
+func (s *RiskService) Record(transaction *models.Transaction, assessment risk.Assessment) error {
+	event := &models.RiskEvent{
+		ID:         "RSK" + generateTransactionID(),
+		CustomerID: transaction.CustomerID,
+		MerchantID: transaction.MerchantID,
+		Amount:     transaction.Amount,
+		DeviceID:   transaction.DeviceID,
+		Decision:   assessment.Decision,
+		Reasons:    assessment.Reasons,
+		CreatedAt:  time.Now(),
+	}
+	if assessment.Decision != models.RiskDecisionDeny {
+		event.TransactionID = transaction.ID
+	}
+
+	err := s.eventRepository.Save(event)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat pemeriksaan risiko: %w", err)
+	}
+	return nil
+}
+
+// GetEvents mengambil catatan pemeriksaan risiko diurutkan dari yang terbaru, decision kosong berarti seluruh keputusan
+func (s *RiskService) GetEvents(decision string) ([]models.RiskEvent, error) {
+	events, err := s.eventRepository.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan catatan risiko: %w", err)
+	}
+	if decision == "" {
+		return events, nil
+	}
+
+	filtered := make([]models.RiskEvent, 0, len(events))
+	for _, event := range events {
+		if event.Decision == decision {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// Pengaturan percobaan ulang pembayaran terjadwal yang gagal. Jeda percobaan berlipat dua
+// setiap kali gagal, dimulai dari ScheduleRetryDelay dan tidak lebih dari ScheduleMaxRetryDelay.
+const (
+	ScheduleMaxAttempts   = 5
+	ScheduleRetryDelay    = time.Minute
+	ScheduleMaxRetryDelay = time.Hour
+)
+
+// ScheduleService menangani pembayaran terjadwal dan berulang ke merchant
+type ScheduleService struct {
+	scheduleRepository repository.ScheduleRepository
+	transactionService *TransactionService
+
+	// mu memastikan eksekusi dan perubahan pembayaran terjadwal tidak saling mendahului
+	mu sync.Mutex
+}
+
+// NewScheduleService membuat instance baru dari ScheduleService
+func NewScheduleService(scheduleRepository repository.ScheduleRepository, transactionService *TransactionService) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepository: scheduleRepository,
+		transactionService: transactionService,
+	}
+}
+
+// CreateSchedule membuat pembayaran terjadwal baru. startAt kosong berarti dijalankan secepatnya
+// dan endAt (boleh nil) adalah batas akhir pembayaran berulang.
+func (s *ScheduleService) CreateSchedule(customerID string, merchantID string, amount money.Money, frequency string, startAt time.Time, endAt *time.Time) (*models.Schedule, error) {
+	log.Println("Membuat pembayaran terjadwal...")
+
+	_, err := s.transactionService.validatePayment(customerID, merchantID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if frequency == "" {
+		frequency = models.ScheduleFrequencyOnce
+	}
+	if frequency != models.ScheduleFrequencyOnce && frequency != models.ScheduleFrequencyWeekly && frequency != models.ScheduleFrequencyMonthly {
+		return nil, fmt.Errorf("frekuensi %s tidak valid, gunakan once, weekly, atau monthly", frequency)
+	}
+	if startAt.IsZero() {
+		startAt = now
+	}
+	if startAt.Before(now.Add(-time.Minute)) {
+		return nil, errors.New("waktu mulai tidak boleh di masa lalu")
+	}
+	if endAt != nil && endAt.Before(startAt) {
+		return nil, errors.New("waktu berakhir tidak boleh sebelum waktu mulai")
+	}
+
+	schedule := &models.Schedule{
+		ID:           "SCH" + generateTransactionID(),
+		CustomerID:   customerID,
+		MerchantID:   merchantID,
+		Amount:       amount,
+		Frequency:    frequency,
+		StartAt:      startAt,
+		EndAt:        endAt,
+		OccurrenceAt: startAt,
+		NextRunAt:    &startAt,
+		Status:       models.ScheduleStatusActive,
+		Runs:         make([]models.ScheduleRun, 0),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	err = s.scheduleRepository.Save(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pembayaran terjadwal: %w", err)
+	}
+
+	log.Println("Pembayaran terjadwal berhasil dibuat.")
+
+	return schedule, nil
+}
+
+// GetSchedules mengambil semua pembayaran terjadwal milik pelanggan
+func (s *ScheduleService) GetSchedules(customerID string) ([]models.Schedule, error) {
+	schedules, err := s.scheduleRepository.GetByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pembayaran terjadwal: %w", err)
+	}
+	return schedules, nil
+}
+
+// GetSchedule mengambil pembayaran terjadwal milik pelanggan beserta riwayat eksekusinya
+func (s *ScheduleService) GetSchedule(customerID string, scheduleID string) (*models.Schedule, error) {
+	schedule, err := s.scheduleRepository.GetByID(scheduleID)
+	if err != nil {
+		return nil, errors.New("ID pembayaran terjadwal tidak valid")
+	}
+	if schedule.CustomerID != customerID {
+		return nil, errors.New("pembayaran terjadwal bukan milik customer")
+	}
+	return schedule, nil
+}
+
+// CancelSchedule membatalkan pembayaran terjadwal yang masih aktif
+func (s *ScheduleService) CancelSchedule(customerID string, scheduleID string) (*models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.GetSchedule(customerID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != models.ScheduleStatusActive {
+		return nil, fmt.Errorf("pembayaran terjadwal dengan status %s tidak dapat dibatalkan", schedule.Status)
+	}
+
+	schedule.Status = models.ScheduleStatusCancelled
+	schedule.NextRunAt = nil
+	schedule.UpdatedAt = time.Now()
+	err = s.scheduleRepository.Save(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pembayaran terjadwal: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// RunDue menjalankan semua pembayaran terjadwal yang waktunya sudah tiba dan mengembalikan jumlah yang dijalankan
+func (s *ScheduleService) RunDue(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.scheduleRepository.GetDue(now)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan pembayaran terjadwal: %w", err)
+	}
+
+	for i := range schedules {
+		err = s.run(&schedules[i], now)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(schedules), nil
+}
+
+// RecoverInterrupted menandai pembayaran terjadwal yang terputus di tengah eksekusi (contoh server mati)
+// sebagai interrupted lalu melanjutkan ke jadwal berikutnya. Pembayaran tersebut tidak diulang karena
+// tidak dapat dipastikan apakah saldo sudah didebit, sehingga pelanggan tidak tertagih dua kali.
+func (s *ScheduleService) RecoverInterrupted(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.scheduleRepository.GetRunning()
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan pembayaran terjadwal: %w", err)
+	}
+
+	for i := range schedules {
+		schedule := &schedules[i]
+		schedule.RunningSince = nil
+		recordRun(schedule, now, models.ScheduleRunInterrupted, "", "eksekusi terputus, periksa riwayat transaksi")
+		advanceSchedule(schedule, now)
+		err = s.scheduleRepository.Save(schedule)
+		if err != nil {
+			return i, fmt.Errorf("gagal menyimpan pembayaran terjadwal: %w", err)
+		}
+	}
+
+	return len(schedules), nil
+}
+
+// StartScheduler memulihkan eksekusi yang terputus lalu menjalankan pembayaran terjadwal yang jatuh tempo
+// secara berkala di background. Fungsi yang dikembalikan digunakan untuk menghentikan scheduler.
+func (s *ScheduleService) StartScheduler(interval time.Duration) (stop func()) {
+	interrupted, err := s.RecoverInterrupted(time.Now())
+	if err != nil {
+		log.Println("Gagal memulihkan pembayaran terjadwal:", err)
+	} else if interrupted > 0 {
+		log.Println("Pembayaran terjadwal terputus ditandai:", interrupted)
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				ran, err := s.RunDue(now)
+				if err != nil {
+					log.Println("Gagal menjalankan pembayaran terjadwal:", err)
+				} else if ran > 0 {
+					log.Println("Pembayaran terjadwal dijalankan:", ran)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Fungsi bantu untuk menjalankan satu pembayaran terjadwal. Harus dipanggil ketika mu sudah dikunci.
+func (s *ScheduleService) run(schedule *models.Schedule, now time.Time) error {
+	// Menandai eksekusi sebelum pembayaran sehingga eksekusi yang terputus dapat dikenali setelah restart
+	schedule.RunningSince = &now
+	schedule.Attempts++
+	err := s.scheduleRepository.Save(schedule)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan pembayaran terjadwal: %w", err)
+	}
+
+	transaction, payErr := s.transactionService.ProcessTransaction(schedule.CustomerID, schedule.MerchantID, schedule.Amount, PaymentOptions{})
+	schedule.RunningSince = nil
+
+	transactionID := ""
+	if transaction != nil {
+		transactionID = transaction.ID
+	}
+	if payErr == nil {
+		recordRun(schedule, now, models.ScheduleRunSucceeded, transactionID, "")
+		advanceSchedule(schedule, now)
+	} else {
+		log.Println("Pembayaran terjadwal", schedule.ID, "gagal:", payErr)
+		recordRun(schedule, now, models.ScheduleRunFailed, transactionID, payErr.Error())
+		if schedule.Attempts < ScheduleMaxAttempts {
+			retryAt := now.Add(retryDelay(schedule.Attempts))
+			schedule.NextRunAt = &retryAt
+		} else {
+			// Percobaan habis, jadwal ini dilewati dan pembayaran berulang lanjut ke jadwal berikutnya
+			advanceSchedule(schedule, now)
+			if schedule.Status == models.ScheduleStatusCompleted && schedule.Frequency == models.ScheduleFrequencyOnce {
+				schedule.Status = models.ScheduleStatusFailed
+			}
+		}
+	}
+
+	err = s.scheduleRepository.Save(schedule)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan pembayaran terjadwal: %w", err)
+	}
+	return nil
+}
+
+// Fungsi bantu untuk mencatat riwayat eksekusi pembayaran terjadwal
+func recordRun(schedule *models.Schedule, now time.Time, status string, transactionID string, message string) {
+	schedule.Runs = append(schedule.Runs, models.ScheduleRun{
+		OccurrenceAt:  schedule.OccurrenceAt,
+		At:            now,
+		Attempt:       schedule.Attempts,
+		Status:        status,
+		TransactionID: transactionID,
+		Error:         message,
+	})
+	schedule.UpdatedAt = now
+}
+
+// Fungsi bantu untuk memindahkan pembayaran terjadwal ke jadwal berikutnya yang belum terlewati.
+// Jadwal yang terlewati ketika server mati tidak dijalankan susul agar pelanggan tidak tertagih beberapa kali sekaligus.
+func advanceSchedule(schedule *models.Schedule, now time.Time) {
+	schedule.Attempts = 0
+	schedule.NextRunAt = nil
+	if schedule.Frequency == models.ScheduleFrequencyOnce {
+		schedule.Status = models.ScheduleStatusCompleted
+		return
+	}
+
+	for {
+		schedule.Occurrences++
+		schedule.OccurrenceAt = occurrence(schedule.StartAt, schedule.Frequency, schedule.Occurrences)
+		if schedule.OccurrenceAt.After(now) {
+			break
+		}
+	}
+	if schedule.EndAt != nil && schedule.OccurrenceAt.After(*schedule.EndAt) {
+		schedule.Status = models.ScheduleStatusCompleted
+		return
+	}
+
+	next := schedule.OccurrenceAt
+	schedule.NextRunAt = &next
+}
+
+// Fungsi bantu untuk menghitung jadwal ke-n dari waktu mulai. Jadwal bulanan pada tanggal yang tidak ada
+// di bulan tersebut (contoh tanggal 31) dijalankan pada hari terakhir bulan itu.
+func occurrence(startAt time.Time, frequency string, n int) time.Time {
+	if frequency == models.ScheduleFrequencyWeekly {
+		return startAt.AddDate(0, 0, 7*n)
+	}
+
+	firstOfMonth := time.Date(startAt.Year(), startAt.Month()+time.Month(n), 1, startAt.Hour(), startAt.Minute(), startAt.Second(), startAt.Nanosecond(), startAt.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := startAt.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// Fungsi bantu untuk menghitung jeda sebelum percobaan berikutnya setelah gagal sebanyak attempts kali
+func retryDelay(attempts int) time.Duration {
+	return backoff(ScheduleRetryDelay, ScheduleMaxRetryDelay, attempts)
+}
+
+// Fungsi bantu untuk menghitung jeda eksponensial: base digandakan setiap percobaan gagal setelah yang pertama
+// dan dibatasi maksimal max
+func backoff(base time.Duration, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/ledger"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// DefaultSettlementPeriod adalah panjang periode default satu batch settlement
+const DefaultSettlementPeriod = 24 * time.Hour
+
+// SettlementLine adalah satu transaksi di dalam laporan settlement.
+// Untuk refund, Gross, Fee, dan Net bernilai negatif karena mengurangi jumlah yang dibayarkan.
+type SettlementLine struct {
+	TransactionID string      `json:"transaction_id"`
+	Type          string      `json:"type"`
+	CustomerID    string      `json:"customer_id"`
+	OriginalID    string      `json:"original_id,omitempty"`
+	SettledAt     time.Time   `json:"settled_at"`
+	Gross         money.Money `json:"gross"`
+	Fee           money.Money `json:"fee"`
+	Net           money.Money `json:"net"`
+}
+
+// SettlementReport berisi batch settlement beserta rincian transaksinya
+type SettlementReport struct {
+	Batch        models.SettlementBatch `json:"batch"`
+	MerchantName string                 `json:"merchant_name"`
+	Lines        []SettlementLine       `json:"transactions"`
+}
+
+// SettlementService mengelompokkan transaksi merchant ke dalam batch settlement per periode cut-off
+type SettlementService struct {
+	settlementRepository  repository.SettlementRepository
+	transactionRepository *repository.TransactionRepository
+	merchantRepository    repository.MerchantRepository
+	ledger                *ledger.Ledger
+
+	// mu memastikan pembuatan dan pembayaran batch tidak berjalan bersamaan
+	mu         sync.Mutex
+	period     time.Duration
+	cutoffHour int
+}
+
+// NewSettlementService membuat instance baru dari SettlementService dengan periode harian dan cut-off tengah malam
+func NewSettlementService(settlementRepository repository.SettlementRepository, transactionRepository *repository.TransactionRepository, merchantRepository repository.MerchantRepository, ledger *ledger.Ledger) *SettlementService {
+	return &SettlementService{
+		settlementRepository:  settlementRepository,
+		transactionRepository: transactionRepository,
+		merchantRepository:    merchantRepository,
+		ledger:                ledger,
+		period:                DefaultSettlementPeriod,
+	}
+}
+
+// SetSchedule mengatur panjang periode settlement dan jam cut-off (0-23) waktu lokal
+func (s *SettlementService) SetSchedule(period time.Duration, cutoffHour int) error {
+	if period <= 0 {
+		return errors.New("periode settlement harus lebih dari 0")
+	}
+	if cutoffHour < 0 || cutoffHour > 23 {
+		return errors.New("jam cut-off harus di antara 0 dan 23")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.period = period
+	s.cutoffHour = cutoffHour
+	return nil
+}
+
+// LastCutoff mengembalikan waktu cut-off terakhir yang tidak melewati now
+func (s *SettlementService) LastCutoff(now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastCutoff(now)
+}
+
+// CreateBatches membuat batch settlement untuk seluruh transaksi merchant yang belum di-settle
+// dan terjadi sebelum cutoff. Transaksi dikelompokkan per merchant, mata uang, dan periode,
+// lalu ditandai dengan ID batch sehingga tidak pernah dibayarkan dua kali.
+func (s *SettlementService) CreateBatches(cutoff time.Time) ([]models.SettlementBatch, error) {
+	log.Println("Membuat batch settlement...")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transactions, err := s.transactionRepository.GetAllTransactions()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+
+	// Mengelompokkan transaksi yang belum di-settle per merchant, mata uang, dan periode
+	groups := make(map[string]*models.SettlementBatch)
+	keys := make([]string, 0)
+	for i := range transactions {
+		transaction := &transactions[i]
+		if !settleable(transaction) {
+			continue
+		}
+		settledAt := settledAt(transaction)
+		if !settledAt.Before(cutoff) {
+			continue
+		}
+
+		gross, fee, net := feeBreakdown(transaction)
+		periodEnd := cutoff.Add(-cutoff.Sub(settledAt) / s.period * s.period)
+		if !settledAt.Before(periodEnd) {
+			periodEnd = periodEnd.Add(s.period)
+		}
+		key := transaction.MerchantID + "|" + gross.Currency() + "|" + periodEnd.Format(time.RFC3339Nano)
+		batch, ok := groups[key]
+		if !ok {
+			batch = newSettlementBatch(transaction.MerchantID, gross.Currency(), periodEnd.Add(-s.period), periodEnd)
+			groups[key] = batch
+			keys = append(keys, key)
+		}
+
+		err = addToBatch(batch, transaction, gross, fee, net)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghitung settlement transaksi %s: %w", transaction.ID, err)
+		}
+	}
+
+	// Batch dibuat berurutan dari periode terlama
+	sort.Slice(keys, func(i, j int) bool {
+		return groups[keys[i]].PeriodEnd.Before(groups[keys[j]].PeriodEnd) ||
+			groups[keys[i]].PeriodEnd.Equal(groups[keys[j]].PeriodEnd) && groups[keys[i]].MerchantID < groups[keys[j]].MerchantID
+	})
+
+	batches := make([]models.SettlementBatch, 0, len(keys))
+	for _, key := range keys {
+		batch := groups[key]
+		err = s.createBatch(batch)
+		if err != nil {
+			return batches, err
+		}
+		batches = append(batches, *batch)
+	}
+
+	log.Println("Batch settlement dibuat:", len(batches))
+
+	return batches, nil
+}
+
+// MarkPaid menandai batch settlement sudah dibayarkan ke merchant
+func (s *SettlementService) MarkPaid(batchID string) (*models.SettlementBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch, err := s.settlementRepository.GetByID(batchID)
+	if err != nil {
+		return nil, errors.New("ID settlement tidak valid")
+	}
+	if batch.Status != models.SettlementStatusPending {
+		return nil, fmt.Errorf("settlement dengan status %s tidak dapat dibayarkan", batch.Status)
+	}
+
+	// Pembayaran mengurangi utang settlement dan kas, batch dengan Net negatif berarti dana ditagih dari merchant
+	var entry *ledger.Entry
+	if !batch.Net.IsZero() {
+		entry = ledger.NewEntry(batch.ID, "settlement payout",
+			ledger.Debit(ledger.MerchantSettlement(batch.MerchantID, batch.Currency), batch.Net),
+			ledger.Credit(ledger.SettlementCash(batch.Currency), batch.Net),
+		)
+		err = s.ledger.Post(entry)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memposting jurnal: %w", err)
+		}
+	}
+
+	paidAt := time.Now()
+	batch.Status = models.SettlementStatusPaid
+	batch.PaidAt = &paidAt
+	err = s.settlementRepository.Save(batch)
+	if err != nil {
+		if entry != nil {
+			reverseErr := s.ledger.Reverse(entry, "settlement gagal disimpan")
+			if reverseErr != nil {
+				log.Println("Gagal membatalkan jurnal", entry.ID, ":", reverseErr)
+			}
+		}
+		return nil, fmt.Errorf("gagal menyimpan settlement: %w", err)
+	}
+
+	return batch, nil
+}
+
+// ListBatches mengambil batch settlement, merchantID kosong berarti seluruh merchant
+func (s *SettlementService) ListBatches(merchantID string) ([]models.SettlementBatch, error) {
+	batches, err := s.settlementRepository.List(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan settlement: %w", err)
+	}
+	return batches, nil
+}
+
+// GetReport mengambil batch settlement beserta rincian transaksinya
+func (s *SettlementService) GetReport(batchID string) (*SettlementReport, error) {
+	batch, err := s.settlementRepository.GetByID(batchID)
+	if err != nil {
+		return nil, errors.New("ID settlement tidak valid")
+	}
+
+	transactions, err := s.transactionRepository.GetTransactionsBySettlementID(batch.ID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+
+	report := &SettlementReport{
+		Batch: *batch,
+		Lines: make([]SettlementLine, 0, len(transactions)),
+	}
+	merchantName, err := s.merchantRepository.GetMerchantNameByID(batch.MerchantID)
+	if err == nil {
+		report.MerchantName = merchantName
+	}
+
+	for i := range transactions {
+		transaction := &transactions[i]
+		gross, fee, net := feeBreakdown(transaction)
+		if transaction.Type == models.TransactionTypeRefund {
+			gross, fee, net = gross.Neg(), fee.Neg(), net.Neg()
+		}
+		report.Lines = append(report.Lines, SettlementLine{
+			TransactionID: transaction.ID,
+			Type:          transaction.Type,
+			CustomerID:    transaction.CustomerID,
+			OriginalID:    transaction.OriginalID,
+			SettledAt:     settledAt(transaction),
+			Gross:         gross,
+			Fee:           fee,
+			Net:           net,
+		})
+	}
+	sort.SliceStable(report.Lines, func(i, j int) bool {
+		return report.Lines[i].SettledAt.Before(report.Lines[j].SettledAt)
+	})
+
+	return report, nil
+}
+
+// ExportCSV menghasilkan laporan settlement dalam format CSV dengan satu baris per transaksi dan baris total
+func (s *SettlementService) ExportCSV(batchID string) ([]byte, error) {
+	report, err := s.GetReport(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	rows := [][]string{{"batch_id", "merchant_id", "currency", "transaction_id", "type", "customer_id", "original_id", "settled_at", "gross", "fee", "net"}}
+	for _, line := range report.Lines {
+		rows = append(rows, []string{
+			report.Batch.ID, report.Batch.MerchantID, report.Batch.Currency,
+			line.TransactionID, line.Type, line.CustomerID, line.OriginalID,
+			line.SettledAt.Format(time.RFC3339),
+			line.Gross.Decimal(), line.Fee.Decimal(), line.Net.Decimal(),
+		})
+	}
+
+	// Baris total: gross dan fee dikurangi refund serta biaya yang dikembalikan
+	gross, err := report.Batch.Gross.Sub(report.Batch.Refunds)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := report.Batch.Fees.Sub(report.Batch.RefundedFees)
+	if err != nil {
+		return nil, err
+	}
+	rows = append(rows, []string{
+		report.Batch.ID, report.Batch.MerchantID, report.Batch.Currency,
+		"TOTAL", "", "", "", report.Batch.PeriodEnd.Format(time.RFC3339),
+		gross.Decimal(), fee.Decimal(), report.Batch.Net.Decimal(),
+	})
+
+	err = writer.WriteAll(rows)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menulis CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportJSON menghasilkan laporan settlement dalam format JSON
+func (s *SettlementService) ExportJSON(batchID string) ([]byte, error) {
+	report, err := s.GetReport(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengodekan JSON: %w", err)
+	}
+	return data, nil
+}
+
+// StartScheduler menjalankan pembuatan batch settlement setiap kali cut-off terlewati di background.
+// Fungsi yang dikembalikan digunakan untuk menghentikan scheduler.
+func (s *SettlementService) StartScheduler() (stop func()) {
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			now := time.Now()
+			s.mu.Lock()
+			next := s.lastCutoff(now).Add(s.period)
+			s.mu.Unlock()
+
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-timer.C:
+				batches, err := s.CreateBatches(next)
+				if err != nil {
+					log.Println("Gagal membuat batch settlement:", err)
+				} else if len(batches) > 0 {
+					log.Println("Batch settlement terjadwal dibuat:", len(batches))
+				}
+			case <-done:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Fungsi bantu untuk menyimpan batch baru, memposting jurnalnya, lalu menandai transaksinya.
+// Jika salah satu langkah gagal maka langkah sebelumnya dibatalkan. Harus dipanggil ketika mu sudah dikunci.
+func (s *SettlementService) createBatch(batch *models.SettlementBatch) error {
+	// Utang merchant dipindahkan ke utang settlement, batch dengan Net negatif memindahkan arah sebaliknya
+	var entry *ledger.Entry
+	if !batch.Net.IsZero() {
+		entry = ledger.NewEntry(batch.ID, "settlement batch",
+			ledger.Debit(ledger.MerchantPayable(batch.MerchantID, batch.Currency), batch.Net),
+			ledger.Credit(ledger.MerchantSettlement(batch.MerchantID, batch.Currency), batch.Net),
+		)
+		err := s.ledger.Post(entry)
+		if err != nil {
+			return fmt.Errorf("gagal memposting jurnal: %w", err)
+		}
+	}
+	reverse := func(reason string) {
+		if entry == nil {
+			return
+		}
+		reverseErr := s.ledger.Reverse(entry, reason)
+		if reverseErr != nil {
+			log.Println("Gagal membatalkan jurnal", entry.ID, ":", reverseErr)
+		}
+	}
+
+	err := s.settlementRepository.Save(batch)
+	if err != nil {
+		reverse("settlement gagal disimpan")
+		return fmt.Errorf("gagal menyimpan settlement: %w", err)
+	}
+
+	err = s.transactionRepository.MarkSettled(batch.ID, batch.TransactionIDs)
+	if err != nil {
+		deleteErr := s.settlementRepository.Delete(batch.ID)
+		if deleteErr != nil {
+			log.Println("Gagal menghapus settlement", batch.ID, ":", deleteErr)
+		}
+		reverse("transaksi settlement gagal ditandai")
+		return fmt.Errorf("gagal menandai transaksi settlement: %w", err)
+	}
+
+	return nil
+}
+
+// Fungsi bantu untuk menghitung waktu cut-off terakhir. Harus dipanggil ketika mu sudah dikunci.
+func (s *SettlementService) lastCutoff(now time.Time) time.Time {
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), s.cutoffHour, 0, 0, 0, now.Location())
+	for cutoff.After(now) {
+		cutoff = cutoff.Add(-s.period)
+	}
+	for !cutoff.Add(s.period).After(now) {
+		cutoff = cutoff.Add(s.period)
+	}
+	return cutoff
+}
+
+// Fungsi bantu untuk membuat batch settlement kosong
+func newSettlementBatch(merchantID string, currency string, periodStart time.Time, periodEnd time.Time) *models.SettlementBatch {
+	return &models.SettlementBatch{
+		ID:             "STL" + generateTransactionID(),
+		MerchantID:     merchantID,
+		Currency:       currency,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		Gross:          money.Zero(currency),
+		Fees:           money.Zero(currency),
+		Refunds:        money.Zero(currency),
+		RefundedFees:   money.Zero(currency),
+		Net:            money.Zero(currency),
+		TransactionIDs: make([]string, 0),
+		Status:         models.SettlementStatusPending,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// Fungsi bantu untuk menambahkan transaksi ke total batch settlement
+func addToBatch(batch *models.SettlementBatch, transaction *models.Transaction, gross, fee, net money.Money) error {
+	var err error
+	if transaction.Type == models.TransactionTypeRefund {
+		batch.RefundCount++
+		batch.Refunds, err = batch.Refunds.Add(gross)
+		if err == nil {
+			batch.RefundedFees, err = batch.RefundedFees.Add(fee)
+		}
+		if err == nil {
+			batch.Net, err = batch.Net.Sub(net)
+		}
+	} else {
+		batch.PaymentCount++
+		batch.Gross, err = batch.Gross.Add(gross)
+		if err == nil {
+			batch.Fees, err = batch.Fees.Add(fee)
+		}
+		if err == nil {
+			batch.Net, err = batch.Net.Add(net)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	batch.TransactionIDs = append(batch.TransactionIDs, transaction.ID)
+	return nil
+}
+
+// Fungsi bantu untuk memeriksa apakah transaksi merchant siap di-settle:
+// pembayaran yang sudah di-capture (termasuk yang kemudian direfund) dan refund yang berhasil
+func settleable(transaction *models.Transaction) bool {
+	if transaction.SettlementID != "" || transaction.MerchantID == "" {
+		return false
+	}
+
+	status := TransactionStatus(transaction)
+	switch transaction.Type {
+	case "", models.TransactionTypePayment:
+		return status == models.TransactionStatusCaptured || status == models.TransactionStatusRefunded
+	case models.TransactionTypeRefund:
+		return status == models.TransactionStatusCaptured
+	}
+	return false
+}
+
+// Fungsi bantu untuk mengambil waktu transaksi di-capture, transaksi tanpa riwayat status menggunakan waktu dibuat
+func settledAt(transaction *models.Transaction) time.Time {
+	for i := len(transaction.StatusHistory) - 1; i >= 0; i-- {
+		if transaction.StatusHistory[i].To == models.TransactionStatusCaptured {
+			return transaction.StatusHistory[i].At
+		}
+	}
+	return transaction.CreatedAt
+}
+
+// Fungsi bantu untuk mengambil jumlah kotor, biaya, dan jumlah bersih transaksi dalam mata uang merchant.
+// Transaksi lama yang belum memiliki rincian biaya dianggap tanpa biaya.
+func feeBreakdown(transaction *models.Transaction) (gross, fee, net money.Money) {
+	gross, fee, net = transaction.Gross, transaction.Fee, transaction.Net
+	if gross.IsZero() && net.IsZero() && fee.IsZero() {
+		gross = merchantAmount(transaction)
+		net = gross
+		fee = money.Zero(gross.Currency())
+	}
+	return gross, fee, net
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/pdf"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// StatementPeriodLayout adalah format periode laporan rekening bulanan, contoh "2026-10"
+const StatementPeriodLayout = "2006-01"
+
+// Statement adalah laporan rekening bulanan pelanggan. Lines hanya berisi transaksi yang mengubah saldo wallet
+// pada periode tersebut, diurutkan dari yang terlama. Seluruh jumlah dalam mata uang wallet pelanggan.
+type Statement struct {
+	CustomerID     string           `json:"customer_id"`
+	CustomerName   string           `json:"customer_name"`
+	Period         string           `json:"period"`
+	PeriodStart    time.Time        `json:"period_start"`
+	PeriodEnd      time.Time        `json:"period_end"`
+	Currency       string           `json:"currency"`
+	OpeningBalance money.Money      `json:"opening_balance"`
+	TotalCredit    money.Money      `json:"total_credit"`
+	TotalDebit     money.Money      `json:"total_debit"`
+	ClosingBalance money.Money      `json:"closing_balance"`
+	Lines          []StatementLine  `json:"lines"`
+	Merchants      []MerchantTotals `json:"merchants"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+}
+
+// StatementLine adalah satu transaksi pada laporan rekening. Tepat salah satu dari Debit dan Credit bernilai
+// bukan nol, Balance adalah saldo setelah transaksi tersebut. PostedAt adalah waktu saldo berubah, yaitu waktu
+// capture untuk pembayaran yang diotorisasi terlebih dahulu.
+type StatementLine struct {
+	TransactionID string      `json:"transaction_id"`
+	Type          string      `json:"type"`
+	Description   string      `json:"description"`
+	MerchantID    string      `json:"merchant_id,omitempty"`
+	MerchantName  string      `json:"merchant_name,omitempty"`
+	Debit         money.Money `json:"debit"`
+	Credit        money.Money `json:"credit"`
+	Balance       money.Money `json:"balance"`
+	PostedAt      time.Time   `json:"posted_at"`
+}
+
+// MerchantTotals adalah jumlah pembayaran dan refund pelanggan ke satu merchant pada periode laporan.
+// Net adalah Payments dikurangi Refunds.
+type MerchantTotals struct {
+	MerchantID   string      `json:"merchant_id"`
+	MerchantName string      `json:"merchant_name"`
+	Count        int         `json:"count"`
+	Payments     money.Money `json:"payments"`
+	Refunds      money.Money `json:"refunds"`
+	Net          money.Money `json:"net"`
+}
+
+// StatementService menyusun laporan rekening bulanan dari riwayat transaksi dan mengekspornya ke CSV dan PDF
+type StatementService struct {
+	transactionRepository *repository.TransactionRepository
+	customerRepository    repository.CustomerRepository
+	merchantRepository    repository.MerchantRepository
+	walletRepository      repository.WalletRepository
+}
+
+// NewStatementService membuat instance baru dari StatementService
+func NewStatementService(transactionRepository *repository.TransactionRepository, customerRepository repository.CustomerRepository, merchantRepository repository.MerchantRepository, walletRepository repository.WalletRepository) *StatementService {
+	return &StatementService{
+		transactionRepository: transactionRepository,
+		customerRepository:    customerRepository,
+		merchantRepository:    merchantRepository,
+		walletRepository:      walletRepository,
+	}
+}
+
+// ParseStatementPeriod membaca periode laporan dengan format StatementPeriodLayout dan mengembalikan awal bulannya
+// pada zona waktu lokal
+func ParseStatementPeriod(value string) (time.Time, error) {
+	start, err := time.ParseInLocation(StatementPeriodLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("periode laporan tidak valid, gunakan format YYYY-MM: %s", value)
+	}
+	return start, nil
+}
+
+// StatementFileName mengembalikan nama file ekspor laporan rekening dengan ekstensi extension, contoh
+// "statement-1-2026-10.csv"
+func StatementFileName(statement *Statement, extension string) string {
+	return "statement-" + statement.CustomerID + "-" + statement.Period + "." + extension
+}
+
+// Build menyusun laporan rekening pelanggan untuk bulan yang dimulai pada start
+func (s *StatementService) Build(customerID string, start time.Time) (*Statement, error) {
+	customer, err := s.customerRepository.GetByID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("pelanggan tidak ditemukan: %w", err)
+	}
+	transactions, err := s.transactionRepository.GetTransactionsByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan riwayat transaksi: %w", err)
+	}
+	return s.build(customer, transactions, start)
+}
+
+// BuildAll menyusun laporan rekening seluruh pelanggan untuk bulan yang dimulai pada start,
+// termasuk pelanggan yang tidak memiliki transaksi pada periode tersebut
+func (s *StatementService) BuildAll(start time.Time) ([]*Statement, error) {
+	customers, err := s.customerRepository.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan data pelanggan: %w", err)
+	}
+	transactions, err := s.transactionRepository.GetAllTransactions()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan riwayat transaksi: %w", err)
+	}
+
+	byCustomer := make(map[string][]models.Transaction)
+	for _, transaction := range transactions {
+		byCustomer[transaction.CustomerID] = append(byCustomer[transaction.CustomerID], transaction)
+	}
+
+	statements := make([]*Statement, 0, len(customers))
+	for i := range customers {
+		statement, err := s.build(&customers[i], byCustomer[customers[i].ID], start)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// Fungsi bantu untuk menyusun laporan rekening dari transaksi pelanggan. Transaksi sebelum periode menentukan
+// saldo awal, transaksi di dalam periode menjadi baris laporan dan total per merchant.
+func (s *StatementService) build(customer *models.Customer, transactions []models.Transaction, start time.Time) (*Statement, error) {
+	wallet, err := s.walletRepository.GetByCustomerID(customer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan wallet pelanggan: %w", err)
+	}
+	currency := wallet.Currency
+	end := start.AddDate(0, 1, 0)
+
+	statement := &Statement{
+		CustomerID:     customer.ID,
+		CustomerName:   customer.Name,
+		Period:         start.Format(StatementPeriodLayout),
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Currency:       currency,
+		OpeningBalance: money.Zero(currency),
+		TotalCredit:    money.Zero(currency),
+		TotalDebit:     money.Zero(currency),
+		Lines:          []StatementLine{},
+		Merchants:      []MerchantTotals{},
+		GeneratedAt:    time.Now(),
+	}
+
+	// Transaksi diurutkan berdasarkan waktu saldo berubah sehingga otorisasi yang di-capture pada periode
+	// berikutnya masuk ke laporan periode capture
+	sorted := make([]models.Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return postedAt(&sorted[i]).Before(postedAt(&sorted[j]))
+	})
+
+	merchants := make(map[string]*MerchantTotals)
+	balance := statement.OpeningBalance
+	for i := range sorted {
+		transaction := &sorted[i]
+		posted := postedAt(transaction)
+		if !posted.Before(end) {
+			break
+		}
+		change, ok := balanceChange(transaction)
+		if !ok {
+			continue
+		}
+
+		balance, err = balance.Add(change)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghitung saldo transaksi %s: %w", transaction.ID, err)
+		}
+		if posted.Before(start) {
+			statement.OpeningBalance = balance
+			continue
+		}
+
+		line := StatementLine{
+			TransactionID: transaction.ID,
+			Type:          transaction.Type,
+			Description:   transaction.Description,
+			MerchantID:    transaction.MerchantID,
+			Debit:         money.Zero(currency),
+			Credit:        money.Zero(currency),
+			Balance:       balance,
+			PostedAt:      posted,
+		}
+		if line.Type == "" {
+			line.Type = models.TransactionTypePayment
+		}
+		if line.MerchantID != "" {
+			line.MerchantName, _ = s.merchantRepository.GetMerchantNameByID(line.MerchantID)
+		}
+		if line.Description == "" && line.Type == models.TransactionTypePayment {
+			line.Description = "pembayaran ke " + line.MerchantName
+		}
+		if change.IsNegative() {
+			line.Debit = change.Neg()
+			statement.TotalDebit, err = statement.TotalDebit.Add(line.Debit)
+		} else {
+			line.Credit = change
+			statement.TotalCredit, err = statement.TotalCredit.Add(line.Credit)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghitung total transaksi %s: %w", transaction.ID, err)
+		}
+		statement.Lines = append(statement.Lines, line)
+
+		err = addMerchantTotals(merchants, &line, currency)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghitung total merchant transaksi %s: %w", transaction.ID, err)
+		}
+	}
+	statement.ClosingBalance = balance
+
+	for _, totals := range merchants {
+		statement.Merchants = append(statement.Merchants, *totals)
+	}
+	sort.Slice(statement.Merchants, func(i, j int) bool {
+		return statement.Merchants[i].MerchantID < statement.Merchants[j].MerchantID
+	})
+	return statement, nil
+}
+
+// Fungsi bantu untuk menambahkan pembayaran atau refund ke total merchant pada laporan
+func addMerchantTotals(merchants map[string]*MerchantTotals, line *StatementLine, currency string) error {
+	if line.MerchantID == "" || (line.Type != models.TransactionTypePayment && line.Type != models.TransactionTypeRefund) {
+		return nil
+	}
+
+	totals, ok := merchants[line.MerchantID]
+	if !ok {
+		totals = &MerchantTotals{
+			MerchantID:   line.MerchantID,
+			MerchantName: line.MerchantName,
+			Payments:     money.Zero(currency),
+			Refunds:      money.Zero(currency),
+			Net:          money.Zero(currency),
+		}
+		merchants[line.MerchantID] = totals
+	}
+
+	var err error
+	if line.Type == models.TransactionTypePayment {
+		totals.Count++
+		totals.Payments, err = totals.Payments.Add(line.Debit)
+	} else {
+		totals.Refunds, err = totals.Refunds.Add(line.Credit)
+	}
+	if err != nil {
+		return err
+	}
+	totals.Net, err = totals.Payments.Sub(totals.Refunds)
+	return err
+}
+
+// Fungsi bantu untuk menghitung perubahan saldo wallet karena transaksi. Mengembalikan false untuk transaksi
+// yang tidak mengubah saldo, seperti otorisasi yang belum di-capture, pembayaran gagal, dan otorisasi yang dibatalkan.
+func balanceChange(transaction *models.Transaction) (money.Money, bool) {
+	status := TransactionStatus(transaction)
+	switch transaction.Type {
+	case models.TransactionTypeTopUp, models.TransactionTypeTransferIn, models.TransactionTypeRefund,
+		models.TransactionTypeCashback, models.TransactionTypePointsRedemption:
+		return transaction.Amount, status == models.TransactionStatusCaptured
+	case models.TransactionTypeTransferOut, models.TransactionTypeCashbackReversal, models.TransactionTypePointsRedemptionReversal:
+		return transaction.Amount.Neg(), status == models.TransactionStatusCaptured
+	case "", models.TransactionTypePayment:
+		// Pembayaran yang sudah direfund tetap mengurangi saldo, pengembaliannya dicatat sebagai transaksi refund
+		return transaction.Amount.Neg(), status == models.TransactionStatusCaptured || status == models.TransactionStatusRefunded
+	}
+	return money.Money{}, false
+}
+
+// Fungsi bantu untuk mengambil waktu transaksi mengubah saldo wallet, yaitu waktu transaksi pertama kali berstatus
+// captured. Transaksi tanpa riwayat status menggunakan waktu transaksi dibuat.
+func postedAt(transaction *models.Transaction) time.Time {
+	for _, change := range transaction.StatusHistory {
+		if change.To == models.TransactionStatusCaptured {
+			return change.At
+		}
+	}
+	return transaction.CreatedAt
+}
+
+// RenderCSV membuat laporan rekening dalam format CSV yang terdiri dari ringkasan, daftar transaksi,
+// dan total per merchant yang dipisahkan baris kosong. Jumlah ditulis sebagai angka desimal tanpa kode mata uang.
+func (s *StatementService) RenderCSV(statement *Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	records := [][]string{
+		{"Laporan rekening", statement.Period},
+		{"ID pelanggan", statement.CustomerID},
+		{"Nama pelanggan", statement.CustomerName},
+		{"Mata uang", statement.Currency},
+		{"Saldo awal", statement.OpeningBalance.Decimal()},
+		{"Total masuk", statement.TotalCredit.Decimal()},
+		{"Total keluar", statement.TotalDebit.Decimal()},
+		{"Saldo akhir", statement.ClosingBalance.Decimal()},
+		{},
+		{"Waktu", "ID transaksi", "Jenis", "Keterangan", "Merchant", "Debit", "Kredit", "Saldo"},
+	}
+	for _, line := range statement.Lines {
+		records = append(records, []string{
+			line.PostedAt.Format(time.RFC3339),
+			line.TransactionID,
+			line.Type,
+			line.Description,
+			line.MerchantName,
+			line.Debit.Decimal(),
+			line.Credit.Decimal(),
+			line.Balance.Decimal(),
+		})
+	}
+	records = append(records, []string{}, []string{"ID merchant", "Merchant", "Jumlah pembayaran", "Pembayaran", "Refund", "Bersih"})
+	for _, totals := range statement.Merchants {
+		records = append(records, []string{
+			totals.MerchantID,
+			totals.MerchantName,
+			strconv.Itoa(totals.Count),
+			totals.Payments.Decimal(),
+			totals.Refunds.Decimal(),
+			totals.Net.Decimal(),
+		})
+	}
+
+	err := writer.WriteAll(records)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat laporan CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Posisi kolom tabel transaksi dan tabel merchant pada laporan PDF
+var (
+	statementLineColumns     = []float64{pdf.Margin, 120, 230, 330, 400, 470}
+	statementMerchantColumns = []float64{pdf.Margin, 260, 330, 400, 470}
+)
+
+// RenderPDF membuat laporan rekening dalam format PDF
+func (s *StatementService) RenderPDF(statement *Statement) []byte {
+	document := pdf.New("Laporan rekening " + statement.CustomerID + " " + statement.Period)
+	document.SetFont(pdf.FontBold, 16)
+	document.Writeln("Laporan Rekening " + statement.Period)
+	document.SetFont(pdf.FontRegular, 10)
+	document.Writeln(fmt.Sprintf("%s (ID %s)", statement.CustomerName, statement.CustomerID))
+	document.Writeln(fmt.Sprintf("Periode %s s.d. %s, mata uang %s", statement.PeriodStart.Format("02-01-2006"),
+		statement.PeriodEnd.AddDate(0, 0, -1).Format("02-01-2006"), statement.Currency))
+	document.Rule()
+
+	summary := [][2]string{
+		{"Saldo awal", statement.OpeningBalance.Decimal()},
+		{"Total masuk", statement.TotalCredit.Decimal()},
+		{"Total keluar", statement.TotalDebit.Decimal()},
+		{"Saldo akhir", statement.ClosingBalance.Decimal()},
+	}
+	for _, row := range summary {
+		document.Row([]float64{pdf.Margin, 160}, row[0], row[1])
+	}
+	document.Rule()
+
+	document.SetFont(pdf.FontBold, 9)
+	document.Row(statementLineColumns, "Waktu", "Jenis", "Merchant", "Debit", "Kredit", "Saldo")
+	document.SetFont(pdf.FontRegular, 9)
+	if len(statement.Lines) == 0 {
+		document.Writeln("Tidak ada transaksi pada periode ini")
+	}
+	for _, line := range statement.Lines {
+		document.Row(statementLineColumns,
+			line.PostedAt.Format("02-01 15:04"),
+			line.Type,
+			truncate(line.MerchantName, 18),
+			nonZeroDecimal(line.Debit),
+			nonZeroDecimal(line.Credit),
+			line.Balance.Decimal(),
+		)
+	}
+
+	if len(statement.Merchants) > 0 {
+		document.Rule()
+		document.SetFont(pdf.FontBold, 9)
+		document.Row(statementMerchantColumns, "Merchant", "Jumlah", "Pembayaran", "Refund", "Bersih")
+		document.SetFont(pdf.FontRegular, 9)
+		for _, totals := range statement.Merchants {
+			document.Row(statementMerchantColumns,
+				truncate(totals.MerchantName, 40),
+				strconv.Itoa(totals.Count),
+				totals.Payments.Decimal(),
+				totals.Refunds.Decimal(),
+				totals.Net.Decimal(),
+			)
+		}
+	}
+	return document.Bytes()
+}
+
+// Fungsi bantu untuk menulis jumlah pada kolom debit atau kredit, nol ditulis sebagai kolom kosong
+func nonZeroDecimal(amount money.Money) string {
+	if amount.IsZero() {
+		return ""
+	}
+	return amount.Decimal()
+}
+
+// Fungsi bantu untuk memotong teks yang lebih panjang dari limit karakter agar tidak menimpa kolom berikutnya
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-3]) + "..."
+}
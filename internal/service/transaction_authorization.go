@@ -0,0 +1,315 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// DefaultHoldExpiry adalah lama default saldo ditahan oleh otorisasi pembayaran
+const DefaultHoldExpiry = 30 * time.Minute
+
+// SetHoldExpiry mengatur lama saldo ditahan sebelum otorisasi dilepas otomatis
+func (s *TransactionService) SetHoldExpiry(expiry time.Duration) {
+	s.holdExpiry = expiry
+}
+
+// AuthorizeTransaction menahan saldo pelanggan untuk pembayaran ke merchant yang akan di-capture kemudian.
+// deviceID adalah perangkat yang digunakan untuk membayar, kosong berarti tidak diketahui.
+func (s *TransactionService) AuthorizeTransaction(customerID string, merchantID string, amount money.Money, deviceID string) (*models.Transaction, error) {
+	log.Println("Mengotorisasi transaksi...")
+
+	merchant, err := s.validatePayment(customerID, merchantID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Saldo ditahan dalam mata uang wallet pelanggan dengan kurs yang dikunci saat otorisasi
+	transaction := newPayment(customerID, merchantID, amount)
+	transaction.DeviceID = deviceID
+	err = s.convertPayment(transaction)
+	if err != nil {
+		return nil, err
+	}
+	err = applyMerchantFee(transaction, merchant)
+	if err != nil {
+		return nil, err
+	}
+	err = s.holdPayment(transaction, transaction.CreatedAt.Add(s.holdExpiry))
+	if err != nil {
+		if TransactionStatus(transaction) == models.TransactionStatusFailed {
+			return transaction, err
+		}
+		return nil, err
+	}
+
+	log.Println("Transaksi berhasil diotorisasi.")
+
+	return transaction, nil
+}
+
+// Fungsi bantu untuk menahan saldo pelanggan sebesar jumlah transaksi sampai expiresAt
+// dan menyimpan transaksi terotorisasi secara bersamaan. Otorisasi yang ditolak karena saldo
+// tidak mencukupi atau melampaui batas dicatat sebagai transaksi gagal, otorisasi yang ditolak
+// pemeriksaan risiko hanya dicatat pada catatan risiko seperti pembayaran langsung.
+func (s *TransactionService) holdPayment(transaction *models.Transaction, expiresAt time.Time) error {
+	hold := transaction.Amount
+	transaction.HoldAmount = &hold
+	transaction.HoldExpiresAt = &expiresAt
+
+	log.Println("Menahan saldo pelanggan...")
+	_, err := s.walletRepository.Hold(transaction.CustomerID, hold, func() error {
+		err := s.limits.CheckSpend(transaction.CustomerID, hold, transaction.CreatedAt)
+		if err != nil {
+			return err
+		}
+		// Otorisasi diperiksa pemeriksaan risiko sebelum saldo ditahan agar capture tidak dapat melewatinya
+		err = s.assessRisk(transaction)
+		if err != nil {
+			return err
+		}
+		err = transitionTransaction(transaction, models.TransactionStatusAuthorized, "saldo ditahan")
+		if err != nil {
+			return err
+		}
+		return postAndSave(s.ledger, s.transactionRepository, authorizationEntry(transaction), transaction)
+	})
+	if err != nil {
+		if isRejection(err) {
+			s.failTransaction(transaction, err)
+			return fmt.Errorf("otorisasi ditolak: %w", err)
+		}
+		var riskErr *RiskError
+		if errors.As(err, &riskErr) {
+			return riskErr
+		}
+		return fmt.Errorf("gagal mengotorisasi transaksi: %w", err)
+	}
+
+	s.recordReview(transaction)
+	s.publish(models.EventPaymentAuthorized, transaction)
+	return nil
+}
+
+// CaptureTransaction mendebit saldo yang ditahan oleh otorisasi atas permintaan merchant penerima pembayaran.
+// Jumlah capture boleh lebih kecil dari jumlah yang ditahan, amount nol berarti capture seluruhnya.
+// Untuk otorisasi valas, amount boleh dalam mata uang merchant dan dikonversi dengan kurs yang dikunci.
+func (s *TransactionService) CaptureTransaction(merchantID string, transactionID string, amount money.Money) (*models.Transaction, error) {
+	log.Println("Meng-capture transaksi...")
+
+	s.holdMu.Lock()
+	defer s.holdMu.Unlock()
+
+	transaction, err := s.getAuthorizedTransaction(merchantID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Otorisasi yang sudah kedaluwarsa dilepas dan tidak dapat di-capture
+	if transaction.HoldExpiresAt != nil && !time.Now().Before(*transaction.HoldExpiresAt) {
+		err = s.voidTransaction(transaction, "otorisasi kedaluwarsa")
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("otorisasi sudah kedaluwarsa")
+	}
+
+	// Validasi jumlah capture
+	holdAmount := holdAmount(transaction)
+	captured, capturedOriginal := amount, amount
+	if amount.IsZero() {
+		captured, capturedOriginal = holdAmount, merchantAmount(transaction)
+	} else if transaction.OriginalAmount != nil && amount.Currency() == transaction.OriginalAmount.Currency() && amount.Currency() != holdAmount.Currency() {
+		rate, err := lockedRate(transaction)
+		if err != nil {
+			return nil, err
+		}
+		captured, err = rate.Convert(amount)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mengonversi jumlah capture: %w", err)
+		}
+		capturedOriginal = amount
+	} else {
+		var err error
+		capturedOriginal, err = merchantShare(transaction, amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	amount = captured
+	cmp, err := amount.Cmp(holdAmount)
+	if err != nil {
+		return nil, fmt.Errorf("mata uang capture tidak valid: %w", err)
+	}
+	if !amount.IsPositive() || cmp > 0 {
+		return nil, fmt.Errorf("jumlah capture harus di antara 0 dan %s", holdAmount)
+	}
+
+	// Biaya merchant dihitung ulang dari jumlah yang di-capture
+	merchant, err := s.merchantRepository.GetByID(transaction.MerchantID)
+	if err != nil {
+		return nil, errors.New("ID merchant tidak valid")
+	}
+
+	// Mendebit saldo yang ditahan dan menyimpan transaksi secara bersamaan
+	_, err = s.walletRepository.CaptureHold(transaction.CustomerID, holdAmount, amount, func() error {
+		transaction.Amount = amount
+		if transaction.OriginalAmount != nil {
+			transaction.OriginalAmount = &capturedOriginal
+		}
+		err := applyMerchantFee(transaction, merchant)
+		if err != nil {
+			return err
+		}
+		entry, err := captureEntry(transaction, holdAmount)
+		if err != nil {
+			return err
+		}
+		err = transitionTransaction(transaction, models.TransactionStatusCaptured, fmt.Sprintf("capture %s dari %s", amount, holdAmount))
+		if err != nil {
+			return err
+		}
+		transaction.PointsEarned = s.pointsToEarn(transaction)
+		return postAndSave(s.ledger, s.transactionRepository, entry, transaction)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal meng-capture transaksi: %w", err)
+	}
+
+	log.Println("Transaksi berhasil di-capture.")
+	s.paymentSucceeded(transaction)
+
+	return transaction, nil
+}
+
+// VoidTransaction membatalkan otorisasi dan melepaskan saldo yang ditahan atas permintaan merchant penerima pembayaran
+func (s *TransactionService) VoidTransaction(merchantID string, transactionID string, reason string) (*models.Transaction, error) {
+	log.Println("Membatalkan otorisasi transaksi...")
+
+	s.holdMu.Lock()
+	defer s.holdMu.Unlock()
+
+	transaction, err := s.getAuthorizedTransaction(merchantID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason == "" {
+		reason = "dibatalkan"
+	}
+	err = s.voidTransaction(transaction, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Otorisasi transaksi berhasil dibatalkan.")
+
+	return transaction, nil
+}
+
+// ReleaseExpiredHolds membatalkan semua otorisasi yang sudah melewati batas waktu penahanan
+func (s *TransactionService) ReleaseExpiredHolds(now time.Time) (int, error) {
+	s.holdMu.Lock()
+	defer s.holdMu.Unlock()
+
+	transactions, err := s.transactionRepository.GetAllTransactions()
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+
+	released := 0
+	for i := range transactions {
+		transaction := &transactions[i]
+		// Otorisasi bagian split bill dilepas oleh bill ketika kedaluwarsa
+		if TransactionStatus(transaction) != models.TransactionStatusAuthorized || transaction.HoldExpiresAt == nil || transaction.BillID != "" {
+			continue
+		}
+		if now.Before(*transaction.HoldExpiresAt) {
+			continue
+		}
+
+		err = s.voidTransaction(transaction, "otorisasi kedaluwarsa")
+		if err != nil {
+			log.Println("Gagal melepas otorisasi", transaction.ID, ":", err)
+			continue
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+// StartHoldSweeper menjalankan pelepasan otorisasi kedaluwarsa secara berkala di background.
+// Fungsi yang dikembalikan digunakan untuk menghentikan sweeper.
+func (s *TransactionService) StartHoldSweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				released, err := s.ReleaseExpiredHolds(now)
+				if err != nil {
+					log.Println("Gagal melepas otorisasi kedaluwarsa:", err)
+				} else if released > 0 {
+					log.Println("Otorisasi kedaluwarsa dilepas:", released)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Fungsi bantu untuk mengambil transaksi terotorisasi yang ditujukan kepada merchant
+func (s *TransactionService) getAuthorizedTransaction(merchantID string, transactionID string) (*models.Transaction, error) {
+	transaction, err := s.transactionRepository.GetTransactionByID(transactionID)
+	if err != nil {
+		return nil, errors.New("ID transaksi tidak valid")
+	}
+	if transaction.MerchantID != merchantID {
+		return nil, errors.New("transaksi bukan milik merchant")
+	}
+	if TransactionStatus(transaction) != models.TransactionStatusAuthorized {
+		return nil, fmt.Errorf("transaksi dengan status %s tidak dapat diproses", TransactionStatus(transaction))
+	}
+	if transaction.BillID != "" {
+		return nil, fmt.Errorf("transaksi adalah bagian dari split bill %s", transaction.BillID)
+	}
+
+	return transaction, nil
+}
+
+// Fungsi bantu untuk melepaskan saldo yang ditahan dan menandai transaksi sebagai voided.
+// Harus dipanggil ketika holdMu sudah dikunci.
+func (s *TransactionService) voidTransaction(transaction *models.Transaction, reason string) error {
+	_, err := s.walletRepository.ReleaseHold(transaction.CustomerID, holdAmount(transaction), func() error {
+		err := transitionTransaction(transaction, models.TransactionStatusVoided, reason)
+		if err != nil {
+			return err
+		}
+		return postAndSave(s.ledger, s.transactionRepository, voidEntry(transaction), transaction)
+	})
+	if err != nil {
+		return fmt.Errorf("gagal melepas saldo yang ditahan: %w", err)
+	}
+
+	s.publish(models.EventPaymentVoided, transaction)
+	return nil
+}
+
+// Fungsi bantu untuk mengambil jumlah yang ditahan oleh otorisasi
+func holdAmount(transaction *models.Transaction) money.Money {
+	if transaction.HoldAmount == nil {
+		return transaction.Amount
+	}
+	return *transaction.HoldAmount
+}
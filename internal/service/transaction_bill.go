@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// AuthorizeBillShare menahan saldo peserta split bill sebesar bagiannya sampai bill kedaluwarsa.
+// Biaya merchant belum dihitung karena biaya dihitung dari total bill ketika seluruh bagian di-capture.
+// deviceID adalah perangkat yang digunakan untuk membayar, kosong berarti tidak diketahui.
+func (s *TransactionService) AuthorizeBillShare(customerID string, merchantID string, amount money.Money, billID string, expiresAt time.Time, deviceID string) (*models.Transaction, error) {
+	_, err := s.validatePayment(customerID, merchantID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := newPayment(customerID, merchantID, amount)
+	transaction.BillID = billID
+	transaction.DeviceID = deviceID
+	transaction.Description = "split bill " + billID
+	err = s.convertPayment(transaction)
+	if err != nil {
+		return nil, err
+	}
+	err = setFee(transaction, merchantAmount(transaction), money.Zero(amount.Currency()))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.holdPayment(transaction, expiresAt)
+	if err != nil {
+		if TransactionStatus(transaction) == models.TransactionStatusFailed {
+			return transaction, err
+		}
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// CaptureBillShare meng-capture seluruh saldo yang ditahan untuk bagian split bill dengan biaya merchant
+// yang sudah dibagi dari total bill. Batas waktu penahanan tidak diperiksa karena bill sudah lunas.
+func (s *TransactionService) CaptureBillShare(transactionID string, fee money.Money) (*models.Transaction, error) {
+	s.holdMu.Lock()
+	defer s.holdMu.Unlock()
+
+	transaction, err := s.getBillShare(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	// Bagian yang sudah di-capture sebelumnya tidak di-capture ulang
+	if TransactionStatus(transaction) == models.TransactionStatusCaptured {
+		return transaction, nil
+	}
+
+	held := holdAmount(transaction)
+	_, err = s.walletRepository.CaptureHold(transaction.CustomerID, held, held, func() error {
+		err := setFee(transaction, merchantAmount(transaction), fee)
+		if err != nil {
+			return err
+		}
+		entry, err := captureEntry(transaction, held)
+		if err != nil {
+			return err
+		}
+		err = transitionTransaction(transaction, models.TransactionStatusCaptured, "split bill "+transaction.BillID+" lunas")
+		if err != nil {
+			return err
+		}
+		transaction.PointsEarned = s.pointsToEarn(transaction)
+		return postAndSave(s.ledger, s.transactionRepository, entry, transaction)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal meng-capture bagian split bill: %w", err)
+	}
+	s.paymentSucceeded(transaction)
+
+	return transaction, nil
+}
+
+// ReleaseBillShare melepaskan saldo yang ditahan untuk bagian split bill sehingga kembali ke peserta
+func (s *TransactionService) ReleaseBillShare(transactionID string, reason string) (*models.Transaction, error) {
+	s.holdMu.Lock()
+	defer s.holdMu.Unlock()
+
+	transaction, err := s.getBillShare(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	// Bagian yang sudah dilepas sebelumnya tidak dilepas ulang
+	if TransactionStatus(transaction) == models.TransactionStatusVoided {
+		return transaction, nil
+	}
+
+	err = s.voidTransaction(transaction, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// Fungsi bantu untuk mengambil transaksi bagian split bill yang masih terotorisasi, sudah di-capture, atau sudah dilepas
+func (s *TransactionService) getBillShare(transactionID string) (*models.Transaction, error) {
+	transaction, err := s.transactionRepository.GetTransactionByID(transactionID)
+	if err != nil {
+		return nil, errors.New("ID transaksi tidak valid")
+	}
+	if transaction.BillID == "" {
+		return nil, errors.New("transaksi bukan bagian dari split bill")
+	}
+	switch TransactionStatus(transaction) {
+	case models.TransactionStatusAuthorized, models.TransactionStatusCaptured, models.TransactionStatusVoided:
+	default:
+		return nil, fmt.Errorf("transaksi dengan status %s tidak dapat diproses", TransactionStatus(transaction))
+	}
+
+	return transaction, nil
+}
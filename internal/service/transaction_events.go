@@ -0,0 +1,22 @@
+package service
+
+import "github.com/IbnuFarhanS/Golang_MNC/internal/models"
+
+// EventPublisher menerima event transaksi setelah transaksi berhasil disimpan, contohnya untuk dikirim
+// ke webhook merchant. Publish tidak boleh memanggil kembali TransactionService.
+type EventPublisher interface {
+	Publish(eventType string, transaction *models.Transaction)
+}
+
+// SetEventPublisher mengatur penerima event transaksi, nil berarti event tidak dikirim
+func (s *TransactionService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// Fungsi bantu untuk mengirim event transaksi ke penerima event jika sudah diatur
+func (s *TransactionService) publish(eventType string, transaction *models.Transaction) {
+	if s.events == nil || transaction.MerchantID == "" {
+		return
+	}
+	s.events.Publish(eventType, transaction)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/fx"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Fungsi bantu untuk mengonversi pembayaran ke mata uang wallet pelanggan.
+// Kurs dikunci saat pembayaran dibuat dan jumlah dalam mata uang merchant disimpan sebagai OriginalAmount.
+func (s *TransactionService) convertPayment(transaction *models.Transaction) error {
+	wallet, err := s.walletRepository.GetByCustomerID(transaction.CustomerID)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan wallet: %w", err)
+	}
+
+	amount := transaction.Amount
+	transaction.Currency = wallet.Currency
+	if amount.Currency() == wallet.Currency {
+		return nil
+	}
+
+	rate, err := s.rates.Rate(amount.Currency(), wallet.Currency)
+	if err != nil {
+		return fmt.Errorf("kurs %s ke %s tidak tersedia: %w", amount.Currency(), wallet.Currency, err)
+	}
+	converted, err := rate.Convert(amount)
+	if err != nil {
+		return fmt.Errorf("gagal mengonversi pembayaran: %w", err)
+	}
+	if !converted.IsPositive() {
+		return fmt.Errorf("jumlah pembayaran terlalu kecil untuk dikonversi ke %s", wallet.Currency)
+	}
+
+	lockedAt := time.Now()
+	transaction.Amount = converted
+	transaction.OriginalAmount = &amount
+	transaction.ExchangeRate = rate.Value
+	transaction.RateLockedAt = &lockedAt
+	return nil
+}
+
+// Fungsi bantu untuk mengambil kurs yang dikunci pada transaksi valas
+func lockedRate(transaction *models.Transaction) (fx.Rate, error) {
+	if transaction.OriginalAmount == nil || transaction.ExchangeRate == "" {
+		return fx.Identity(transaction.Amount.Currency()), nil
+	}
+
+	rate := fx.Rate{
+		Base:  transaction.OriginalAmount.Currency(),
+		Quote: transaction.Amount.Currency(),
+		Value: transaction.ExchangeRate,
+	}
+	if transaction.RateLockedAt != nil {
+		rate.UpdatedAt = *transaction.RateLockedAt
+	}
+	return rate, nil
+}
+
+// Fungsi bantu untuk mengambil jumlah transaksi dalam mata uang merchant
+func merchantAmount(transaction *models.Transaction) money.Money {
+	if transaction.OriginalAmount == nil {
+		return transaction.Amount
+	}
+	return *transaction.OriginalAmount
+}
+
+// Fungsi bantu untuk menghitung bagian jumlah dalam mata uang merchant yang sebanding dengan
+// sebagian jumlah transaksi dalam mata uang wallet
+func merchantShare(transaction *models.Transaction, part money.Money) (money.Money, error) {
+	if transaction.OriginalAmount == nil || transaction.Amount.IsZero() {
+		return part, nil
+	}
+	return transaction.OriginalAmount.MulRat(part.Amount(), transaction.Amount.Amount())
+}
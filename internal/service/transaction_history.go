@@ -0,0 +1,241 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Batas jumlah item per halaman riwayat transaksi
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+// TransactionQuery berisi filter, urutan, dan posisi halaman riwayat transaksi
+type TransactionQuery struct {
+	MerchantID string
+	Type       string
+	Status     string
+	Currency   string
+	MinAmount  *money.Money
+	MaxAmount  *money.Money
+	From       *time.Time
+	To         *time.Time
+	// Sort berisi "created_at" atau "amount", awalan "-" berarti urutan menurun
+	Sort   string
+	Cursor string
+	Limit  int
+}
+
+// TransactionItem adalah transaksi beserta nama merchant
+type TransactionItem struct {
+	models.Transaction
+	MerchantName string `json:"merchant_name,omitempty"`
+}
+
+// TransactionPage adalah satu halaman riwayat transaksi
+type TransactionPage struct {
+	Items      []TransactionItem `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// historyCursor menyimpan posisi item terakhir pada halaman sebelumnya
+type historyCursor struct {
+	Sort      string      `json:"sort"`
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Amount    money.Money `json:"amount"`
+}
+
+// ListTransactions mengambil riwayat transaksi milik pelanggan dengan filter dan cursor pagination
+func (s *TransactionService) ListTransactions(customerID string, query TransactionQuery) (*TransactionPage, error) {
+	// Validasi urutan dan batas halaman
+	if query.Sort == "" {
+		query.Sort = "-created_at"
+	}
+	less, err := historyComparator(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultHistoryLimit
+	}
+	if query.Limit > MaxHistoryLimit {
+		query.Limit = MaxHistoryLimit
+	}
+
+	transactions, err := s.transactionRepository.GetTransactionsByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+
+	// Menerapkan filter lalu mengurutkan transaksi
+	filtered := make([]models.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if query.matches(transaction) {
+			filtered = append(filtered, transaction)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return less(filtered[i], filtered[j])
+	})
+
+	// Melewati transaksi sampai posisi cursor
+	start := 0
+	if query.Cursor != "" {
+		cursor, err := decodeHistoryCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != query.Sort {
+			return nil, errors.New("cursor tidak sesuai dengan urutan yang diminta")
+		}
+		last := models.Transaction{ID: cursor.ID, CreatedAt: cursor.CreatedAt, Amount: cursor.Amount}
+		start = sort.Search(len(filtered), func(i int) bool {
+			return less(last, filtered[i])
+		})
+	}
+
+	end := start + query.Limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+
+	// Melengkapi setiap item dengan nama merchant
+	page := &TransactionPage{Items: make([]TransactionItem, 0, end-start)}
+	merchantNames := make(map[string]string)
+	for _, transaction := range filtered[start:end] {
+		item := TransactionItem{Transaction: transaction}
+		if transaction.MerchantID != "" {
+			name, ok := merchantNames[transaction.MerchantID]
+			if !ok {
+				name, _ = s.merchantRepository.GetMerchantNameByID(transaction.MerchantID)
+				merchantNames[transaction.MerchantID] = name
+			}
+			item.MerchantName = name
+		}
+		page.Items = append(page.Items, item)
+	}
+
+	// Cursor berikutnya hanya diberikan jika masih ada transaksi setelah halaman ini
+	if end < len(filtered) {
+		last := filtered[end-1]
+		page.NextCursor = encodeHistoryCursor(historyCursor{
+			Sort:      query.Sort,
+			ID:        last.ID,
+			CreatedAt: last.CreatedAt,
+			Amount:    last.Amount,
+		})
+	}
+
+	return page, nil
+}
+
+// Fungsi bantu untuk memeriksa apakah transaksi lolos filter
+func (q TransactionQuery) matches(transaction models.Transaction) bool {
+	if q.MerchantID != "" && transaction.MerchantID != q.MerchantID {
+		return false
+	}
+	if q.Type != "" && transaction.Type != q.Type {
+		return false
+	}
+	// Filter mata uang cocok dengan mata uang wallet maupun mata uang merchant
+	if q.Currency != "" && !strings.EqualFold(transaction.Amount.Currency(), q.Currency) && !strings.EqualFold(merchantAmount(&transaction).Currency(), q.Currency) {
+		return false
+	}
+	if q.Status != "" && TransactionStatus(&transaction) != q.Status {
+		return false
+	}
+	if q.MinAmount != nil {
+		cmp, err := transaction.Amount.Cmp(*q.MinAmount)
+		if err != nil || cmp < 0 {
+			return false
+		}
+	}
+	if q.MaxAmount != nil {
+		cmp, err := transaction.Amount.Cmp(*q.MaxAmount)
+		if err != nil || cmp > 0 {
+			return false
+		}
+	}
+	if q.From != nil && transaction.CreatedAt.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && !transaction.CreatedAt.Before(*q.To) {
+		return false
+	}
+	return true
+}
+
+// Fungsi bantu untuk membuat pembanding urutan transaksi, ID dipakai sebagai penentu jika nilai sama
+func historyComparator(sortBy string) (func(a, b models.Transaction) bool, error) {
+	descending := strings.HasPrefix(sortBy, "-")
+	field := strings.TrimPrefix(sortBy, "-")
+
+	var compare func(a, b models.Transaction) int
+	switch field {
+	case "created_at":
+		compare = func(a, b models.Transaction) int {
+			switch {
+			case a.CreatedAt.Before(b.CreatedAt):
+				return -1
+			case a.CreatedAt.After(b.CreatedAt):
+				return 1
+			}
+			return 0
+		}
+	case "amount":
+		compare = func(a, b models.Transaction) int {
+			switch {
+			case a.Amount.Amount() < b.Amount.Amount():
+				return -1
+			case a.Amount.Amount() > b.Amount.Amount():
+				return 1
+			}
+			return 0
+		}
+	default:
+		return nil, fmt.Errorf("urutan %q tidak didukung", sortBy)
+	}
+
+	return func(a, b models.Transaction) bool {
+		c := compare(a, b)
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	}, nil
+}
+
+// Fungsi bantu untuk mengodekan cursor menjadi string yang aman dipakai di URL
+func encodeHistoryCursor(cursor historyCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Fungsi bantu untuk membaca cursor dari string
+func decodeHistoryCursor(value string) (*historyCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("cursor tidak valid")
+	}
+
+	var cursor historyCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return nil, errors.New("cursor tidak valid")
+	}
+
+	return &cursor, nil
+}
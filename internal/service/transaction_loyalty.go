@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// SetLoyalty mengatur layanan poin loyalitas yang mencatat perolehan dan penukaran poin, nil berarti tanpa poin
+func (s *TransactionService) SetLoyalty(loyalty *LoyaltyService) {
+	s.loyalty = loyalty
+}
+
+// Fungsi bantu untuk menukar poin pelanggan sebagai potongan pembayaran. Nilai poin dikonversi ke mata uang wallet
+// dan dicatat sebagai transaksi penukaran poin yang ditanggung beban loyalitas. Mengembalikan nil jika tidak ada
+// poin yang ditukar. Poin yang dikembalikan harus dilepas dengan Release jika pembayaran tidak berhasil.
+func (s *TransactionService) redeemPoints(payment *models.Transaction, points int64) (*models.Transaction, *models.PointsEntry, error) {
+	if points == 0 {
+		return nil, nil, nil
+	}
+	if s.loyalty == nil {
+		return nil, nil, errors.New("penukaran poin tidak tersedia")
+	}
+
+	value, err := s.loyalty.RedeemValue(points)
+	if err != nil {
+		return nil, nil, err
+	}
+	if value.Currency() != payment.Amount.Currency() {
+		rate, err := s.rates.Rate(value.Currency(), payment.Amount.Currency())
+		if err != nil {
+			return nil, nil, fmt.Errorf("kurs %s ke %s tidak tersedia: %w", value.Currency(), payment.Amount.Currency(), err)
+		}
+		value, err = rate.Convert(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gagal mengonversi nilai poin: %w", err)
+		}
+	}
+	if !value.IsPositive() {
+		return nil, nil, errors.New("nilai poin yang ditukar terlalu kecil")
+	}
+	cmp, err := value.Cmp(payment.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cmp > 0 {
+		return nil, nil, fmt.Errorf("nilai poin yang ditukar %s melebihi jumlah pembayaran %s", value, payment.Amount)
+	}
+
+	entry, err := s.loyalty.Redeem(payment.CustomerID, points, payment.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redemption := &models.Transaction{
+		ID:          generateTransactionID(),
+		Type:        models.TransactionTypePointsRedemption,
+		CustomerID:  payment.CustomerID,
+		OriginalID:  payment.ID,
+		Currency:    value.Currency(),
+		Amount:      value,
+		Description: fmt.Sprintf("redeem %d points for transaction %s", points, payment.ID),
+		CreatedAt:   payment.CreatedAt,
+	}
+	applyNoFee(redemption)
+	startTransaction(redemption)
+
+	payment.PointsRedeemed = points
+	payment.PointsDiscount = &value
+	return redemption, entry, nil
+}
+
+// Fungsi bantu untuk menghitung poin yang didapat dari pembayaran. Poin dihitung dari jumlah yang dibayar dalam
+// mata uang merchant setelah dikurangi potongan penukaran poin.
+func (s *TransactionService) pointsToEarn(payment *models.Transaction) int64 {
+	if s.loyalty == nil {
+		return 0
+	}
+
+	paid := payment.Amount
+	if payment.PointsDiscount != nil {
+		var err error
+		paid, err = paid.Sub(*payment.PointsDiscount)
+		if err != nil {
+			return 0
+		}
+	}
+	share, err := merchantShare(payment, paid)
+	if err != nil {
+		log.Println("Gagal menghitung poin transaksi", payment.ID, ":", err)
+		return 0
+	}
+	points, err := s.loyalty.EarnedPoints(payment.MerchantID, share)
+	if err != nil {
+		log.Println("Gagal menghitung poin transaksi", payment.ID, ":", err)
+		return 0
+	}
+	return points
+}
+
+// Fungsi bantu untuk menjalankan proses setelah pembayaran berhasil: mencatat poin yang didapat pelanggan
+// lalu menerbitkan event pembayaran berhasil. Pembayaran tetap berhasil jika poin gagal dicatat.
+func (s *TransactionService) paymentSucceeded(payment *models.Transaction) {
+	if s.loyalty != nil && payment.PointsEarned > 0 {
+		err := s.loyalty.Earn(payment)
+		if err != nil {
+			log.Println("Gagal mencatat poin transaksi", payment.ID, ":", err)
+		}
+	}
+	s.publish(models.EventPaymentSucceeded, payment)
+}
+
+// Fungsi bantu untuk membuat transaksi penarikan kembali nilai penukaran poin yang sebanding dengan jumlah refund.
+// Refund terakhir menarik seluruh sisa nilainya. Mengembalikan nil jika pembayaran tidak menukar poin.
+func (s *TransactionService) pointsRedemptionReversal(original *models.Transaction, refund *models.Transaction, final bool) (*models.Transaction, error) {
+	if original.PointsDiscount == nil || !original.PointsDiscount.IsPositive() {
+		return nil, nil
+	}
+
+	amount, err := s.reversalShare(original, *original.PointsDiscount, models.TransactionTypePointsRedemptionReversal, refund, final)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung penarikan kembali penukaran poin: %w", err)
+	}
+	if !amount.IsPositive() {
+		return nil, nil
+	}
+
+	reversal := &models.Transaction{
+		ID:          generateTransactionID(),
+		Type:        models.TransactionTypePointsRedemptionReversal,
+		CustomerID:  original.CustomerID,
+		OriginalID:  original.ID,
+		Currency:    amount.Currency(),
+		Amount:      amount,
+		Description: fmt.Sprintf("points redemption reversal for refund %s", refund.ID),
+		CreatedAt:   refund.CreatedAt,
+	}
+	applyNoFee(reversal)
+	startTransaction(reversal)
+	err = transitionTransaction(reversal, models.TransactionStatusCaptured, "refund "+refund.ID)
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// Fungsi bantu untuk menyesuaikan poin pelanggan setelah refund berhasil: poin yang ditukar dikembalikan dan poin
+// yang didapat ditarik kembali, keduanya sebanding dengan jumlah refund. refundedBefore adalah total refund
+// sebelum refund ini dalam mata uang wallet.
+func (s *TransactionService) refundPoints(original *models.Transaction, refund *models.Transaction, refundedBefore money.Money) {
+	if s.loyalty == nil {
+		return
+	}
+	refundedAfter, err := refundedBefore.Add(refund.Amount)
+	if err != nil {
+		log.Println("Gagal menyesuaikan poin transaksi", original.ID, ":", err)
+		return
+	}
+
+	restored := pointsShare(original.PointsRedeemed, refundedBefore, refundedAfter, original.Amount)
+	if restored > 0 {
+		err = s.loyalty.Refund(original.CustomerID, restored, original.ID, fmt.Sprintf("points returned by refund %s", refund.ID))
+		if err != nil {
+			log.Println("Gagal mengembalikan poin transaksi", original.ID, ":", err)
+		}
+	}
+
+	reversed := pointsShare(original.PointsEarned, refundedBefore, refundedAfter, original.Amount)
+	if reversed > 0 {
+		_, err = s.loyalty.Reverse(original.CustomerID, reversed, original.ID, fmt.Sprintf("points reversed by refund %s", refund.ID))
+		if err != nil {
+			log.Println("Gagal menarik kembali poin transaksi", original.ID, ":", err)
+		}
+	}
+}
+
+// Fungsi bantu untuk menghitung bagian poin dari sebuah refund. Bagian dihitung dari total refund kumulatif
+// sehingga pembulatan ke bawah tidak menghilangkan poin dan refund terakhir selalu menggenapkan seluruh poin.
+func pointsShare(points int64, refundedBefore money.Money, refundedAfter money.Money, total money.Money) int64 {
+	if points <= 0 || !total.IsPositive() {
+		return 0
+	}
+	share := func(refunded money.Money) int64 {
+		product := new(big.Int).Mul(big.NewInt(points), big.NewInt(refunded.Amount()))
+		return product.Quo(product, big.NewInt(total.Amount())).Int64()
+	}
+	return share(refundedAfter) - share(refundedBefore)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+)
+
+// Fungsi bantu untuk memesan kuota promosi yang berlaku untuk pembayaran. Mengembalikan nil jika layanan
+// promosi belum diatur atau tidak ada promosi yang berlaku.
+func (s *TransactionService) reservePromotion(customerID string, merchantID string, amount money.Money, voucherCode string) (*models.Promotion, *models.Redemption, error) {
+	if s.promotions == nil {
+		if voucherCode != "" {
+			return nil, nil, fmt.Errorf("kode voucher %s tidak valid", voucherCode)
+		}
+		return nil, nil, nil
+	}
+	return s.promotions.Reserve(customerID, merchantID, amount, voucherCode)
+}
+
+// RecoverPromotions menyelesaikan kuota promosi yang masih dipesan karena server berhenti saat pembayaran diproses.
+// Pemesanan yang pembayarannya sudah tersimpan ditandai redeemed dan sisanya dikembalikan agar kuota dapat dipakai
+// kembali. Kuota hanya dipesan selama pembayaran diproses sehingga harus dipanggil sebelum server menerima permintaan.
+// Mengembalikan jumlah pemesanan yang ditandai redeemed dan yang dikembalikan.
+func (s *TransactionService) RecoverPromotions() (int, int, error) {
+	if s.promotions == nil {
+		return 0, 0, nil
+	}
+	redemptions, err := s.promotions.reserved()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	confirmed, released := 0, 0
+	claimed := make(map[string]bool)
+	for i := range redemptions {
+		redemption := &redemptions[i]
+		payment, err := s.reservedPayment(redemption, claimed)
+		if err != nil {
+			return confirmed, released, err
+		}
+		if payment != nil && TransactionStatus(payment) == models.TransactionStatusCaptured {
+			claimed[payment.ID] = true
+			err = s.promotions.Confirm(redemption, payment.ID)
+			if err != nil {
+				return confirmed, released, fmt.Errorf("gagal mencatat pemakaian promosi: %w", err)
+			}
+			confirmed++
+			continue
+		}
+
+		transactionID := ""
+		if payment != nil {
+			claimed[payment.ID] = true
+			transactionID = payment.ID
+		}
+		s.promotions.Release(redemption, transactionID)
+		released++
+	}
+
+	return confirmed, released, nil
+}
+
+// Fungsi bantu untuk mencari pembayaran yang tersimpan untuk pemesanan kuota promosi, yaitu pembayaran pertama
+// pelanggan dengan promosi yang sama setelah kuota dipesan yang belum tercatat pada pemakaian promosi lain.
+// Mengembalikan nil jika pembayaran belum tersimpan.
+func (s *TransactionService) reservedPayment(redemption *models.Redemption, claimed map[string]bool) (*models.Transaction, error) {
+	transactions, err := s.transactionRepository.GetTransactionsByCustomerID(redemption.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan riwayat transaksi: %w", err)
+	}
+
+	var payment *models.Transaction
+	for i := range transactions {
+		transaction := &transactions[i]
+		if transaction.Type != models.TransactionTypePayment || transaction.PromotionID != redemption.PromotionID ||
+			transaction.CreatedAt.Before(redemption.CreatedAt) || claimed[transaction.ID] || s.promotions.recorded(transaction.ID) {
+			continue
+		}
+		if payment == nil || transaction.CreatedAt.Before(payment.CreatedAt) {
+			payment = transaction
+		}
+	}
+	return payment, nil
+}
+
+// Fungsi bantu untuk mengkreditkan cashback promosi ke wallet pelanggan setelah pembayaran berhasil.
+// Cashback dicatat sebagai transaksi tersendiri yang ditanggung beban promosi dan dikonversi ke mata uang
+// wallet dengan kurs yang dikunci pada pembayaran. Pembayaran tetap berhasil jika cashback gagal dikreditkan.
+func (s *TransactionService) creditCashback(payment *models.Transaction, promotion *models.Promotion, benefit money.Money) {
+	log.Println("Mengkreditkan cashback...")
+
+	// Cashback tidak boleh mendahului refund pembayaran yang sama agar penarikan kembali cashback selalu tercatat
+	s.refundMu.Lock()
+	defer s.refundMu.Unlock()
+
+	stored, err := s.transactionRepository.GetTransactionByID(payment.ID)
+	if err != nil {
+		log.Println("Gagal mendapatkan transaksi", payment.ID, "untuk cashback:", err)
+		return
+	}
+	refunded, err := s.refundTotals(payment.ID)
+	if err != nil || TransactionStatus(stored) != models.TransactionStatusCaptured || refunded.amount.IsPositive() {
+		log.Println("Cashback transaksi", payment.ID, "tidak dikreditkan karena pembayaran sudah direfund")
+		return
+	}
+
+	rate, err := lockedRate(stored)
+	if err == nil {
+		benefit, err = rate.Convert(benefit)
+	}
+	if err != nil {
+		log.Println("Gagal mengonversi cashback transaksi", payment.ID, ":", err)
+		return
+	}
+	if !benefit.IsPositive() {
+		return
+	}
+
+	cashback := &models.Transaction{
+		ID:          generateTransactionID(),
+		Type:        models.TransactionTypeCashback,
+		CustomerID:  payment.CustomerID,
+		OriginalID:  payment.ID,
+		Currency:    benefit.Currency(),
+		Amount:      benefit,
+		Description: fmt.Sprintf("cashback %s for transaction %s", promotion.Name, payment.ID),
+		CreatedAt:   time.Now(),
+	}
+	applyNoFee(cashback)
+	startTransaction(cashback)
+
+	stored.Cashback = &benefit
+	_, err = s.walletRepository.Credit(payment.CustomerID, benefit, func() error {
+		err := transitionTransaction(cashback, models.TransactionStatusCaptured, "promosi "+promotion.ID)
+		if err != nil {
+			return err
+		}
+		return postAndSave(s.ledger, s.transactionRepository, cashbackEntry(cashback), cashback, stored)
+	})
+	if err != nil {
+		log.Println("Gagal mengkreditkan cashback transaksi", payment.ID, ":", err)
+		return
+	}
+
+	payment.Cashback = &benefit
+}
+
+// Fungsi bantu untuk membuat transaksi penarikan kembali cashback yang sebanding dengan jumlah refund.
+// Refund terakhir menarik seluruh sisa cashback. Mengembalikan nil jika pembayaran tidak mendapat cashback.
+func (s *TransactionService) cashbackReversal(original *models.Transaction, refund *models.Transaction, final bool) (*models.Transaction, error) {
+	if original.Cashback == nil || !original.Cashback.IsPositive() {
+		return nil, nil
+	}
+
+	amount, err := s.reversalShare(original, *original.Cashback, models.TransactionTypeCashbackReversal, refund, final)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung penarikan kembali cashback: %w", err)
+	}
+	if !amount.IsPositive() {
+		return nil, nil
+	}
+
+	reversal := &models.Transaction{
+		ID:          generateTransactionID(),
+		Type:        models.TransactionTypeCashbackReversal,
+		CustomerID:  original.CustomerID,
+		OriginalID:  original.ID,
+		Currency:    amount.Currency(),
+		Amount:      amount,
+		Description: fmt.Sprintf("cashback reversal for refund %s", refund.ID),
+		CreatedAt:   refund.CreatedAt,
+	}
+	applyNoFee(reversal)
+	startTransaction(reversal)
+	err = transitionTransaction(reversal, models.TransactionStatusCaptured, "refund "+refund.ID)
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// Fungsi bantu untuk menghitung bagian total yang ditarik kembali sebanding dengan jumlah refund. Refund terakhir
+// menarik seluruh sisa total yang belum ditarik oleh transaksi reversalType sebelumnya.
+func (s *TransactionService) reversalShare(original *models.Transaction, total money.Money, reversalType string, refund *models.Transaction, final bool) (money.Money, error) {
+	if !final {
+		return total.MulRat(refund.Amount.Amount(), original.Amount.Amount())
+	}
+
+	reversed := money.Zero(total.Currency())
+	related, err := s.transactionRepository.GetTransactionsByOriginalID(original.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	for _, transaction := range related {
+		if transaction.Type != reversalType {
+			continue
+		}
+		reversed, err = reversed.Add(transaction.Amount)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total.Sub(reversed)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/qris"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// DynamicQRExpiry adalah lama QR dinamis dapat dibayar sejak dibuat
+const DynamicQRExpiry = 30 * time.Minute
+
+// SetQRCodes mengatur repository tempat QR dinamis disimpan. Tanpa repository, QR dinamis tidak dapat dibuat
+// maupun dibayar.
+func (s *TransactionService) SetQRCodes(qrCodes repository.QRCodeRepository) {
+	s.qrCodes = qrCodes
+}
+
+// GenerateQR membuat payload QR pembayaran merchant. Amount kosong menghasilkan QR statis yang jumlahnya diisi
+// pelanggan, amount yang diisi menghasilkan QR dinamis untuk satu pembayaran dengan referensi tersebut.
+// Referensi QR dinamis yang kosong dibuat otomatis. QR dinamis disimpan beserta jumlah, merchant, dan masa
+// berlakunya, dan referensinya tidak boleh sudah pernah digunakan.
+func (s *TransactionService) GenerateQR(merchantID string, amount *money.Money, reference string) (*qris.Payload, string, error) {
+	merchant, err := s.merchantRepository.GetByID(merchantID)
+	if err != nil {
+		return nil, "", errors.New("ID merchant tidak valid")
+	}
+
+	payload := qris.Payload{
+		MerchantID:   merchant.ID,
+		MerchantName: qrMerchantName(merchant),
+		Currency:     money.Zero(merchant.Currency).Currency(),
+		Amount:       amount,
+		Reference:    reference,
+	}
+	if payload.Dynamic() && payload.Reference == "" {
+		payload.Reference = "QR" + generateTransactionID()
+	}
+
+	data, err := qris.Encode(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	if !payload.Dynamic() {
+		return &payload, data, nil
+	}
+	if s.qrCodes == nil {
+		return nil, "", errors.New("QR dinamis tidak tersedia")
+	}
+
+	s.qrMu.Lock()
+	defer s.qrMu.Unlock()
+
+	// Referensi yang sudah dipakai QR lain atau pembayaran merchant tidak dapat digunakan kembali
+	_, err = s.qrCodes.GetByReference(payload.Reference)
+	if err == nil {
+		return nil, "", errors.New("referensi QR sudah digunakan")
+	}
+	if !errors.Is(err, repository.ErrQRCodeNotFound) {
+		return nil, "", fmt.Errorf("gagal memeriksa referensi QR: %w", err)
+	}
+	paid, err := s.referencePaid(merchant.ID, payload.Reference)
+	if err != nil {
+		return nil, "", err
+	}
+	if paid {
+		return nil, "", errors.New("referensi QR sudah digunakan")
+	}
+
+	now := time.Now()
+	err = s.qrCodes.Save(&models.QRCode{
+		Reference:  payload.Reference,
+		MerchantID: merchant.ID,
+		Amount:     *payload.Amount,
+		Status:     models.QRCodeStatusActive,
+		ExpiresAt:  now.Add(DynamicQRExpiry),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("gagal menyimpan QR: %w", err)
+	}
+	return &payload, data, nil
+}
+
+// ProcessQRPayment membayar merchant dari payload QR yang dipindai pelanggan. Payload diperiksa checksumnya
+// dan dicocokkan dengan data merchant sebelum diproses seperti ProcessTransaction. Amount wajib diisi untuk
+// QR statis. QR dinamis dicocokkan dengan QR yang disimpan saat dibuat, menggunakan jumlah yang disimpan,
+// dan hanya dapat dibayar satu kali sebelum kedaluwarsa.
+// Options bersifat opsional seperti pada ProcessTransaction.
+func (s *TransactionService) ProcessQRPayment(customerID string, data string, amount *money.Money, options PaymentOptions) (*models.Transaction, error) {
+	log.Println("Memproses pembayaran QR...")
+
+	payload, err := qris.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("payload QR tidak valid: %w", err)
+	}
+
+	// Payload harus sesuai dengan merchant yang terdaftar
+	merchant, err := s.merchantRepository.GetByID(payload.MerchantID)
+	if err != nil {
+		return nil, errors.New("merchant pada QR tidak terdaftar")
+	}
+	if payload.MerchantName != qrMerchantName(merchant) {
+		return nil, errors.New("nama merchant pada QR tidak sesuai")
+	}
+	if payload.Currency != money.Zero(merchant.Currency).Currency() {
+		return nil, errors.New("mata uang pada QR tidak sesuai dengan merchant")
+	}
+
+	qrOptions := paymentOptions{PaymentOptions: options, Reference: payload.Reference}
+	if !payload.Dynamic() {
+		if amount == nil {
+			return nil, errors.New("jumlah pembayaran wajib diisi untuk QR statis")
+		}
+		return s.processPayment(customerID, merchant.ID, *amount, qrOptions)
+	}
+
+	if amount != nil {
+		cmp, err := amount.Cmp(*payload.Amount)
+		if err != nil || cmp != 0 {
+			return nil, fmt.Errorf("jumlah pembayaran harus sama dengan jumlah pada QR %s", payload.Amount)
+		}
+	}
+
+	if s.qrCodes == nil {
+		return nil, errors.New("QR dinamis tidak tersedia")
+	}
+
+	s.qrMu.Lock()
+	defer s.qrMu.Unlock()
+
+	// Checksum payload dapat dihitung ulang oleh siapa saja, sehingga jumlah dan merchant harus sama dengan
+	// QR yang disimpan saat dibuat
+	code, err := s.qrCodes.GetByReference(payload.Reference)
+	if errors.Is(err, repository.ErrQRCodeNotFound) {
+		return nil, errors.New("QR dinamis tidak terdaftar")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan QR: %w", err)
+	}
+	if code.MerchantID != merchant.ID {
+		return nil, errors.New("merchant pada QR tidak sesuai dengan QR yang dibuat")
+	}
+	cmp, err := code.Amount.Cmp(*payload.Amount)
+	if err != nil || cmp != 0 {
+		return nil, errors.New("jumlah pada QR tidak sesuai dengan QR yang dibuat")
+	}
+	if code.Status == models.QRCodeStatusPaid {
+		return nil, errors.New("QR dinamis sudah dibayar")
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, errors.New("QR dinamis sudah kedaluwarsa")
+	}
+
+	paid, err := s.referencePaid(merchant.ID, payload.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if paid {
+		return nil, errors.New("QR dinamis sudah dibayar")
+	}
+
+	transaction, err := s.processPayment(customerID, merchant.ID, code.Amount, qrOptions)
+	if err != nil {
+		return transaction, err
+	}
+
+	// Pembayaran sudah tersimpan, jika QR gagal ditandai lunas referensinya tetap ditolak oleh referencePaid
+	code.Status = models.QRCodeStatusPaid
+	code.TransactionID = transaction.ID
+	code.UpdatedAt = time.Now()
+	err = s.qrCodes.Save(code)
+	if err != nil {
+		log.Println("Gagal menandai QR dinamis sudah dibayar:", err)
+	}
+	return transaction, nil
+}
+
+// Fungsi bantu untuk memeriksa apakah referensi pembayaran merchant sudah memiliki pembayaran yang tidak gagal
+func (s *TransactionService) referencePaid(merchantID string, reference string) (bool, error) {
+	transactions, err := s.transactionRepository.GetAllTransactions()
+	if err != nil {
+		return false, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	for _, transaction := range transactions {
+		if transaction.MerchantID != merchantID || transaction.Reference != reference {
+			continue
+		}
+		if transaction.Type != "" && transaction.Type != models.TransactionTypePayment {
+			continue
+		}
+		if TransactionStatus(&transaction) != models.TransactionStatusFailed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Fungsi bantu untuk mendapatkan nama merchant sebagaimana ditulis pada payload QR
+func qrMerchantName(merchant *models.Merchant) string {
+	return qris.Truncate(merchant.Name, qris.MaxMerchantNameLength)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"log"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/risk"
+)
+
+// SetRisk mengatur layanan pemeriksaan risiko yang dijalankan sebelum pembayaran disimpan, nil berarti tanpa pemeriksaan
+func (s *TransactionService) SetRisk(risk *RiskService) {
+	s.risk = risk
+}
+
+// Fungsi bantu untuk memeriksa risiko pembayaran sebelum disimpan. Pembayaran yang ditolak dicatat ke catatan
+// risiko dan mengembalikan RiskError, pembayaran yang perlu diperiksa ditandai dengan keputusan dan alasannya.
+// Harus dipanggil di dalam penguncian wallet agar pembayaran bersamaan dari pelanggan yang sama dihitung berurutan.
+func (s *TransactionService) assessRisk(transaction *models.Transaction) error {
+	if s.risk == nil {
+		return nil
+	}
+
+	assessment, err := s.risk.Assess(transaction)
+	if err != nil {
+		return err
+	}
+
+	switch assessment.Decision {
+	case models.RiskDecisionDeny:
+		s.recordRisk(transaction, assessment)
+		return &RiskError{Reasons: assessment.Reasons}
+	case models.RiskDecisionReview:
+		transaction.RiskDecision = assessment.Decision
+		transaction.RiskReasons = assessment.Reasons
+	}
+	return nil
+}
+
+// Fungsi bantu untuk mencatat pembayaran yang ditandai review setelah pembayaran tersimpan
+func (s *TransactionService) recordReview(transaction *models.Transaction) {
+	if transaction.RiskDecision == models.RiskDecisionReview {
+		s.recordRisk(transaction, risk.Assessment{Decision: transaction.RiskDecision, Reasons: transaction.RiskReasons})
+	}
+}
+
+// Fungsi bantu untuk mencatat hasil pemeriksaan risiko, pembayaran tetap diproses jika pencatatan gagal
+func (s *TransactionService) recordRisk(transaction *models.Transaction, assessment risk.Assessment) {
+	err := s.risk.Record(transaction, assessment)
+	if err != nil {
+		log.Println("Gagal mencatat pemeriksaan risiko transaksi", transaction.ID, ":", err)
+	}
+}
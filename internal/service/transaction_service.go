@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/IbnuFarhanS/Golang_MNC/internal/fx"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/ledger"
 	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
 	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
 )
 
@@ -16,57 +20,485 @@ type TransactionService struct {
 	transactionRepository *repository.TransactionRepository
 	customerRepository    repository.CustomerRepository
 	merchantRepository    repository.MerchantRepository
+	walletRepository      repository.WalletRepository
+	ledger                *ledger.Ledger
+	rates                 fx.RateProvider
+	limits                *LimitService
+	events                EventPublisher
+	promotions            *PromotionService
+	loyalty               *LoyaltyService
+	risk                  *RiskService
+	qrCodes               repository.QRCodeRepository
+
+	// holdExpiry adalah lama saldo ditahan sebelum otorisasi dilepas otomatis
+	holdExpiry time.Duration
+
+	// refundMu memastikan pemeriksaan total refund dan penyimpanannya tidak saling mendahului
+	refundMu sync.Mutex
+	// holdMu memastikan capture, void, dan pelepasan otorisasi kedaluwarsa tidak saling mendahului
+	holdMu sync.Mutex
+	// qrMu memastikan QR dinamis yang sama tidak dibayar dua kali oleh pembayaran bersamaan
+	qrMu sync.Mutex
 }
 
-func NewTransactionService(transactionRepository *repository.TransactionRepository, customerRepository repository.CustomerRepository, merchantRepository repository.MerchantRepository) *TransactionService {
+func NewTransactionService(transactionRepository *repository.TransactionRepository, customerRepository repository.CustomerRepository, merchantRepository repository.MerchantRepository, walletRepository repository.WalletRepository, ledger *ledger.Ledger, rates fx.RateProvider, limits *LimitService) *TransactionService {
 	return &TransactionService{
 		transactionRepository: transactionRepository,
 		customerRepository:    customerRepository,
 		merchantRepository:    merchantRepository,
+		walletRepository:      walletRepository,
+		ledger:                ledger,
+		rates:                 rates,
+		limits:                limits,
+		holdExpiry:            DefaultHoldExpiry,
 	}
 }
 
-func (s *TransactionService) ProcessTransaction(customerID string, merchantID string, amount float64) error {
+// PaymentOptions berisi data opsional pembayaran langsung: kode voucher promosi, jumlah poin loyalitas
+// yang ditukar, dan ID perangkat yang digunakan untuk membayar
+type PaymentOptions struct {
+	VoucherCode  string
+	RedeemPoints int64
+	DeviceID     string
+}
+
+// paymentOptions menambahkan referensi pembayaran dari QR dinamis ke PaymentOptions
+type paymentOptions struct {
+	PaymentOptions
+	Reference string
+}
+
+// SetPromotions mengatur layanan promosi yang menghitung diskon dan cashback pembayaran, nil berarti tanpa promosi
+func (s *TransactionService) SetPromotions(promotions *PromotionService) {
+	s.promotions = promotions
+}
+
+// ProcessTransaction memproses pembayaran pelanggan ke merchant dan mengembalikan transaksi beserta statusnya.
+// Pembayaran yang ditolak karena saldo tidak mencukupi tetap dicatat dengan status failed.
+// Kode voucher bersifat opsional, tanpa kode voucher promosi otomatis yang berlaku tetap diterapkan.
+// RedeemPoints adalah poin loyalitas yang ditukar sebagai potongan pembayaran, nol berarti tanpa penukaran poin.
+// Pembayaran diperiksa oleh pemeriksaan risiko sebelum disimpan dan ditolak dengan RiskError jika tidak diizinkan.
+func (s *TransactionService) ProcessTransaction(customerID string, merchantID string, amount money.Money, options PaymentOptions) (*models.Transaction, error) {
+	return s.processPayment(customerID, merchantID, amount, paymentOptions{PaymentOptions: options})
+}
+
+// Fungsi bantu untuk memproses pembayaran langsung beserta promosi, penukaran poin, dan pemeriksaan risiko
+func (s *TransactionService) processPayment(customerID string, merchantID string, amount money.Money, options paymentOptions) (*models.Transaction, error) {
 	log.Println("Memproses transaksi...")
 
+	merchant, err := s.validatePayment(customerID, merchantID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Memesan kuota promosi yang berlaku, kuota dikembalikan jika pembayaran tidak berhasil
+	promotion, redemption, err := s.reservePromotion(customerID, merchantID, amount, options.VoucherCode)
+	if err != nil {
+		return nil, err
+	}
+	succeeded := false
+	transactionID := ""
+	if redemption != nil {
+		defer func() {
+			if !succeeded {
+				s.promotions.Release(redemption, transactionID)
+			}
+		}()
+	}
+
+	// Diskon ditanggung merchant sehingga mengurangi jumlah yang dibayar pelanggan
+	charged := amount
+	if promotion != nil && promotion.Type == models.PromotionTypeDiscount {
+		charged, err = amount.Sub(redemption.Benefit)
+		if err != nil {
+			return nil, err
+		}
+		if !charged.IsPositive() {
+			return nil, errors.New("jumlah pembayaran setelah diskon harus lebih dari nol")
+		}
+	}
+
+	// Membuat transaksi baru dan mengonversinya ke mata uang wallet pelanggan dengan kurs yang dikunci
+	log.Println("Membuat transaksi baru...")
+	transaction := newPayment(customerID, merchantID, charged)
+	transactionID = transaction.ID
+	transaction.Reference = options.Reference
+	transaction.DeviceID = options.DeviceID
+	if promotion != nil {
+		transaction.PromotionID = promotion.ID
+		transaction.VoucherCode = promotion.Code
+		if promotion.Type == models.PromotionTypeDiscount {
+			discount := redemption.Benefit
+			transaction.Discount = &discount
+		}
+	}
+	err = s.convertPayment(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Menghitung biaya merchant (MDR) dari jumlah pembayaran
+	err = applyMerchantFee(transaction, merchant)
+	if err != nil {
+		return nil, err
+	}
+
+	// Menukar poin loyalitas, poin dikembalikan jika pembayaran tidak berhasil. Nilai poin dikreditkan ke wallet
+	// bersamaan dengan pembayaran sehingga saldo yang didebit hanya sisa pembayaran setelah penukaran poin.
+	pointsRedemption, pointsEntry, err := s.redeemPoints(transaction, options.RedeemPoints)
+	if err != nil {
+		return nil, err
+	}
+	entries := []*ledger.Entry{paymentEntry(transaction)}
+	saved := []*models.Transaction{transaction}
+	debit := transaction.Amount
+	if pointsEntry != nil {
+		defer func() {
+			if !succeeded {
+				s.loyalty.Release(pointsEntry)
+			}
+		}()
+		entries = append(entries, pointsRedemptionEntry(pointsRedemption))
+		saved = append(saved, pointsRedemption)
+		debit, err = debit.Sub(pointsRedemption.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Memeriksa dan mendebit saldo pelanggan secara atomik, jurnal dan transaksi
+	// disimpan setelah saldo baru disimpan
+	log.Println("Mendebit saldo pelanggan...")
+	commit := func() error {
+		// Batas pengeluaran diperiksa di dalam penguncian wallet agar pembayaran bersamaan tidak saling mendahului
+		err := s.limits.CheckSpend(customerID, transaction.Amount, transaction.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		// Pemeriksaan risiko juga dijalankan di dalam penguncian wallet agar pembayaran bersamaan dihitung berurutan
+		err = s.assessRisk(transaction)
+		if err != nil {
+			return err
+		}
+
+		// Saldo sudah didebit sehingga pembayaran langsung diotorisasi dan di-capture
+		err = transitionTransaction(transaction, models.TransactionStatusAuthorized, "saldo didebit")
+		if err == nil {
+			err = transitionTransaction(transaction, models.TransactionStatusCaptured, "")
+		}
+		if err != nil {
+			return err
+		}
+		if pointsRedemption != nil {
+			err = transitionTransaction(pointsRedemption, models.TransactionStatusCaptured, "pembayaran "+transaction.ID)
+			if err != nil {
+				return err
+			}
+		}
+		transaction.PointsEarned = s.pointsToEarn(transaction)
+
+		// Memposting jurnal dan menyimpan transaksi ke repository
+		log.Println("Menyimpan transaksi...")
+		return postEntriesAndSave(s.ledger, s.transactionRepository, entries, saved...)
+	}
+	// Pembayaran yang seluruhnya dibayar dengan poin didebit sejumlah nol agar commit tetap berjalan selama wallet dikunci
+	_, err = s.walletRepository.Debit(customerID, debit, commit)
+	if err != nil {
+		// Mencatat pembayaran yang ditolak sebagai transaksi gagal
+		if isRejection(err) {
+			s.failTransaction(transaction, err)
+			return transaction, fmt.Errorf("transaksi ditolak: %w", err)
+		}
+		// Pembayaran yang ditolak pemeriksaan risiko hanya dicatat pada catatan risiko
+		var riskErr *RiskError
+		if errors.As(err, &riskErr) {
+			return nil, riskErr
+		}
+		return nil, fmt.Errorf("gagal menyimpan transaksi: %w", err)
+	}
+
+	log.Println("Transaksi berhasil diproses.")
+	succeeded = true
+	if redemption != nil {
+		err = s.promotions.Confirm(redemption, transaction.ID)
+		if err != nil {
+			log.Println("Gagal mencatat pemakaian promosi", promotion.ID, ":", err)
+		}
+		if promotion.Type == models.PromotionTypeCashback {
+			s.creditCashback(transaction, promotion, redemption.Benefit)
+		}
+	}
+	s.recordReview(transaction)
+	s.paymentSucceeded(transaction)
+
+	return transaction, nil
+}
+
+// Fungsi bantu untuk memvalidasi pelanggan, merchant, dan jumlah pembayaran lalu mengembalikan merchant
+func (s *TransactionService) validatePayment(customerID string, merchantID string, amount money.Money) (*models.Merchant, error) {
 	// Validasi customer ID
 	log.Println("Memvalidasi customer ID...")
 	_, err := s.customerRepository.GetByID(customerID)
 	if err != nil {
-		return errors.New("ID customer tidak valid")
+		return nil, errors.New("ID customer tidak valid")
 	}
 
 	// Validasi merchant ID
-	_, err = s.merchantRepository.GetByID(merchantID)
+	merchant, err := s.merchantRepository.GetByID(merchantID)
 	if err != nil {
-		return errors.New("ID merchant tidak valid")
+		return nil, errors.New("ID merchant tidak valid")
 	}
 
 	// Validasi jumlah transaksi
 	log.Println("Memvalidasi jumlah transaksi...")
-	if amount <= 0 {
-		return errors.New("jumlah transaksi tidak boleh kurang dari atau sama dengan nol")
+	if !amount.IsPositive() {
+		return nil, errors.New("jumlah transaksi tidak boleh kurang dari atau sama dengan nol")
 	}
 
-	// Membuat transaksi baru
-	log.Println("Membuat transaksi baru...")
+	// Pembayaran harus dalam mata uang yang digunakan merchant
+	merchantCurrency := money.Zero(merchant.Currency).Currency()
+	if amount.Currency() != merchantCurrency {
+		return nil, fmt.Errorf("merchant hanya menerima pembayaran dalam %s", merchantCurrency)
+	}
+
+	return merchant, nil
+}
+
+// Fungsi bantu untuk membuat transaksi pembayaran baru dengan status pending
+func newPayment(customerID string, merchantID string, amount money.Money) *models.Transaction {
 	transaction := &models.Transaction{
 		ID:         generateTransactionID(),
+		Type:       models.TransactionTypePayment,
 		CustomerID: customerID,
 		MerchantID: merchantID,
+		Currency:   amount.Currency(),
 		Amount:     amount,
+		CreatedAt:  time.Now(),
 	}
+	startTransaction(transaction)
+	return transaction
+}
+
+// Fungsi bantu untuk memeriksa apakah error adalah penolakan pembayaran yang perlu dicatat sebagai transaksi gagal,
+// yaitu saldo tidak mencukupi atau batas pengeluaran terlampaui
+func isRejection(err error) bool {
+	var limitErr *LimitError
+	return errors.Is(err, repository.ErrInsufficientFunds) || errors.As(err, &limitErr)
+}
 
-	// Menyimpan transaksi ke repository
-	log.Println("Menyimpan transaksi...")
-	err = s.transactionRepository.SaveTransaction(transaction)
+// Fungsi bantu untuk menandai transaksi gagal dan menyimpannya sebagai catatan
+func (s *TransactionService) failTransaction(transaction *models.Transaction, cause error) {
+	err := transitionTransaction(transaction, models.TransactionStatusFailed, cause.Error())
+	if err == nil {
+		err = s.transactionRepository.SaveTransaction(transaction)
+	}
 	if err != nil {
-		return fmt.Errorf("gagal menyimpan transaksi: %w", err)
+		log.Println("Gagal mencatat transaksi gagal:", err)
+		return
 	}
+	s.publish(models.EventPaymentFailed, transaction)
+}
 
-	log.Println("Transaksi berhasil diproses.")
+// RefundTransaction mengembalikan sebagian atau seluruh pembayaran ke saldo pelanggan atas permintaan merchant
+// penerima pembayaran. Amount nol berarti mengembalikan seluruh sisa pembayaran yang belum direfund.
+func (s *TransactionService) RefundTransaction(merchantID string, transactionID string, amount money.Money, reason string) (*models.Transaction, error) {
+	log.Println("Memproses refund...")
 
-	return nil
+	s.refundMu.Lock()
+	defer s.refundMu.Unlock()
+
+	// Validasi transaksi asal
+	original, err := s.transactionRepository.GetTransactionByID(transactionID)
+	if err != nil {
+		return nil, errors.New("ID transaksi tidak valid")
+	}
+	if original.MerchantID != merchantID {
+		return nil, errors.New("transaksi bukan milik merchant")
+	}
+	if original.Type != "" && original.Type != models.TransactionTypePayment {
+		return nil, errors.New("hanya transaksi pembayaran yang dapat direfund")
+	}
+	if TransactionStatus(original) != models.TransactionStatusCaptured {
+		return nil, fmt.Errorf("transaksi dengan status %s tidak dapat direfund", TransactionStatus(original))
+	}
+
+	// Menghitung total yang sudah direfund
+	refunded, err := s.refundTotals(original.ID)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := original.Amount.Sub(refunded.amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validasi jumlah refund
+	log.Println("Memvalidasi jumlah refund...")
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if !remaining.IsPositive() {
+		return nil, errors.New("transaksi sudah direfund seluruhnya")
+	}
+	cmp, err := amount.Cmp(remaining)
+	if err != nil {
+		return nil, fmt.Errorf("mata uang refund tidak valid: %w", err)
+	}
+	if !amount.IsPositive() {
+		return nil, errors.New("jumlah refund tidak boleh kurang dari nol")
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("jumlah refund melebihi sisa pembayaran %s", remaining)
+	}
+
+	description := fmt.Sprintf("refund for transaction %s", original.ID)
+	if reason != "" {
+		description += ": " + reason
+	}
+	refund := &models.Transaction{
+		ID:          generateTransactionID(),
+		Type:        models.TransactionTypeRefund,
+		CustomerID:  original.CustomerID,
+		MerchantID:  original.MerchantID,
+		OriginalID:  original.ID,
+		Currency:    amount.Currency(),
+		Amount:      amount,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+
+	// Refund pembayaran valas menggunakan kurs yang dikunci saat pembayaran,
+	// refund terakhir mengembalikan seluruh sisa jumlah dalam mata uang merchant
+	if original.OriginalAmount != nil {
+		refundOriginal, err := merchantShare(original, amount)
+		if err != nil {
+			return nil, err
+		}
+		if cmp == 0 {
+			refundOriginal, err = original.OriginalAmount.Sub(refunded.original)
+			if err != nil {
+				return nil, err
+			}
+		}
+		refund.OriginalAmount = &refundOriginal
+		refund.ExchangeRate = original.ExchangeRate
+		refund.RateLockedAt = original.RateLockedAt
+	}
+
+	// Biaya merchant dikembalikan sebanding dengan jumlah refund
+	err = applyRefundFee(refund, original, refunded.fee, cmp == 0)
+	if err != nil {
+		return nil, err
+	}
+	startTransaction(refund)
+	err = transitionTransaction(refund, models.TransactionStatusCaptured, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Transaksi asal berstatus refunded setelah seluruh pembayaran dikembalikan
+	updated := []*models.Transaction{refund}
+	if cmp == 0 {
+		err = transitionTransaction(original, models.TransactionStatusRefunded, "refund "+refund.ID)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, original)
+	}
+
+	// Cashback promosi dan nilai penukaran poin ditarik kembali sebanding dengan jumlah refund
+	// dan dipotong dari dana yang dikembalikan
+	entries := []*ledger.Entry{refundEntry(refund)}
+	credit := amount
+	reversal, err := s.cashbackReversal(original, refund, cmp == 0)
+	if err != nil {
+		return nil, err
+	}
+	if reversal != nil {
+		credit, err = credit.Sub(reversal.Amount)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, cashbackReversalEntry(reversal))
+		updated = append(updated, reversal)
+	}
+	pointsReversal, err := s.pointsRedemptionReversal(original, refund, cmp == 0)
+	if err != nil {
+		return nil, err
+	}
+	if pointsReversal != nil {
+		credit, err = credit.Sub(pointsReversal.Amount)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, pointsRedemptionReversalEntry(pointsReversal))
+		updated = append(updated, pointsReversal)
+	}
+
+	// Mengembalikan dana ke saldo pelanggan, jurnal dan refund disimpan setelah saldo baru disimpan
+	log.Println("Mengembalikan dana ke saldo pelanggan...")
+	commit := func() error {
+		log.Println("Menyimpan refund...")
+		return postEntriesAndSave(s.ledger, s.transactionRepository, entries, updated...)
+	}
+	if credit.IsPositive() {
+		_, err = s.walletRepository.Credit(original.CustomerID, credit, commit)
+	} else {
+		// Cashback dan penukaran poin yang ditarik kembali melebihi dana refund sehingga selisihnya didebit. Jika seluruh
+		// dana refund habis untuk menarik kembali keduanya, debit sejumlah nol tetap menjalankan commit selama wallet dikunci.
+		_, err = s.walletRepository.Debit(original.CustomerID, credit.Neg(), commit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan refund: %w", err)
+	}
+
+	log.Println("Refund berhasil diproses.")
+	s.refundPoints(original, refund, refunded.amount)
+	s.publish(models.EventRefundCreated, refund)
+
+	return refund, nil
+}
+
+// RefundedAmount menghitung total refund yang sudah tercatat untuk sebuah transaksi
+func (s *TransactionService) RefundedAmount(transactionID string) (money.Money, error) {
+	total, err := s.refundTotals(transactionID)
+	return total.amount, err
+}
+
+// refundTotal berisi total refund dalam mata uang wallet, dalam mata uang merchant, dan total biaya merchant yang dikembalikan
+type refundTotal struct {
+	amount   money.Money
+	original money.Money
+	fee      money.Money
+}
+
+// Fungsi bantu untuk menghitung total refund yang sudah tercatat untuk sebuah transaksi
+func (s *TransactionService) refundTotals(transactionID string) (refundTotal, error) {
+	var total refundTotal
+	refunds, err := s.transactionRepository.GetTransactionsByOriginalID(transactionID)
+	if err != nil {
+		return total, fmt.Errorf("gagal mendapatkan refund: %w", err)
+	}
+
+	for i := range refunds {
+		refund := &refunds[i]
+		if refund.Type != models.TransactionTypeRefund {
+			continue
+		}
+		total.amount, err = total.amount.Add(refund.Amount)
+		if err == nil {
+			total.original, err = total.original.Add(merchantAmount(refund))
+		}
+		if err == nil {
+			total.fee, err = total.fee.Add(refund.Fee)
+		}
+		if err != nil {
+			return refundTotal{}, err
+		}
+	}
+
+	return total, nil
 }
 
 func (s *TransactionService) GetMerchantNameByID(merchantID string) (string, error) {
@@ -78,8 +510,22 @@ func (s *TransactionService) GetMerchantNameByID(merchantID string) (string, err
 	return merchant.Name, nil
 }
 
+var (
+	transactionIDMu   sync.Mutex
+	lastTransactionID int64
+)
+
 // Fungsi bantu untuk menghasilkan ID transaksi yang unik
 func generateTransactionID() string {
+	transactionIDMu.Lock()
+	defer transactionIDMu.Unlock()
+
 	transactionCounter := time.Now().UnixNano() // Menggunakan timestamp saat ini sebagai basis ID transaksi
+	// Menjaga ID tetap unik ketika beberapa transaksi dibuat pada nanodetik yang sama
+	if transactionCounter <= lastTransactionID {
+		transactionCounter = lastTransactionID + 1
+	}
+	lastTransactionID = transactionCounter
+
 	return strconv.FormatInt(transactionCounter, 10)
 }
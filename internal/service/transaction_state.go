@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+)
+
+// transactionTransitions berisi perpindahan status transaksi yang diperbolehkan.
+// Transaksi satu tahap seperti top-up dan transfer boleh langsung berpindah dari pending ke captured.
+var transactionTransitions = map[string][]string{
+	models.TransactionStatusPending: {
+		models.TransactionStatusAuthorized,
+		models.TransactionStatusCaptured,
+		models.TransactionStatusFailed,
+	},
+	models.TransactionStatusAuthorized: {
+		models.TransactionStatusCaptured,
+		models.TransactionStatusVoided,
+		models.TransactionStatusFailed,
+	},
+	models.TransactionStatusCaptured: {
+		models.TransactionStatusRefunded,
+	},
+	models.TransactionStatusFailed:   {},
+	models.TransactionStatusVoided:   {},
+	models.TransactionStatusRefunded: {},
+}
+
+// TransactionStatus mengembalikan status transaksi saat ini.
+// Transaksi lama yang tersimpan sebelum ada status dianggap sudah captured.
+func TransactionStatus(transaction *models.Transaction) string {
+	if transaction.Status == "" {
+		return models.TransactionStatusCaptured
+	}
+	return transaction.Status
+}
+
+// Fungsi bantu untuk memulai siklus hidup transaksi baru dengan status pending
+func startTransaction(transaction *models.Transaction) {
+	transaction.Status = models.TransactionStatusPending
+	transaction.StatusHistory = []models.StatusChange{{
+		To: models.TransactionStatusPending,
+		At: time.Now(),
+	}}
+}
+
+// Fungsi bantu untuk memindahkan status transaksi dan mencatatnya ke riwayat status.
+// Perpindahan yang tidak diperbolehkan akan ditolak tanpa mengubah transaksi.
+func transitionTransaction(transaction *models.Transaction, to string, reason string) error {
+	from := TransactionStatus(transaction)
+
+	allowed := false
+	for _, next := range transactionTransitions[from] {
+		if next == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("status transaksi tidak dapat berubah dari %s ke %s", from, to)
+	}
+
+	transaction.Status = to
+	transaction.StatusHistory = append(transaction.StatusHistory, models.StatusChange{
+		From:   from,
+		To:     to,
+		At:     time.Now(),
+		Reason: reason,
+	})
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/funding"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/ledger"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// WalletService menangani operasi terkait saldo wallet pelanggan
+type WalletService struct {
+	walletRepository      repository.WalletRepository
+	customerRepository    repository.CustomerRepository
+	transactionRepository *repository.TransactionRepository
+	fundingSources        *funding.Registry
+	ledger                *ledger.Ledger
+	limits                *LimitService
+}
+
+// NewWalletService membuat instance baru dari WalletService
+func NewWalletService(walletRepository repository.WalletRepository, customerRepository repository.CustomerRepository, transactionRepository *repository.TransactionRepository, fundingSources *funding.Registry, ledger *ledger.Ledger, limits *LimitService) *WalletService {
+	return &WalletService{
+		walletRepository:      walletRepository,
+		customerRepository:    customerRepository,
+		transactionRepository: transactionRepository,
+		fundingSources:        fundingSources,
+		ledger:                ledger,
+		limits:                limits,
+	}
+}
+
+// GetWallet mengambil wallet milik pelanggan
+func (s *WalletService) GetWallet(customerID string) (*models.Wallet, error) {
+	return s.walletRepository.GetByCustomerID(customerID)
+}
+
+// AccountBalance adalah saldo sebuah akun buku besar
+type AccountBalance struct {
+	Account ledger.Account `json:"account"`
+	Balance money.Money    `json:"balance"`
+}
+
+// LedgerBalances mengambil saldo akun wallet dan saldo ditahan milik pelanggan dari buku besar
+// pada waktu tertentu. Waktu nol berarti saldo saat ini.
+func (s *WalletService) LedgerBalances(customerID string, at time.Time) ([]AccountBalance, error) {
+	wallet, err := s.walletRepository.GetByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := wallet.Currency
+	balances := make([]AccountBalance, 0, 2)
+	for _, account := range []ledger.Account{ledger.CustomerWallet(customerID, currency), ledger.CustomerHold(customerID, currency)} {
+		balance, err := s.ledger.BalanceAt(account.ID, at)
+		if errors.Is(err, ledger.ErrAccountNotFound) {
+			// Akun yang belum pernah dipakai dianggap bersaldo nol
+			balance, err = money.Zero(currency), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghitung saldo buku besar: %w", err)
+		}
+		balances = append(balances, AccountBalance{Account: account, Balance: balance})
+	}
+
+	return balances, nil
+}
+
+// TopUp menambah saldo wallet pelanggan dari sumber dana yang dipilih
+func (s *WalletService) TopUp(customerID string, sourceName string, amount money.Money) (*models.Transaction, *models.Wallet, error) {
+	log.Println("Memproses top-up...")
+
+	// Validasi jumlah top-up
+	if !amount.IsPositive() {
+		return nil, nil, errors.New("jumlah top-up tidak boleh kurang dari atau sama dengan nol")
+	}
+	// Mata uang diperiksa sebelum dana ditarik dari sumber dana
+	err := s.checkWalletCurrency(customerID, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Mengambil sumber dana yang dipilih
+	source, err := s.fundingSources.Get(sourceName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sumber dana tidak valid: %w", err)
+	}
+
+	// Menarik dana dari sumber dana
+	log.Println("Menarik dana dari", source.Name())
+	result, err := source.Fund(funding.Request{
+		CustomerID: customerID,
+		Amount:     amount,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal menarik dana: %w", err)
+	}
+
+	// Mencatat top-up sebagai transaksi
+	transaction := &models.Transaction{
+		ID:          generateTransactionID(),
+		Type:        models.TransactionTypeTopUp,
+		CustomerID:  customerID,
+		Currency:    result.Amount.Currency(),
+		Amount:      result.Amount,
+		Description: fmt.Sprintf("top up via %s %s ref %s", result.Source, result.AccountNumber, result.Reference),
+		CreatedAt:   result.ProcessedAt,
+	}
+	applyNoFee(transaction)
+	startTransaction(transaction)
+
+	// Menambah saldo wallet pelanggan, jurnal dan transaksi disimpan setelah saldo baru disimpan
+	log.Println("Menambah saldo wallet...")
+	wallet, err := s.walletRepository.Credit(customerID, result.Amount, func() error {
+		err := transitionTransaction(transaction, models.TransactionStatusCaptured, "dana diterima dari "+result.Source)
+		if err != nil {
+			return err
+		}
+		return postAndSave(s.ledger, s.transactionRepository, topUpEntry(transaction, result.Source), transaction)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal menambah saldo: %w", err)
+	}
+
+	log.Println("Top-up berhasil diproses.")
+
+	return transaction, wallet, nil
+}
+
+// Transfer memindahkan saldo dari pelanggan pengirim ke pelanggan lain yang dicari
+// berdasarkan username atau nomor telepon. Pendebitan, pengkreditan, dan pencatatan
+// kedua transaksi terjadi bersama atau gagal bersama.
+func (s *WalletService) Transfer(senderID string, recipient string, amount money.Money, note string) (*models.Transaction, *models.Wallet, error) {
+	log.Println("Memproses transfer...")
+
+	// Validasi jumlah transfer
+	if !amount.IsPositive() {
+		return nil, nil, errors.New("jumlah transfer tidak boleh kurang dari atau sama dengan nol")
+	}
+	err := s.checkWalletCurrency(senderID, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validasi pelanggan pengirim
+	sender, err := s.customerRepository.GetByID(senderID)
+	if err != nil {
+		return nil, nil, errors.New("ID customer tidak valid")
+	}
+
+	// Mencari pelanggan penerima
+	log.Println("Mencari pelanggan penerima...")
+	receiver, err := s.findRecipient(recipient)
+	if err != nil {
+		return nil, nil, errors.New("pelanggan penerima tidak ditemukan")
+	}
+	if receiver.ID == senderID {
+		return nil, nil, errors.New("tidak dapat melakukan transfer ke diri sendiri")
+	}
+
+	// Mencatat transaksi untuk kedua pihak
+	if note != "" {
+		note = ": " + note
+	}
+	now := time.Now()
+	outgoing := &models.Transaction{
+		ID:             generateTransactionID(),
+		Type:           models.TransactionTypeTransferOut,
+		CustomerID:     senderID,
+		CounterpartyID: receiver.ID,
+		Currency:       amount.Currency(),
+		Amount:         amount,
+		Description:    fmt.Sprintf("transfer to %s%s", receiver.Username, note),
+		CreatedAt:      now,
+	}
+	incoming := &models.Transaction{
+		ID:             generateTransactionID(),
+		Type:           models.TransactionTypeTransferIn,
+		CustomerID:     receiver.ID,
+		CounterpartyID: senderID,
+		Currency:       amount.Currency(),
+		Amount:         amount,
+		Description:    fmt.Sprintf("transfer from %s%s", sender.Username, note),
+		CreatedAt:      now,
+	}
+
+	// Memindahkan saldo, jurnal dan kedua transaksi disimpan setelah saldo baru disimpan
+	log.Println("Memindahkan saldo...")
+	wallet, _, err := s.walletRepository.Transfer(senderID, receiver.ID, amount, func() error {
+		err := s.limits.CheckSpend(senderID, amount, now)
+		if err != nil {
+			return err
+		}
+		for _, transaction := range []*models.Transaction{outgoing, incoming} {
+			applyNoFee(transaction)
+			startTransaction(transaction)
+			err = transitionTransaction(transaction, models.TransactionStatusCaptured, "")
+			if err != nil {
+				return err
+			}
+		}
+		return postAndSave(s.ledger, s.transactionRepository, transferEntry(outgoing), outgoing, incoming)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("transfer gagal: %w", err)
+	}
+
+	log.Println("Transfer berhasil diproses.")
+
+	return outgoing, wallet, nil
+}
+
+// Fungsi bantu untuk memastikan jumlah top-up atau transfer dalam mata uang wallet pelanggan
+func (s *WalletService) checkWalletCurrency(customerID string, amount money.Money) error {
+	wallet, err := s.walletRepository.GetByCustomerID(customerID)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan wallet: %w", err)
+	}
+	if amount.Currency() != wallet.Currency {
+		return fmt.Errorf("wallet hanya mendukung mata uang %s", wallet.Currency)
+	}
+	return nil
+}
+
+// Fungsi bantu untuk mencari pelanggan penerima transfer
+func (s *WalletService) findRecipient(recipient string) (*models.Customer, error) {
+	return findCustomer(s.customerRepository, recipient)
+}
+
+// Fungsi bantu untuk mencari pelanggan berdasarkan username atau nomor telepon
+func findCustomer(customerRepository repository.CustomerRepository, identifier string) (*models.Customer, error) {
+	customer, err := customerRepository.GetByUsername(identifier)
+	if err == nil {
+		return customer, nil
+	}
+
+	phone, convErr := strconv.Atoi(identifier)
+	if convErr != nil {
+		return nil, err
+	}
+	return customerRepository.GetByPhone(phone)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// errNonPublicAddress dikembalikan ketika endpoint webhook mengarah ke alamat yang bukan alamat publik
+var errNonPublicAddress = errors.New("URL webhook tidak boleh mengarah ke alamat internal")
+
+// nonPublicNetworks adalah rentang alamat khusus yang tidak dikenali oleh method net.IP, seperti
+// 0.0.0.0/8, shared address space (CGNAT), IETF protocol assignments, jaringan benchmark, dan alamat cadangan
+var nonPublicNetworks = parseNetworks("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4")
+
+// Fungsi bantu untuk memeriksa apakah alamat IP adalah alamat publik, yaitu bukan loopback, private,
+// link-local (termasuk metadata cloud 169.254.169.254), multicast, atau rentang khusus lainnya
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fungsi bantu untuk memastikan seluruh alamat host endpoint webhook adalah alamat publik.
+// Host yang berupa nama di-resolve terlebih dahulu dan ditolak jika salah satu alamatnya bukan alamat publik.
+func checkWebhookHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return errNonPublicAddress
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), WebhookTimeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addresses) == 0 {
+		return fmt.Errorf("host webhook %s tidak dapat ditemukan", host)
+	}
+	for _, address := range addresses {
+		if !isPublicIP(address.IP) {
+			return errNonPublicAddress
+		}
+	}
+	return nil
+}
+
+// Fungsi bantu untuk membuat http.Client pengiriman webhook yang hanya terhubung ke alamat publik. Alamat diperiksa
+// saat koneksi dibuat setelah DNS di-resolve, sehingga nama host yang kemudian diarahkan ke alamat internal dan
+// redirect ke alamat internal tetap ditolak. Proxy tidak digunakan agar alamat yang diperiksa adalah alamat tujuan.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: WebhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", errNonPublicAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: WebhookTimeout, Transport: transport}
+}
+
+// Fungsi bantu untuk membaca daftar rentang alamat dalam notasi CIDR, hanya untuk nilai konstan
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,545 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+// Pengaturan percobaan pengiriman webhook. Jeda percobaan digandakan setiap kali gagal sampai WebhookMaxRetryDelay.
+const (
+	WebhookMaxAttempts   = 8
+	WebhookRetryDelay    = 30 * time.Second
+	WebhookMaxRetryDelay = time.Hour
+	WebhookTimeout       = 10 * time.Second
+)
+
+// Header yang dikirim bersama setiap pengiriman webhook
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookEventIDHeader   = "X-Webhook-Event-ID"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// WebhookService mengelola endpoint webhook merchant dan mengirim event transaksi melalui outbox
+// yang dicoba ulang dengan jeda eksponensial sampai berhasil atau percobaan habis
+type WebhookService struct {
+	webhookRepository  repository.WebhookRepository
+	deliveryRepository repository.WebhookDeliveryRepository
+	merchantRepository repository.MerchantRepository
+	client             *http.Client
+
+	// mu memastikan perubahan pengaturan webhook merchant tidak saling menimpa
+	mu sync.Mutex
+	// dispatchMu memastikan satu pengiriman tidak dikirim dua kali oleh proses pengiriman bersamaan
+	dispatchMu sync.Mutex
+	// notify membangunkan dispatcher ketika ada pengiriman baru
+	notify chan struct{}
+}
+
+// NewWebhookService membuat instance baru dari WebhookService. client nil berarti http.Client dengan WebhookTimeout
+// yang hanya mengirim ke alamat publik.
+func NewWebhookService(webhookRepository repository.WebhookRepository, deliveryRepository repository.WebhookDeliveryRepository, merchantRepository repository.MerchantRepository, client *http.Client) *WebhookService {
+	if client == nil {
+		client = newWebhookClient()
+	}
+	return &WebhookService{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		merchantRepository: merchantRepository,
+		client:             client,
+		notify:             make(chan struct{}, 1),
+	}
+}
+
+// SignPayload menghitung tanda tangan pengiriman webhook, yaitu HMAC-SHA256 dengan secret merchant dari
+// "<timestamp>.<body>" yang ditulis dalam hex. Header X-Webhook-Signature berisi "t=<timestamp>,v1=<tanda tangan>".
+func SignPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetConfig mengambil pengaturan webhook merchant beserta secret untuk memverifikasi tanda tangan
+func (s *WebhookService) GetConfig(merchantID string) (*models.WebhookConfig, error) {
+	config, err := s.webhookRepository.GetByMerchantID(merchantID)
+	if errors.Is(err, repository.ErrWebhookConfigNotFound) {
+		return &models.WebhookConfig{MerchantID: merchantID, Endpoints: []models.WebhookEndpoint{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pengaturan webhook: %w", err)
+	}
+	return config, nil
+}
+
+// RegisterEndpoint mendaftarkan URL penerima event merchant. events kosong berarti seluruh jenis event.
+// Secret merchant dibuat ketika endpoint pertama didaftarkan. URL yang mengarah ke alamat loopback, private,
+// atau link-local ditolak agar server tidak dapat digunakan untuk mengirim permintaan ke jaringan internal.
+func (s *WebhookService) RegisterEndpoint(merchantID string, endpointURL string, events []string) (*models.WebhookConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.merchantRepository.GetByID(merchantID)
+	if err != nil {
+		return nil, errors.New("ID merchant tidak valid")
+	}
+	parsed, err := url.Parse(endpointURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("URL webhook harus berupa URL http atau https")
+	}
+	err = checkWebhookHost(parsed.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if !isEventType(event) {
+			return nil, fmt.Errorf("jenis event %s tidak dikenal", event)
+		}
+	}
+
+	config, err := s.GetConfig(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range config.Endpoints {
+		if endpoint.URL == endpointURL {
+			return nil, errors.New("URL webhook sudah terdaftar")
+		}
+	}
+	if config.Secret == "" {
+		config.Secret, err = generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	config.Endpoints = append(config.Endpoints, models.WebhookEndpoint{
+		ID:        "WH" + generateTransactionID(),
+		URL:       endpointURL,
+		Events:    events,
+		CreatedAt: now,
+	})
+	config.UpdatedAt = now
+	err = s.webhookRepository.Save(config)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pengaturan webhook: %w", err)
+	}
+
+	return config, nil
+}
+
+// DeleteEndpoint menghapus endpoint webhook merchant dan membatalkan pengiriman ke endpoint tersebut yang masih tertunda
+func (s *WebhookService) DeleteEndpoint(merchantID string, endpointID string) (*models.WebhookConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, err := s.GetConfig(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]models.WebhookEndpoint, 0, len(config.Endpoints))
+	for _, endpoint := range config.Endpoints {
+		if endpoint.ID != endpointID {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if len(endpoints) == len(config.Endpoints) {
+		return nil, errors.New("ID endpoint webhook tidak valid")
+	}
+
+	config.Endpoints = endpoints
+	config.UpdatedAt = time.Now()
+	err = s.webhookRepository.Save(config)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pengaturan webhook: %w", err)
+	}
+
+	// Pengiriman yang sedang dikirim dispatcher tidak ikut dibatalkan di sini, tetapi dibatalkan oleh deliver
+	// pada percobaan berikutnya karena endpoint-nya sudah tidak ada
+	deliveries, err := s.deliveryRepository.GetByMerchantID(merchantID)
+	if err != nil {
+		log.Println("Gagal mendapatkan pengiriman webhook:", err)
+		return config, nil
+	}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if delivery.EndpointID != endpointID || delivery.Status != models.WebhookDeliveryPending {
+			continue
+		}
+		err = s.cancel(delivery)
+		if err != nil {
+			log.Println("Gagal membatalkan pengiriman webhook", delivery.ID, ":", err)
+		}
+	}
+
+	return config, nil
+}
+
+// RotateSecret mengganti secret merchant. Pengiriman berikutnya, termasuk percobaan ulang, ditandatangani dengan secret baru.
+func (s *WebhookService) RotateSecret(merchantID string) (*models.WebhookConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, err := s.GetConfig(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Endpoints) == 0 {
+		return nil, errors.New("merchant belum memiliki endpoint webhook")
+	}
+
+	config.Secret, err = generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	config.UpdatedAt = time.Now()
+	err = s.webhookRepository.Save(config)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pengaturan webhook: %w", err)
+	}
+
+	return config, nil
+}
+
+// Publish mencatat event transaksi ke outbox untuk setiap endpoint merchant yang berlangganan event tersebut.
+// Pengiriman dilakukan oleh dispatcher di background sehingga transaksi tidak menunggu merchant.
+func (s *WebhookService) Publish(eventType string, transaction *models.Transaction) {
+	config, err := s.webhookRepository.GetByMerchantID(transaction.MerchantID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrWebhookConfigNotFound) {
+			log.Println("Gagal mendapatkan pengaturan webhook:", err)
+		}
+		return
+	}
+
+	now := time.Now()
+	event := models.WebhookEvent{
+		ID:        "EVT" + generateTransactionID(),
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      webhookEventData(transaction),
+	}
+	payload, err := json.Marshal(&event)
+	if err != nil {
+		log.Println("Gagal mengodekan event webhook:", err)
+		return
+	}
+
+	queued := false
+	for _, endpoint := range config.Endpoints {
+		if !subscribes(&endpoint, eventType) {
+			continue
+		}
+		delivery := &models.WebhookDelivery{
+			ID:            "WHD" + generateTransactionID(),
+			MerchantID:    config.MerchantID,
+			EndpointID:    endpoint.ID,
+			URL:           endpoint.URL,
+			EventID:       event.ID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        models.WebhookDeliveryPending,
+			Attempts:      []models.WebhookAttempt{},
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		err = s.deliveryRepository.Save(delivery)
+		if err != nil {
+			log.Println("Gagal menyimpan pengiriman webhook", event.ID, ":", err)
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		s.wake()
+	}
+}
+
+// GetDeliveries mengambil log pengiriman webhook merchant
+func (s *WebhookService) GetDeliveries(merchantID string) ([]models.WebhookDelivery, error) {
+	deliveries, err := s.deliveryRepository.GetByMerchantID(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pengiriman webhook: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDelivery mengambil pengiriman webhook merchant beserta riwayat percobaannya
+func (s *WebhookService) GetDelivery(merchantID string, deliveryID string) (*models.WebhookDelivery, error) {
+	delivery, err := s.deliveryRepository.GetByID(deliveryID)
+	if err != nil {
+		return nil, errors.New("ID pengiriman webhook tidak valid")
+	}
+	if delivery.MerchantID != merchantID {
+		return nil, errors.New("pengiriman webhook bukan milik merchant")
+	}
+	return delivery, nil
+}
+
+// Redeliver mengirim ulang event dari pengiriman sebelumnya sebagai pengiriman baru dengan event ID yang sama
+// sehingga penerima dapat mengenali event yang sudah pernah diproses
+func (s *WebhookService) Redeliver(merchantID string, deliveryID string) (*models.WebhookDelivery, error) {
+	original, err := s.GetDelivery(merchantID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.GetConfig(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if findEndpoint(config, original.EndpointID) == nil {
+		return nil, errors.New("endpoint webhook pengiriman sudah dihapus")
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		ID:            "WHD" + generateTransactionID(),
+		MerchantID:    original.MerchantID,
+		EndpointID:    original.EndpointID,
+		URL:           original.URL,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		Attempts:      []models.WebhookAttempt{},
+		NextAttemptAt: &now,
+		RedeliveryOf:  original.ID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	err = s.deliveryRepository.Save(delivery)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pengiriman webhook: %w", err)
+	}
+
+	s.wake()
+	return delivery, nil
+}
+
+// DispatchDue mengirim seluruh pengiriman webhook yang sudah waktunya dicoba dan mengembalikan jumlah yang berhasil
+func (s *WebhookService) DispatchDue(now time.Time) (int, error) {
+	s.dispatchMu.Lock()
+	defer s.dispatchMu.Unlock()
+
+	deliveries, err := s.deliveryRepository.GetDue(now)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan pengiriman webhook: %w", err)
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		err = s.deliver(delivery)
+		if err != nil {
+			log.Println("Gagal mengirim webhook", delivery.ID, ":", err)
+			continue
+		}
+		if delivery.Status == models.WebhookDeliveryDelivered {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// StartDispatcher menjalankan DispatchDue secara berkala dan setiap kali ada pengiriman baru di background.
+// Fungsi yang dikembalikan digunakan untuk menghentikan dispatcher.
+func (s *WebhookService) StartDispatcher(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		// Pengiriman yang tertunda sebelum server dijalankan ulang langsung dicoba
+		s.wake()
+		for {
+			select {
+			case <-ticker.C:
+			case <-s.notify:
+			case <-done:
+				ticker.Stop()
+				return
+			}
+			delivered, err := s.DispatchDue(time.Now())
+			if err != nil {
+				log.Println("Gagal mengirim webhook:", err)
+			} else if delivered > 0 {
+				log.Println("Webhook terkirim:", delivered)
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Fungsi bantu untuk mengirim satu pengiriman webhook dan mencatat hasil percobaannya.
+// Harus dipanggil ketika dispatchMu sudah dikunci.
+func (s *WebhookService) deliver(delivery *models.WebhookDelivery) error {
+	config, err := s.GetConfig(delivery.MerchantID)
+	if err != nil {
+		return err
+	}
+	// Pengiriman ke endpoint yang sudah dihapus tidak dikirim lagi
+	if findEndpoint(config, delivery.EndpointID) == nil {
+		return s.cancel(delivery)
+	}
+
+	start := time.Now()
+	attempt := models.WebhookAttempt{At: start}
+	statusCode, err := s.post(delivery, config.Secret, start)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	attempt.StatusCode = statusCode
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("penerima membalas dengan status %d", statusCode)
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	now := time.Now()
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.UpdatedAt = now
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case len(delivery.Attempts) >= WebhookMaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(backoff(WebhookRetryDelay, WebhookMaxRetryDelay, len(delivery.Attempts)))
+		delivery.NextAttemptAt = &next
+	}
+
+	return s.deliveryRepository.Save(delivery)
+}
+
+// Fungsi bantu untuk membatalkan pengiriman yang masih tertunda
+func (s *WebhookService) cancel(delivery *models.WebhookDelivery) error {
+	delivery.Status = models.WebhookDeliveryCanceled
+	delivery.NextAttemptAt = nil
+	delivery.UpdatedAt = time.Now()
+	return s.deliveryRepository.Save(delivery)
+}
+
+// Fungsi bantu untuk mengirim payload pengiriman yang sudah ditandatangani ke URL endpoint
+func (s *WebhookService) post(delivery *models.WebhookDelivery, secret string, at time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := at.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookEventIDHeader, delivery.EventID)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookSignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, SignPayload(secret, timestamp, delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Membaca sisa body agar koneksi dapat digunakan kembali
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// Fungsi bantu untuk membangunkan dispatcher tanpa menunggu jika dispatcher sudah dibangunkan
+func (s *WebhookService) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Fungsi bantu untuk membuat secret webhook acak
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", fmt.Errorf("gagal membuat secret webhook: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// Fungsi bantu untuk membuat data event webhook dari transaksi
+func webhookEventData(transaction *models.Transaction) *models.WebhookEventData {
+	transactionType := transaction.Type
+	if transactionType == "" {
+		transactionType = models.TransactionTypePayment
+	}
+	updatedAt := transaction.CreatedAt
+	if len(transaction.StatusHistory) > 0 {
+		updatedAt = transaction.StatusHistory[len(transaction.StatusHistory)-1].At
+	}
+	amount := merchantAmount(transaction)
+	return &models.WebhookEventData{
+		ID:         transaction.ID,
+		Type:       transactionType,
+		Status:     TransactionStatus(transaction),
+		Amount:     amount,
+		Currency:   amount.Currency(),
+		OriginalID: transaction.OriginalID,
+		Reference:  transaction.Reference,
+		CreatedAt:  transaction.CreatedAt,
+		UpdatedAt:  updatedAt,
+	}
+}
+
+// Fungsi bantu untuk mencari endpoint webhook berdasarkan ID, mengembalikan nil jika tidak ada
+func findEndpoint(config *models.WebhookConfig, endpointID string) *models.WebhookEndpoint {
+	for i := range config.Endpoints {
+		if config.Endpoints[i].ID == endpointID {
+			return &config.Endpoints[i]
+		}
+	}
+	return nil
+}
+
+// Fungsi bantu untuk memeriksa apakah endpoint berlangganan jenis event tertentu
+func subscribes(endpoint *models.WebhookEndpoint, eventType string) bool {
+	if len(endpoint.Events) == 0 {
+		return true
+	}
+	for _, event := range endpoint.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Fungsi bantu untuk memeriksa apakah jenis event dikenal
+func isEventType(eventType string) bool {
+	for _, known := range models.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/money"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+)
+
+const testWebhookSecret = "whsec_test"
+
+func TestSignPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{"event", testWebhookSecret, 1700000000, `{"id":"EVT1"}`, "a221d38bb69167addb99cc1c64937f63617b4a114c8ba006a6c6f70f09106759"},
+		{"empty", "", 0, "", "b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SignPayload(tt.secret, tt.timestamp, []byte(tt.body))
+			if got != tt.want {
+				t.Errorf("SignPayload() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 16 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.attempts), func(t *testing.T) {
+			got := backoff(WebhookRetryDelay, WebhookMaxRetryDelay, tt.attempts)
+			if got != tt.want {
+				t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterEndpointRejectsInternalAddresses(t *testing.T) {
+	service, _ := newTestWebhookService(t, nil)
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"http://127.0.0.1/webhook", true},
+		{"http://[::1]:8080/webhook", true},
+		{"http://10.0.0.5/webhook", true},
+		{"http://172.16.1.1/webhook", true},
+		{"http://192.168.1.10/webhook", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://[fe80::1]/webhook", true},
+		{"http://0.0.0.0/webhook", true},
+		{"http://100.64.0.1/webhook", true},
+		{"ftp://93.184.216.34/webhook", true},
+		{"https://93.184.216.34/webhook", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := service.RegisterEndpoint("1", tt.url, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterEndpoint(%s) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookClientRejectsInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := newWebhookClient().Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err == nil || !strings.Contains(err.Error(), errNonPublicAddress.Error()) {
+		t.Errorf("Post(%s) error = %v, want %v", server.URL, err, errNonPublicAddress)
+	}
+}
+
+func TestDispatchDue(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		wantStatus   string
+		wantAttempts int
+	}{
+		{"delivered", 0, models.WebhookDeliveryDelivered, 1},
+		{"retried", 2, models.WebhookDeliveryDelivered, 3},
+		{"failed", WebhookMaxAttempts, models.WebhookDeliveryFailed, WebhookMaxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newTestReceiver(tt.failures)
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			service, deliveries := newTestWebhookService(t, server)
+			service.Publish(models.EventPaymentSucceeded, &models.Transaction{ID: "TX1", MerchantID: "1", Amount: money.New(100000, "IDR")})
+			delivery := onlyDelivery(t, deliveries)
+
+			// Pengiriman dicoba setiap kali jadwal percobaan berikutnya tercapai sampai berhasil atau gagal
+			for i := 0; i < WebhookMaxAttempts+1 && delivery.Status == models.WebhookDeliveryPending; i++ {
+				now := time.Now()
+				if delivery.NextAttemptAt != nil {
+					now = *delivery.NextAttemptAt
+				}
+				_, err := service.DispatchDue(now)
+				if err != nil {
+					t.Fatalf("DispatchDue() error = %v", err)
+				}
+
+				previous := delivery
+				delivery = onlyDelivery(t, deliveries)
+				if delivery.Status != models.WebhookDeliveryPending {
+					break
+				}
+				if delivery.NextAttemptAt == nil {
+					t.Fatalf("pending delivery without next attempt")
+				}
+				wait := backoff(WebhookRetryDelay, WebhookMaxRetryDelay, len(delivery.Attempts))
+				if delivery.NextAttemptAt.Before(previous.UpdatedAt.Add(wait)) {
+					t.Errorf("next attempt %s, want at least %s after the previous attempt", delivery.NextAttemptAt, wait)
+				}
+			}
+
+			if delivery.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", delivery.Status, tt.wantStatus)
+			}
+			if len(delivery.Attempts) != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", len(delivery.Attempts), tt.wantAttempts)
+			}
+			if tt.wantStatus == models.WebhookDeliveryDelivered && delivery.DeliveredAt == nil {
+				t.Errorf("delivered delivery without delivered_at")
+			}
+			if delivery.NextAttemptAt != nil {
+				t.Errorf("finished delivery still scheduled at %s", delivery.NextAttemptAt)
+			}
+
+			request := receiver.last()
+			timestamp, _ := strconv.ParseInt(strings.TrimPrefix(strings.Split(request.signature, ",")[0], "t="), 10, 64)
+			want := "t=" + strconv.FormatInt(timestamp, 10) + ",v1=" + SignPayload(testWebhookSecret, timestamp, delivery.Payload)
+			if request.signature != want {
+				t.Errorf("signature = %s, want %s", request.signature, want)
+			}
+			if request.eventID != delivery.EventID {
+				t.Errorf("event id header = %s, want %s", request.eventID, delivery.EventID)
+			}
+		})
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	server := httptest.NewServer(newTestReceiver(0))
+	defer server.Close()
+
+	service, deliveries := newTestWebhookService(t, server)
+	service.Publish(models.EventPaymentSucceeded, &models.Transaction{ID: "TX1", MerchantID: "1", Amount: money.New(100000, "IDR")})
+	original := onlyDelivery(t, deliveries)
+	_, err := service.DispatchDue(time.Now())
+	if err != nil {
+		t.Fatalf("DispatchDue() error = %v", err)
+	}
+
+	redelivery, err := service.Redeliver("1", original.ID)
+	if err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	if redelivery.ID == original.ID {
+		t.Errorf("redelivery reuses delivery id %s", original.ID)
+	}
+	if redelivery.EventID != original.EventID {
+		t.Errorf("event id = %s, want %s", redelivery.EventID, original.EventID)
+	}
+	if redelivery.RedeliveryOf != original.ID {
+		t.Errorf("redelivery_of = %s, want %s", redelivery.RedeliveryOf, original.ID)
+	}
+	if string(redelivery.Payload) != string(original.Payload) {
+		t.Errorf("payload = %s, want %s", redelivery.Payload, original.Payload)
+	}
+	if redelivery.Status != models.WebhookDeliveryPending {
+		t.Errorf("status = %s, want %s", redelivery.Status, models.WebhookDeliveryPending)
+	}
+
+	_, err = service.Redeliver("2", original.ID)
+	if err == nil {
+		t.Errorf("Redeliver() by another merchant succeeded")
+	}
+}
+
+func TestPublishPayload(t *testing.T) {
+	server := httptest.NewServer(newTestReceiver(0))
+	defer server.Close()
+
+	service, deliveries := newTestWebhookService(t, server)
+	original := money.New(1050, "USD")
+	service.Publish(models.EventPaymentSucceeded, &models.Transaction{
+		ID:             "TX1",
+		CustomerID:     "7",
+		MerchantID:     "1",
+		Amount:         money.New(16432500, "IDR"),
+		OriginalAmount: &original,
+		Reference:      "ORDER-1",
+		DeviceID:       "device-1",
+		Status:         models.TransactionStatusCaptured,
+	})
+
+	var event struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err := json.Unmarshal(onlyDelivery(t, deliveries).Payload, &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"customer_id", "device_id", "status_history", "fee", "net"} {
+		if _, ok := event.Data[field]; ok {
+			t.Errorf("payload contains internal field %s", field)
+		}
+	}
+	want := map[string]interface{}{"id": "TX1", "type": "payment", "status": "captured", "currency": "USD", "reference": "ORDER-1"}
+	for field, value := range want {
+		if event.Data[field] != value {
+			t.Errorf("%s = %v, want %v", field, event.Data[field], value)
+		}
+	}
+}
+
+func TestDeleteEndpointCancelsPendingDeliveries(t *testing.T) {
+	receiver := newTestReceiver(0)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	service, deliveries := newTestWebhookService(t, server)
+	service.Publish(models.EventPaymentSucceeded, &models.Transaction{ID: "TX1", MerchantID: "1", Amount: money.New(100000, "IDR")})
+	_, err := service.DeleteEndpoint("1", "WH1")
+	if err != nil {
+		t.Fatalf("DeleteEndpoint() error = %v", err)
+	}
+
+	delivery := onlyDelivery(t, deliveries)
+	if delivery.Status != models.WebhookDeliveryCanceled {
+		t.Errorf("status = %s, want %s", delivery.Status, models.WebhookDeliveryCanceled)
+	}
+	_, err = service.DispatchDue(time.Now())
+	if err != nil {
+		t.Fatalf("DispatchDue() error = %v", err)
+	}
+	if request := receiver.last(); request.eventID != "" {
+		t.Errorf("deleted endpoint received event %s", request.eventID)
+	}
+	_, err = service.Redeliver("1", delivery.ID)
+	if err == nil {
+		t.Errorf("Redeliver() to a deleted endpoint succeeded")
+	}
+}
+
+// testReceiver adalah endpoint webhook yang membalas 500 sebanyak failures kali sebelum membalas 200
+type testReceiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []testRequest
+}
+
+type testRequest struct {
+	signature string
+	eventID   string
+}
+
+func newTestReceiver(failures int) *testReceiver {
+	return &testReceiver{failures: failures}
+}
+
+func (r *testReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, testRequest{
+		signature: req.Header.Get(WebhookSignatureHeader),
+		eventID:   req.Header.Get(WebhookEventIDHeader),
+	})
+	if len(r.requests) <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (r *testReceiver) last() testRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.requests) == 0 {
+		return testRequest{}
+	}
+	return r.requests[len(r.requests)-1]
+}
+
+// newTestWebhookService membuat WebhookService dengan data pada direktori sementara. Jika server diisi,
+// merchant 1 langsung memiliki endpoint ke server tersebut dan pengiriman menggunakan client server
+// karena alamat loopback ditolak oleh RegisterEndpoint dan client default.
+func newTestWebhookService(t *testing.T, server *httptest.Server) (*WebhookService, repository.WebhookDeliveryRepository) {
+	t.Helper()
+	dir := t.TempDir()
+	merchants := `[{"id":"1","name":"Merchant Satu","currency":"IDR"},{"id":"2","name":"Merchant Dua","currency":"IDR"}]`
+	err := os.WriteFile(filepath.Join(dir, "merchants.json"), []byte(merchants), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merchantRepository, err := repository.NewInMemoryMerchantRepository(filepath.Join(dir, "merchants.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhookRepository, err := repository.NewInMemoryWebhookRepository(filepath.Join(dir, "webhooks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveryRepository, err := repository.NewInMemoryWebhookDeliveryRepository(filepath.Join(dir, "webhook_deliveries.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var client *http.Client
+	if server != nil {
+		client = server.Client()
+		err = webhookRepository.Save(&models.WebhookConfig{
+			MerchantID: "1",
+			Secret:     testWebhookSecret,
+			Endpoints:  []models.WebhookEndpoint{{ID: "WH1", URL: server.URL, CreatedAt: time.Now()}},
+			UpdatedAt:  time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewWebhookService(webhookRepository, deliveryRepository, merchantRepository, client), deliveryRepository
+}
+
+// onlyDelivery mengambil satu-satunya pengiriman webhook merchant 1
+func onlyDelivery(t *testing.T, deliveries repository.WebhookDeliveryRepository) models.WebhookDelivery {
+	t.Helper()
+	all, err := deliveries.GetByMerchantID("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("deliveries = %d, want 1", len(all))
+	}
+	return all[0]
+}
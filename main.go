@@ -1,8 +1,21 @@
 package main
 
-import "github.com/IbnuFarhanS/Golang_MNC/api"
+import (
+	"os"
+
+	"github.com/IbnuFarhanS/Golang_MNC/api"
+)
 
 func main() {
+	// Subcommand reconcile menjalankan rekonsiliasi tanpa menjalankan server
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(os.Args[2:]))
+	}
+	// Subcommand statements membuat laporan rekening bulanan seluruh pelanggan tanpa menjalankan server
+	if len(os.Args) > 1 && os.Args[1] == "statements" {
+		os.Exit(runStatements(os.Args[2:]))
+	}
+
 	app := api.NewApp()
 	app.Initialize()
 	app.Run("8080")
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/models"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// AdminMiddleware membatasi akses hanya untuk pengguna dengan role admin.
+// Harus dipasang setelah AuthMiddleware. Role pada klaim token dan role pelanggan saat ini
+// harus sama-sama admin sehingga pencabutan role langsung berlaku tanpa menunggu token kedaluwarsa.
+func AdminMiddleware(repo repository.CustomerRepository) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(RoleKey).(string)
+			userID, _ := r.Context().Value(UserIDKey).(string)
+			if role != models.RoleAdmin {
+				log.Println("Pengguna bukan admin:", userID)
+				http.Error(w, "akses hanya untuk admin", http.StatusForbidden)
+				return
+			}
+
+			customer, err := repo.GetByUsername(userID)
+			if err != nil || customer.RoleName() != models.RoleAdmin {
+				log.Println("Role admin pengguna sudah dicabut:", userID)
+				http.Error(w, "akses hanya untuk admin", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -17,6 +17,7 @@ type contextKey string
 
 const (
 	UserIDKey contextKey = "userID"
+	RoleKey   contextKey = "role"
 )
 
 // AuthMiddleware adalah middleware untuk mengautentikasi permintaan
@@ -73,8 +74,10 @@ func AuthMiddleware(repo repository.CustomerRepository) mux.MiddlewareFunc {
 				return
 			}
 
-			// Tambahkan ID pengguna ke konteks permintaan
+			// Tambahkan ID pengguna dan role dari klaim token ke konteks permintaan
+			role, _ := claims["role"].(string)
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, RoleKey, role)
 			r = r.WithContext(ctx)
 
 			log.Println("Permintaan terautentikasi.")
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// IdempotencyKeyHeader adalah header yang digunakan klien untuk menandai permintaan yang boleh diulang
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// committedKey adalah key context tempat handler menandai bahwa perubahan sudah disimpan
+const committedKey contextKey = "idempotencyCommitted"
+
+// MarkCommitted menandai bahwa handler sudah menyimpan perubahan (misalnya saldo sudah didebit), sehingga respons
+// permintaan tetap disimpan untuk Idempotency-Key meskipun respons tersebut adalah kesalahan server.
+// Tidak melakukan apa pun jika permintaan tidak melalui IdempotencyMiddleware.
+func MarkCommitted(r *http.Request) {
+	if committed, ok := r.Context().Value(committedKey).(*bool); ok {
+		*committed = true
+	}
+}
+
+// IdempotencyMiddleware adalah middleware yang memastikan permintaan dengan Idempotency-Key yang sama
+// hanya diproses satu kali. Permintaan ulang dengan body yang sama mendapatkan respons awal,
+// sedangkan penggunaan ulang key dengan body berbeda akan ditolak. Selama diproses, key hanya berlaku
+// selama lease sehingga key dari permintaan yang terhenti dapat digunakan kembali, lalu respons disimpan selama ttl.
+// Middleware ini harus dijalankan setelah AuthMiddleware karena key dicatat per pengguna.
+func IdempotencyMiddleware(repo repository.IdempotencyRepository, ttl time.Duration, lease time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, _ := r.Context().Value(UserIDKey).(string)
+
+			// Membaca body untuk dihitung hash-nya lalu mengembalikannya ke permintaan
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "gagal membaca body permintaan", http.StatusBadRequest)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			requestHash := hashRequest(r, body)
+
+			record, created, err := repo.Begin(userID, key, requestHash, lease)
+			if err != nil {
+				log.Println("Gagal mencatat idempotency key:", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if !created {
+				if record.RequestHash != requestHash {
+					log.Println("Idempotency key digunakan ulang dengan payload berbeda:", key)
+					http.Error(w, "Idempotency-Key sudah digunakan untuk permintaan yang berbeda", http.StatusUnprocessableEntity)
+					return
+				}
+				if !record.Completed {
+					log.Println("Permintaan dengan idempotency key masih diproses:", key)
+					http.Error(w, "permintaan dengan Idempotency-Key yang sama masih diproses", http.StatusConflict)
+					return
+				}
+
+				// Mengirim ulang respons awal
+				log.Println("Mengirim ulang respons untuk idempotency key:", key)
+				if record.ContentType != "" {
+					w.Header().Set("Content-Type", record.ContentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write([]byte(record.Body))
+				return
+			}
+
+			committed := false
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), committedKey, &committed)))
+
+			// Kesalahan server sebelum ada perubahan yang disimpan tidak disimpan agar permintaan dapat dicoba ulang.
+			// Setelah perubahan disimpan, respons apa pun disimpan agar percobaan ulang tidak memproses permintaan lagi.
+			if recorder.statusCode >= http.StatusInternalServerError && !committed {
+				err = repo.Delete(userID, key)
+			} else {
+				err = repo.Complete(userID, key, recorder.statusCode, recorder.Header().Get("Content-Type"), recorder.body.Bytes(), ttl)
+			}
+			if err != nil {
+				log.Println("Gagal menyimpan respons idempotency key:", err)
+			}
+		})
+	}
+}
+
+// Fungsi bantu untuk menghitung hash dari method, path, dan body permintaan.
+// Body JSON dipadatkan terlebih dahulu sehingga perbedaan spasi tidak dianggap sebagai body yang berbeda.
+func hashRequest(r *http.Request, body []byte) string {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, body); err == nil {
+		body = compacted.Bytes()
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder meneruskan respons ke klien sambil menyimpan salinannya
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
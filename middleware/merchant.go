@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// MerchantAPIKeyHeader adalah header yang berisi API key merchant
+const MerchantAPIKeyHeader = "X-Merchant-Key"
+
+// MerchantIDKey adalah kunci konteks untuk ID merchant yang terautentikasi
+const MerchantIDKey contextKey = "merchantID"
+
+// MerchantAuthMiddleware mengautentikasi permintaan merchant menggunakan API key pada header X-Merchant-Key
+// dan menambahkan ID merchant ke konteks permintaan
+func MerchantAuthMiddleware(repo repository.MerchantRepository) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log.Println("Mengautentikasi permintaan merchant...")
+
+			apiKey := r.Header.Get(MerchantAPIKeyHeader)
+			if apiKey == "" {
+				log.Println("Header API key merchant tidak ada")
+				http.Error(w, "header "+MerchantAPIKeyHeader+" tidak ada", http.StatusUnauthorized)
+				return
+			}
+
+			merchant, err := repo.GetByAPIKey(apiKey)
+			if err != nil {
+				log.Println("API key merchant tidak valid")
+				http.Error(w, "API key merchant tidak valid", http.StatusUnauthorized)
+				return
+			}
+
+			log.Println("ID merchant terautentikasi:", merchant.ID)
+
+			// Tambahkan ID merchant ke konteks permintaan
+			ctx := context.WithValue(r.Context(), MerchantIDKey, merchant.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
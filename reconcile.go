@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/reconciliation"
+)
+
+// Exit code subcommand reconcile
+const (
+	reconcileOK       = 0
+	reconcileMismatch = 1
+	reconcileFailed   = 2
+)
+
+// runReconcile mencocokkan transaksi, wallet, settlement, dan buku besar lalu mencetak laporan selisih.
+// Mengembalikan exit code bukan nol jika ditemukan selisih sehingga dapat dijalankan sebagai job malam hari.
+func runReconcile(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	dir := flags.String("dir", "json", "direktori file json")
+	asJSON := flags.Bool("json", false, "cetak laporan dalam format JSON")
+	err := flags.Parse(args)
+	if err != nil {
+		return reconcileFailed
+	}
+
+	snapshot, err := reconciliation.LoadSnapshot(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Gagal memuat data rekonsiliasi:", err)
+		return reconcileFailed
+	}
+
+	report := reconciliation.Reconcile(snapshot, time.Now())
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Gagal menulis laporan rekonsiliasi:", err)
+		return reconcileFailed
+	}
+
+	if !report.OK() {
+		return reconcileMismatch
+	}
+	return reconcileOK
+}
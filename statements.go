@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/IbnuFarhanS/Golang_MNC/internal/repository"
+	"github.com/IbnuFarhanS/Golang_MNC/internal/service"
+)
+
+// Exit code subcommand statements
+const (
+	statementsOK     = 0
+	statementsFailed = 1
+)
+
+// runStatements membuat laporan rekening bulanan seluruh pelanggan dalam format CSV dan/atau PDF ke direktori
+// keluaran. Periode default adalah bulan sebelumnya sehingga dapat dijalankan sebagai job awal bulan.
+func runStatements(args []string) int {
+	flags := flag.NewFlagSet("statements", flag.ContinueOnError)
+	dir := flags.String("dir", "json", "direktori file json")
+	out := flags.String("out", "statements", "direktori keluaran laporan rekening")
+	period := flags.String("period", time.Now().AddDate(0, -1, 0).Format(service.StatementPeriodLayout), "periode laporan dengan format YYYY-MM")
+	formats := flags.String("format", "csv,pdf", "format laporan dipisahkan koma: csv, pdf")
+	err := flags.Parse(args)
+	if err != nil {
+		return statementsFailed
+	}
+
+	start, err := service.ParseStatementPeriod(*period)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return statementsFailed
+	}
+	extensions := make([]string, 0, 2)
+	for _, format := range strings.Split(*formats, ",") {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != "csv" && format != "pdf" {
+			fmt.Fprintln(os.Stderr, "Format laporan tidak didukung:", format)
+			return statementsFailed
+		}
+		extensions = append(extensions, format)
+	}
+
+	statementService, err := loadStatementService(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Gagal memuat data laporan rekening:", err)
+		return statementsFailed
+	}
+	statements, err := statementService.BuildAll(start)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Gagal menyusun laporan rekening:", err)
+		return statementsFailed
+	}
+
+	target := filepath.Join(*out, start.Format(service.StatementPeriodLayout))
+	err = os.MkdirAll(target, 0755)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Gagal membuat direktori keluaran:", err)
+		return statementsFailed
+	}
+
+	for _, statement := range statements {
+		for _, extension := range extensions {
+			var data []byte
+			if extension == "csv" {
+				data, err = statementService.RenderCSV(statement)
+			} else {
+				data = statementService.RenderPDF(statement)
+			}
+			if err == nil {
+				err = os.WriteFile(filepath.Join(target, service.StatementFileName(statement, extension)), data, 0644)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Gagal menulis laporan rekening pelanggan %s: %v\n", statement.CustomerID, err)
+				return statementsFailed
+			}
+		}
+		fmt.Printf("Pelanggan %-6s %-20s saldo awal %s, saldo akhir %s, %d transaksi\n", statement.CustomerID,
+			statement.CustomerName, statement.OpeningBalance, statement.ClosingBalance, len(statement.Lines))
+	}
+	fmt.Printf("%d laporan rekening periode %s ditulis ke %s\n", len(statements), start.Format(service.StatementPeriodLayout), target)
+	return statementsOK
+}
+
+// Fungsi bantu untuk membuat layanan laporan rekening dari file json pada direktori dir
+func loadStatementService(dir string) (*service.StatementService, error) {
+	customerRepo, err := repository.NewInMemoryCustomerRepository(filepath.Join(dir, "customers.json"))
+	if err != nil {
+		return nil, err
+	}
+	merchantRepo, err := repository.NewInMemoryMerchantRepository(filepath.Join(dir, "merchants.json"))
+	if err != nil {
+		return nil, err
+	}
+	walletRepo, err := repository.NewInMemoryWalletRepository(filepath.Join(dir, "wallets.json"))
+	if err != nil {
+		return nil, err
+	}
+	transactionRepo := repository.NewTransactionRepository(filepath.Join(dir, "transactions.json"))
+	return service.NewStatementService(transactionRepo, customerRepo, merchantRepo, walletRepo), nil
+}